@@ -0,0 +1,47 @@
+// Command copy-hiv-screenings copies the infant hiv screenings that older versions of the
+// augmentor wrote to the ACSIS database into the emtct database. It only needs to run once
+// per installation, after the emtct migrations have been applied:
+//
+//	copy-hiv-screenings -c env.yaml
+//
+// The counts are checked before the copy is committed. The rows in ACSIS are left as they
+// are; they can be dropped by hand once the copy has succeeded.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/hivScreenings"
+	"moh.gov.bz/mch/emtct/internal/config"
+	"moh.gov.bz/mch/emtct/internal/server"
+)
+
+func main() {
+	var confFile string
+	flag.StringVar(&confFile, "c", "", "Specify configuration file.")
+	flag.Parse()
+	if len(confFile) == 0 {
+		fmt.Fprintln(os.Stderr, "please specify the configuration file using the -c flag")
+		os.Exit(2)
+	}
+	cnf, err := config.ReadConf(confFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not parse the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	a := server.NewApp(*cnf)
+
+	result, err := hivScreenings.CopyFromAcsis(context.Background(), a.AcsisDb, a.EmtctDb)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("hiv screenings in acsis: %d\ncopied: %d\nalready in emtct: %d\nskipped, different in emtct: %d\n",
+		result.InAcsis, result.Copied, result.AlreadyCopied, len(result.Conflicting))
+	for _, id := range result.Conflicting {
+		fmt.Printf("  %s\n", id)
+	}
+}
@@ -0,0 +1,210 @@
+// Command etl runs the pregnancy sync from ACSIS into the emtct database from a shell,
+// e.g. to backfill several years or to run the sync from a system timer.
+//
+// It reads the same configuration file as the server:
+//
+//	etl -c env.yaml -from 2015 -to 2020
+//	etl -c env.yaml -since 2020-06-01 -dry-run
+//
+// A summary of every sync is printed when the command finishes. Pregnancies that could not
+// be copied are skipped and listed on stderr. The command exits with status 1 if any sync failed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/business/etl"
+	"moh.gov.bz/mch/emtct/internal/config"
+	"moh.gov.bz/mch/emtct/internal/server"
+)
+
+const layoutISO = "2006-01-02"
+
+// summary is a row of the table that is printed when the command finishes.
+type summary struct {
+	period    string
+	read      int
+	inserted  int
+	updated   int
+	unchanged int
+	removed   int
+	failed    int
+	err       error
+}
+
+func main() {
+	var confFile, since string
+	var from, to int
+	var dryRun bool
+	flag.StringVar(&confFile, "c", "", "Specify configuration file.")
+	flag.IntVar(&from, "from", 0, "First year of pregnancies (by LMP) to sync.")
+	flag.IntVar(&to, "to", 0, "Last year of pregnancies (by LMP) to sync. Defaults to -from.")
+	flag.StringVar(&since, "since", "", "Sync the pregnancies modified in ACSIS since this date (YYYY-MM-DD).")
+	flag.BoolVar(&dryRun, "dry-run", false, "Show what the sync would change without writing anything.")
+	flag.Parse()
+	if len(confFile) == 0 {
+		fmt.Fprintln(os.Stderr, "please specify the configuration file using the -c flag")
+		os.Exit(2)
+	}
+	if from == 0 && len(since) == 0 {
+		fmt.Fprintln(os.Stderr, "please specify a year range with -from and -to, or a date with -since")
+		os.Exit(2)
+	}
+	if to == 0 {
+		to = from
+	}
+	if to < from {
+		fmt.Fprintln(os.Stderr, "-to must not be before -from")
+		os.Exit(2)
+	}
+	var sinceDate time.Time
+	if len(since) > 0 {
+		d, err := time.ParseInLocation(layoutISO, since, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-since must be a date formatted as YYYY-MM-DD: %v\n", err)
+			os.Exit(2)
+		}
+		sinceDate = d
+	}
+
+	cnf, err := config.ReadConf(confFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not parse the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	a := server.NewApp(*cnf)
+	sync := etl.NewPregnancySync(pregnancy.New(a.EmtctDb, a.AcsisDb), etlRuns.New(a.EmtctDb))
+
+	// Cancel the running sync on Ctrl+C. The sync is still recorded as a failed run.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		<-c
+		log.Info("interrupted, cancelling the sync")
+		cancel()
+	}()
+
+	user := os.Getenv("USER")
+	var rows []summary
+	if from > 0 {
+		for year := from; year <= to; year++ {
+			if ctx.Err() != nil {
+				break
+			}
+			if dryRun {
+				rows = append(rows, previewYear(ctx, sync, year))
+			} else {
+				rows = append(rows, syncYear(ctx, sync, user, year))
+			}
+		}
+	}
+	if len(since) > 0 && ctx.Err() == nil {
+		if dryRun {
+			rows = append(rows, previewSince(ctx, sync, sinceDate))
+		} else {
+			rows = append(rows, syncSince(ctx, sync, user, sinceDate))
+		}
+	}
+
+	failed := printSummary(rows, dryRun)
+	if failed || ctx.Err() != nil {
+		os.Exit(1)
+	}
+}
+
+func syncYear(ctx context.Context, sync etl.PregnancySync, user string, year int) summary {
+	row := summary{period: fmt.Sprint(year)}
+	result, err := sync.SyncYear(ctx, etlRuns.Cli, user, year)
+	if err != nil {
+		row.err = err
+		return row
+	}
+	row.read = result.Read
+	row.inserted = len(result.Inserted)
+	row.failed = len(result.Failed)
+	row.unchanged = result.Read - len(result.Inserted) - row.failed
+	for _, f := range result.Failed {
+		fmt.Fprintf(os.Stderr, "%d: skipped pregnancy %d: %s\n", year, f.PregnancyId, f.Error)
+	}
+	return row
+}
+
+func previewYear(ctx context.Context, sync etl.PregnancySync, year int) summary {
+	row := summary{period: fmt.Sprint(year)}
+	preview, err := sync.PreviewYear(ctx, year)
+	if err != nil {
+		row.err = err
+		return row
+	}
+	row.read = preview.Read
+	row.inserted = len(preview.Insert)
+	row.updated = len(preview.Changed)
+	row.unchanged = preview.Unchanged
+	row.removed = len(preview.Removed)
+	return row
+}
+
+func syncSince(ctx context.Context, sync etl.PregnancySync, user string, since time.Time) summary {
+	row := summary{period: "since " + since.Format(layoutISO)}
+	result, err := sync.SyncSince(ctx, etlRuns.Cli, user, since)
+	if err != nil {
+		row.err = err
+		return row
+	}
+	row.inserted = len(result.Inserted)
+	row.updated = len(result.Updated)
+	row.unchanged = result.Unchanged
+	row.read = row.inserted + row.updated + row.unchanged
+	return row
+}
+
+func previewSince(ctx context.Context, sync etl.PregnancySync, since time.Time) summary {
+	row := summary{period: "since " + since.Format(layoutISO)}
+	preview, err := sync.PreviewSince(ctx, since)
+	if err != nil {
+		row.err = err
+		return row
+	}
+	row.inserted = len(preview.Insert)
+	row.updated = len(preview.Changed)
+	row.unchanged = preview.Unchanged
+	row.read = row.inserted + row.updated + row.unchanged
+	return row
+}
+
+// printSummary writes the summary table to stdout, and reports whether any sync failed.
+func printSummary(rows []summary, dryRun bool) bool {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if dryRun {
+		fmt.Fprintln(w, "PERIOD\tREAD\tWOULD INSERT\tWOULD UPDATE\tUNCHANGED\tREMOVED IN ACSIS\tERROR")
+	} else {
+		fmt.Fprintln(w, "PERIOD\tREAD\tINSERTED\tUPDATED\tUNCHANGED\tFAILED\tERROR")
+	}
+	failed := false
+	for _, r := range rows {
+		status := ""
+		if r.err != nil {
+			failed = true
+			status = r.err.Error()
+		}
+		if dryRun {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n", r.period, r.read, r.inserted, r.updated, r.unchanged, r.removed, status)
+		} else {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n", r.period, r.read, r.inserted, r.updated, r.unchanged, r.failed, status)
+		}
+	}
+	w.Flush()
+	return failed
+}
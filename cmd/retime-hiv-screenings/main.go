@@ -0,0 +1,51 @@
+// Command retime-hiv-screenings works out the due date and timeliness of the saved infant hiv
+// screenings again from the hiv screening rules, e.g. after the migration that added the rules
+// or after an admin changed one:
+//
+//	retime-hiv-screenings -c env.yaml
+//
+// Every screening that changes is saved as a new version and written to the audit log, so the
+// old values can be seen in its history.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/hivScreenings"
+	"moh.gov.bz/mch/emtct/internal/config"
+	"moh.gov.bz/mch/emtct/internal/server"
+)
+
+func main() {
+	var confFile string
+	flag.StringVar(&confFile, "c", "", "Specify configuration file.")
+	flag.Parse()
+	if len(confFile) == 0 {
+		fmt.Fprintln(os.Stderr, "please specify the configuration file using the -c flag")
+		os.Exit(2)
+	}
+	cnf, err := config.ReadConf(confFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not parse the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	a := server.NewApp(*cnf)
+
+	ctx := context.Background()
+	screenings := hivScreenings.New(a.EmtctDb)
+	rules, err := screenings.FindRules(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	result, err := screenings.Retime(ctx, rules, "retime-hiv-screenings")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("hiv screenings: %d\nchanged: %d\nwithout a synced birth: %d\n",
+		result.Screenings, result.Changed, result.NoBirth)
+}
@@ -24,8 +24,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
 	var confFile string
+	var requireSchema bool
 	flag.StringVar(&confFile, "c", "", "Specify configuration file.")
+	flag.BoolVar(&requireSchema, "require-schema", false, "Refuse to start when the emtct database has pending migrations.")
 	flag.Parse()
 	if len(confFile) == 0 {
 		fmt.Print("please specify the configuration file using the -c flag")
@@ -35,5 +41,5 @@ func main() {
 	if err != nil {
 		fmt.Print("could not parse the configuration file")
 	}
-	server.NewServer(*cnf)
+	server.NewServer(*cnf, requireSchema)
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"moh.gov.bz/mch/emtct/db/migrations"
+	"moh.gov.bz/mch/emtct/internal/config"
+	"moh.gov.bz/mch/emtct/internal/db"
+	"moh.gov.bz/mch/emtct/internal/migrate"
+)
+
+const migrateUsage = `usage: emtct migrate -c conf.yaml up|down [n]|status
+
+  up      apply every pending migration
+  down    roll back the last n migrations (1 by default)
+  status  print the version of the schema and the pending migrations
+`
+
+// runMigrate runs the migrate subcommand with the arguments that follow "migrate".
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	var confFile string
+	fs.StringVar(&confFile, "c", "", "Specify configuration file.")
+	fs.Parse(args)
+	if len(confFile) == 0 || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cnf, err := config.ReadConf(confFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not parse the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	emtctDb, err := db.NewConnection(&cnf.EmtctDb)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not connect to the emtct database: %v\n", err)
+		os.Exit(1)
+	}
+	defer emtctDb.Close()
+	m, err := migrate.New(emtctDb, migrations.FS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	switch fs.Arg(0) {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if len(applied) == 0 {
+			fmt.Println("the schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if fs.NArg() > 1 {
+			steps, err = strconv.Atoi(fs.Arg(1))
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, "the number of migrations to roll back must be a positive number")
+				os.Exit(2)
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, mig := range reverted {
+			fmt.Printf("rolled back %d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "status":
+		s, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("version: %d\nlatest: %d\ndirty: %t\n", s.Version, s.Latest, s.Dirty)
+		for _, mig := range s.Pending {
+			fmt.Printf("pending: %d_%s\n", mig.Version, mig.Name)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE pregnancy_changes;
+DROP TABLE etl_watermarks;
+ALTER TABLE pregnancies DROP COLUMN last_modified_time;
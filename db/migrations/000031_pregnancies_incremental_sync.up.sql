@@ -0,0 +1,17 @@
+ALTER TABLE pregnancies ADD COLUMN last_modified_time TIMESTAMP;
+
+CREATE TABLE etl_watermarks(
+    name TEXT PRIMARY KEY,
+    last_modified_time TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE pregnancy_changes(
+    id SERIAL PRIMARY KEY,
+    pregnancy_id INT NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    changed_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_pregnancy_changes_pregnancy_id ON pregnancy_changes(pregnancy_id);
@@ -0,0 +1,2 @@
+ALTER TABLE pregnancy_changes DROP COLUMN etl_run_id;
+DROP TABLE etl_runs;
@@ -0,0 +1,21 @@
+CREATE TABLE etl_runs(
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    trigger_type TEXT NOT NULL,
+    triggered_by TEXT,
+    year_from INT,
+    year_to INT,
+    since TIMESTAMP,
+    rows_read INT NOT NULL DEFAULT 0,
+    rows_inserted INT NOT NULL DEFAULT 0,
+    rows_updated INT NOT NULL DEFAULT 0,
+    rows_skipped INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    duration_ms BIGINT,
+    error TEXT
+);
+CREATE INDEX idx_etl_runs_started_at ON etl_runs(started_at);
+
+ALTER TABLE pregnancy_changes ADD COLUMN etl_run_id TEXT;
+CREATE INDEX idx_pregnancy_changes_etl_run_id ON pregnancy_changes(etl_run_id);
@@ -0,0 +1,13 @@
+CREATE TABLE infants(
+    infant_id INT PRIMARY KEY,
+    pregnancy_id INT,
+    mother_id INT NOT NULL,
+    birth_date DATE,
+    birth_status TEXT,
+    linked_manually BOOLEAN NOT NULL DEFAULT false,
+    linked_by TEXT,
+    linked_at TIMESTAMP,
+    synced_at TIMESTAMP
+);
+CREATE INDEX idx_infants_pregnancy_id ON infants(pregnancy_id);
+CREATE INDEX idx_infants_mother_id ON infants(mother_id);
@@ -0,0 +1,4 @@
+DROP TABLE lab_result_syncs;
+DROP TABLE lab_results;
+ALTER TABLE lab_results_v1 RENAME CONSTRAINT lab_results_v1_pkey TO lab_results_pkey;
+ALTER TABLE lab_results_v1 RENAME TO lab_results;
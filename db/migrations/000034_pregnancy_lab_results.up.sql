@@ -0,0 +1,24 @@
+-- The rows of the old lab_results have no pregnancy or test request, so they cannot be moved into
+-- the new shape, which the lab results etl fills from ACSIS. They are kept in lab_results_v1.
+ALTER TABLE lab_results RENAME TO lab_results_v1;
+ALTER TABLE lab_results_v1 RENAME CONSTRAINT lab_results_pkey TO lab_results_v1_pkey;
+CREATE TABLE lab_results(
+    id INT NOT NULL,
+    pregnancy_id INT NOT NULL,
+    patient_id INT NOT NULL,
+    test_name TEXT NOT NULL,
+    test_result TEXT NOT NULL,
+    test_request_id INT NOT NULL,
+    test_request_item_id INT NOT NULL,
+    date_sample_taken TIMESTAMP,
+    result_date TIMESTAMP,
+    released_time TIMESTAMP,
+    date_order_received_by_lab TIMESTAMP,
+    PRIMARY KEY (pregnancy_id, id)
+);
+
+CREATE TABLE lab_result_syncs(
+    pregnancy_id INT PRIMARY KEY,
+    synced_at TIMESTAMP NOT NULL,
+    etl_run_id TEXT
+);
@@ -0,0 +1,2 @@
+DROP TABLE etl_run_errors;
+ALTER TABLE etl_runs DROP COLUMN rows_failed;
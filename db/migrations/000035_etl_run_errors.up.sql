@@ -0,0 +1,10 @@
+ALTER TABLE etl_runs ADD COLUMN rows_failed INT NOT NULL DEFAULT 0;
+
+CREATE TABLE etl_run_errors(
+    id SERIAL PRIMARY KEY,
+    etl_run_id TEXT NOT NULL,
+    record_id INT NOT NULL,
+    error TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_etl_run_errors_etl_run_id ON etl_run_errors(etl_run_id);
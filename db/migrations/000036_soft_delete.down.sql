@@ -0,0 +1,6 @@
+ALTER TABLE home_visit DROP COLUMN deleted_at, DROP COLUMN deleted_by, DROP COLUMN deletion_reason;
+ALTER TABLE hospital_admission DROP COLUMN deleted_at, DROP COLUMN deleted_by, DROP COLUMN deletion_reason;
+ALTER TABLE contraceptive_used DROP COLUMN deleted_at, DROP COLUMN deleted_by, DROP COLUMN deletion_reason;
+ALTER TABLE contact_tracing DROP COLUMN deleted_at, DROP COLUMN deleted_by, DROP COLUMN deletion_reason;
+ALTER TABLE syphilis_treatment_partner DROP COLUMN deleted_at, DROP COLUMN deleted_by, DROP COLUMN deletion_reason;
+ALTER TABLE hiv_screening DROP COLUMN deleted_at, DROP COLUMN deleted_by, DROP COLUMN deletion_reason;
@@ -0,0 +1,6 @@
+ALTER TABLE home_visit ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by TEXT, ADD COLUMN deletion_reason TEXT;
+ALTER TABLE hospital_admission ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by TEXT, ADD COLUMN deletion_reason TEXT;
+ALTER TABLE contraceptive_used ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by TEXT, ADD COLUMN deletion_reason TEXT;
+ALTER TABLE contact_tracing ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by TEXT, ADD COLUMN deletion_reason TEXT;
+ALTER TABLE syphilis_treatment_partner ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by TEXT, ADD COLUMN deletion_reason TEXT;
+ALTER TABLE hiv_screening ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by TEXT, ADD COLUMN deletion_reason TEXT;
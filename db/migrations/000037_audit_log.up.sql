@@ -0,0 +1,11 @@
+CREATE TABLE audit_log(
+    id BIGSERIAL PRIMARY KEY,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    user_email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    before JSONB,
+    after JSONB
+);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at);
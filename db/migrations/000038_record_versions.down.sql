@@ -0,0 +1,6 @@
+ALTER TABLE home_visit DROP COLUMN version;
+ALTER TABLE hospital_admission DROP COLUMN version;
+ALTER TABLE contraceptive_used DROP COLUMN version;
+ALTER TABLE contact_tracing DROP COLUMN version;
+ALTER TABLE syphilis_treatment_partner DROP COLUMN version;
+ALTER TABLE hiv_screening DROP COLUMN version;
@@ -0,0 +1,6 @@
+ALTER TABLE home_visit ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE hospital_admission ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE contraceptive_used ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE contact_tracing ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE syphilis_treatment_partner ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE hiv_screening ADD COLUMN version INT NOT NULL DEFAULT 1;
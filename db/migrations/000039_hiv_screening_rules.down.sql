@@ -0,0 +1 @@
+DROP TABLE hiv_screening_rules;
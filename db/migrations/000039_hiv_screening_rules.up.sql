@@ -0,0 +1,20 @@
+CREATE TABLE hiv_screening_rules(
+    id BIGSERIAL PRIMARY KEY,
+    test_name TEXT NOT NULL,
+    effective_from DATE NOT NULL,
+    due_months INT NOT NULL DEFAULT 0,
+    due_days INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    created_by TEXT NOT NULL,
+    UNIQUE (test_name, effective_from)
+);
+
+-- The windows that were hard-coded before the rules could be changed. A rule is due on the last
+-- day on which a sample is timely, so PCR 2, which had to be taken less than 6 weeks after birth,
+-- is due 41 days after birth. ELISA was timely up to 504 days after birth but due 18 months after
+-- it; it is now timely up to its due date.
+INSERT INTO hiv_screening_rules (test_name, effective_from, due_months, due_days, created_at, created_by)
+VALUES ('PCR 1', '1900-01-01', 0, 3, now(), 'migration'),
+       ('PCR 2', '1900-01-01', 0, 41, now(), 'migration'),
+       ('PCR 3', '1900-01-01', 0, 90, now(), 'migration'),
+       ('ELISA', '1900-01-01', 18, 0, now(), 'migration');
@@ -0,0 +1 @@
+DROP TABLE syphilis_screening_rules;
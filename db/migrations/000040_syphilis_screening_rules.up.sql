@@ -0,0 +1,20 @@
+-- syphilis_screening_rules is the testing schedule of the syphilis screenings of infants. A
+-- screening of test_name is due due_months and due_days after birth, for the infants born on or
+-- after effective_from, until a rule of the same test with a later effective_from is added.
+CREATE TABLE syphilis_screening_rules(
+    id BIGSERIAL PRIMARY KEY,
+    test_name TEXT NOT NULL,
+    effective_from DATE NOT NULL,
+    due_months INT NOT NULL DEFAULT 0,
+    due_days INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    created_by TEXT NOT NULL,
+    UNIQUE (test_name, effective_from)
+);
+
+-- The first schedule: a screening at birth, then at 3 and 6 months to see the titre fall. It is
+-- effective from 1900 so that every infant is judged by it until a later rule is added.
+INSERT INTO syphilis_screening_rules (test_name, effective_from, due_months, due_days, created_at, created_by)
+VALUES ('Birth', '1900-01-01', 0, 3, now(), 'migration'),
+       ('3 months', '1900-01-01', 3, 0, now(), 'migration'),
+       ('6 months', '1900-01-01', 6, 0, now(), 'migration');
@@ -0,0 +1,10 @@
+// Package migrations embeds the sql migrations of the emtct database, so that the server
+// binary can apply them without the migrate tool.
+package migrations
+
+import "embed"
+
+// FS holds the NNNNNN_name.up.sql and NNNNNN_name.down.sql files of every migration.
+//
+//go:embed *.sql
+var FS embed.FS
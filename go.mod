@@ -1,13 +1,15 @@
 module moh.gov.bz/mch/emtct
 
-go 1.15
+go 1.16
 
 require (
 	github.com/bearbin/go-age v0.0.0-20140407072555-316d0c1e7cd1
 	github.com/google/uuid v1.1.2
 	github.com/gorilla/mux v1.8.0
 	github.com/lib/pq v1.8.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/viper v1.7.1
 	github.com/uris77/auth0 v0.0.0-20200303040845-37c0873555b7
+	gopkg.in/yaml.v2 v2.2.4
 )
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/app"
+	"moh.gov.bz/mch/emtct/internal/business/data/infant"
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/cache"
+)
+
+// Caches are the caches in front of the ACSIS lookups. A nil cache is disabled.
+type Caches struct {
+	Patients *cache.Cache
+	Infants  *cache.Cache
+}
+
+type AdminRoutes struct {
+	Caches Caches
+	// Restorers restore the deleted records of each kind, keyed by the kind in the url.
+	Restorers map[string]RestoreFunc
+	// HivScreeningRules keeps the due dates of the infant hiv screenings.
+	HivScreeningRules screeningRules.Store
+	// SyphilisScreeningRules keeps the due dates of the infant syphilis screenings.
+	SyphilisScreeningRules screeningRules.Store
+}
+
+type cacheStatsResponse struct {
+	Patients *cache.Stats `json:"patients"`
+	Infants  *cache.Stats `json:"infants"`
+}
+
+func stats(c *cache.Cache) *cache.Stats {
+	if c == nil {
+		return nil
+	}
+	s := c.Stats()
+	return &s
+}
+
+// CacheStatsHandler returns the hit and miss counters of every cache. Disabled caches are null.
+func (a AdminRoutes) CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		response := cacheStatsResponse{
+			Patients: stats(a.Caches.Patients),
+			Infants:  stats(a.Caches.Infants),
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.WithFields(log.Fields{"stats": response}).
+				WithError(err).
+				Error("error marshalling the cache stats")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// InvalidatePatientHandler removes a patient from every cache, e.g. after their record
+// was corrected in ACSIS. Infants are patients too, so their id removes the cached infant, and
+// the cached infants of a mother are removed with her, as they have her basic info.
+func (a AdminRoutes) InvalidatePatientHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodDelete:
+		id := mux.Vars(r)["patientId"]
+		patientId, err := strconv.Atoi(id)
+		if err != nil {
+			http.Error(w, "the patient id must be a valid number", http.StatusBadRequest)
+			return
+		}
+		for _, c := range []*cache.Cache{a.Caches.Patients, a.Caches.Infants} {
+			if c != nil {
+				c.Delete(patientId)
+			}
+		}
+		if a.Caches.Infants != nil {
+			infant.DeleteMother(a.Caches.Infants, patientId)
+		}
+		token := r.Context().Value("user").(app.JwtToken)
+		log.WithFields(log.Fields{"patientId": patientId, "user": token.Email}).Info("invalidated cached patient")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RestoreHandler restores a record that was deleted by mistake.
+func (a AdminRoutes) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodPost:
+		vars := mux.Vars(r)
+		kind, id := vars["kind"], vars["id"]
+		restore, ok := a.Restorers[kind]
+		if !ok {
+			http.Error(w, fmt.Sprintf("%s can not be restored", kind), http.StatusNotFound)
+			return
+		}
+		token := r.Context().Value("user").(app.JwtToken)
+		restored, err := restore(r.Context(), id, token.Email)
+		if err != nil {
+			log.WithFields(log.Fields{"kind": kind, "id": id, "user": token.Email}).
+				WithError(err).
+				Error("error restoring the deleted record")
+			internalError(w, err)
+			return
+		}
+		if !restored {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.WithFields(log.Fields{"kind": kind, "id": id, "user": token.Email}).Info("restored deleted record")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HivScreeningRulesHandler lists the hiv screening rules, and adds a rule. A rule is not edited:
+// a guideline that changes gets a new rule from the day it is in force.
+func (a AdminRoutes) HivScreeningRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rulesHandler(w, r, a.HivScreeningRules, "hiv screening", "HivScreeningRulesHandler")
+}
+
+// DeleteHivScreeningRuleHandler removes an hiv screening rule that was added by mistake.
+func (a AdminRoutes) DeleteHivScreeningRuleHandler(w http.ResponseWriter, r *http.Request) {
+	deleteRuleHandler(w, r, a.HivScreeningRules, "hiv screening")
+}
+
+// SyphilisScreeningRulesHandler lists the syphilis screening rules, and adds a rule, the same way
+// as HivScreeningRulesHandler.
+func (a AdminRoutes) SyphilisScreeningRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rulesHandler(w, r, a.SyphilisScreeningRules, "syphilis screening", "SyphilisScreeningRulesHandler")
+}
+
+// DeleteSyphilisScreeningRuleHandler removes a syphilis screening rule that was added by mistake.
+func (a AdminRoutes) DeleteSyphilisScreeningRuleHandler(w http.ResponseWriter, r *http.Request) {
+	deleteRuleHandler(w, r, a.SyphilisScreeningRules, "syphilis screening")
+}
+
+// rulesHandler lists the rules of the schedule of kind in rules, and adds a rule.
+func rulesHandler(w http.ResponseWriter, r *http.Request, rules screeningRules.Store, kind, handlerName string) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		found, err := rules.FindRules(r.Context())
+		if err != nil {
+			log.WithField("handler", handlerName).WithError(err).Errorf("error retrieving the %s rules", kind)
+			internalError(w, err)
+			return
+		}
+		// Return an empty array if there are no rules
+		if found == nil {
+			found = screeningRules.Rules{}
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(found); err != nil {
+			log.WithFields(log.Fields{"rules": found, "handler": handlerName}).
+				WithError(err).
+				Errorf("error encoding the %s rules", kind)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		token := r.Context().Value("user").(app.JwtToken)
+		var rule screeningRules.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			http.Error(w, "the rule is not valid json", http.StatusBadRequest)
+			return
+		}
+		switch {
+		case rule.TestName == "":
+			http.Error(w, "the rule needs a testName", http.StatusBadRequest)
+			return
+		case rule.EffectiveFrom.IsZero():
+			http.Error(w, "the rule needs an effectiveFrom date", http.StatusBadRequest)
+			return
+		case rule.DueMonths < 0 || rule.DueDays < 0 || rule.DueMonths+rule.DueDays == 0:
+			http.Error(w, "the rule must be due some months or days after birth", http.StatusBadRequest)
+			return
+		}
+		rule.CreatedAt = time.Now()
+		rule.CreatedBy = token.Email
+		created, err := rules.CreateRule(r.Context(), rule)
+		if errors.Is(err, screeningRules.ErrExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"rule": rule, "user": token.Email, "handler": handlerName}).
+				WithError(err).
+				Errorf("error creating the %s rule", kind)
+			internalError(w, err)
+			return
+		}
+		log.WithFields(log.Fields{"rule": created, "user": token.Email}).Infof("created %s rule", kind)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(created); err != nil {
+			log.WithFields(log.Fields{"rule": created, "handler": handlerName}).
+				WithError(err).
+				Errorf("error encoding the %s rule", kind)
+			return
+		}
+	}
+}
+
+// deleteRuleHandler removes a rule of the schedule of kind in rules.
+func deleteRuleHandler(w http.ResponseWriter, r *http.Request, rules screeningRules.Store, kind string) {
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodDelete:
+		id, err := strconv.Atoi(mux.Vars(r)["ruleId"])
+		if err != nil {
+			http.Error(w, "the rule id must be a valid number", http.StatusBadRequest)
+			return
+		}
+		token := r.Context().Value("user").(app.JwtToken)
+		deleted, err := rules.DeleteRule(r.Context(), id)
+		if err != nil {
+			log.WithFields(log.Fields{"ruleId": id, "user": token.Email}).
+				WithError(err).
+				Errorf("error deleting the %s rule", kind)
+			internalError(w, err)
+			return
+		}
+		if !deleted {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.WithFields(log.Fields{"ruleId": id, "user": token.Email}).Infof("deleted %s rule", kind)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
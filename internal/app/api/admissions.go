@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,17 +13,20 @@ import (
 
 	"moh.gov.bz/mch/emtct/internal/app"
 	"moh.gov.bz/mch/emtct/internal/business/data/admissions"
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
 	"moh.gov.bz/mch/emtct/internal/business/data/patient"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type AdmissionRoutes struct {
-	Admissions admissions.Admissions
-	Patients   patient.Patients
+	Admissions admissions.Store
+	Patients   patient.Store
 }
 
 type admissionsResponse struct {
 	HospitalAdmissions []admissions.HospitalAdmission `json:"hospitalAdmissions"`
 	Patient            patient.BasicInfo              `json:"patient"`
+	Page               paging.Info                    `json:"page"`
 }
 
 func (a *AdmissionRoutes) AdmissionsByPatientHandler(w http.ResponseWriter, r *http.Request) {
@@ -40,27 +44,32 @@ func (a *AdmissionRoutes) AdmissionsByPatientHandler(w http.ResponseWriter, r *h
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		admissions, err := a.Admissions.FindByPatientId(id)
+		q, ok := pageQuery(w, r)
+		if !ok {
+			return
+		}
+		admissions, page, err := a.Admissions.FindByPatientId(r.Context(), id, q)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": patientId,
-			}).WithError(err).Error("error retrieving patient's hospital admissions: %+v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}).WithError(err).Error("error retrieving patient's hospital admissions")
+			internalError(w, err)
 			return
 		}
-		patient, err := a.Patients.FindBasicInfo(id)
+		patient, err := a.Patients.FindBasicInfo(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId":  id,
 				"admissions": admissions,
 				"handler":    "HospitalAdmissionsByPatientHandler",
 			}).WithError(err).Error("error retrieving patient information")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		response := admissionsResponse{
 			HospitalAdmissions: admissions,
 			Patient:            *patient,
+			Page:               page,
 		}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -112,16 +121,18 @@ func (a *AdmissionRoutes) AdmissionsHandler(w http.ResponseWriter, r *http.Reque
 			UpdatedAt:      nil,
 			CreatedBy:      user,
 			UpdatedBy:      nil,
+			Version:        1,
 		}
-		err := a.Admissions.Create(admission)
+		err := a.Admissions.Create(r.Context(), admission)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"admission": admission,
 			}).WithError(err).Error("error when posting a request to create a hospital admission")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, admission.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(admission); err != nil {
 			log.WithFields(log.Fields{
@@ -134,28 +145,51 @@ func (a *AdmissionRoutes) AdmissionsHandler(w http.ResponseWriter, r *http.Reque
 	case http.MethodPut:
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
+		version, ok := ifMatch(w, r)
+		if !ok {
+			return
+		}
 
 		var req admissions.HospitalAdmission
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.WithFields(log.Fields{
 				"user": user,
 			}).WithError(err).Error("error while parsing body for editing a hospital admission")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 
 		now := time.Now()
 		req.UpdatedBy = &user
 		req.UpdatedAt = &now
-		err := a.Admissions.Edit(req)
+		req.Version = version
+		err := a.Admissions.Edit(r.Context(), req)
+		if errors.Is(err, db.ErrVersionConflict) {
+			current, err := a.Admissions.FindById(r.Context(), req.Id)
+			if err != nil {
+				log.WithFields(log.Fields{"user": user, "admissionId": req.Id}).
+					WithError(err).
+					Error("error retrieving the current hospital admission")
+				internalError(w, err)
+				return
+			}
+			if current == nil {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			preconditionFailed(w, current, current.Version)
+			return
+		}
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":        user,
 				"requestBody": req,
 			}).WithError(err).Error("failed to edit hospital admission")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		req.Version++
+		setETag(w, req.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(req); err != nil {
 			log.WithFields(log.Fields{
@@ -167,3 +201,14 @@ func (a *AdmissionRoutes) AdmissionsHandler(w http.ResponseWriter, r *http.Reque
 		}
 	}
 }
+
+// DeleteAdmissionHandler soft-deletes a hospital admission that was recorded by mistake.
+func (a *AdmissionRoutes) DeleteAdmissionHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodDelete:
+		softDelete(w, r, "hospitalAdmission", "admissionId", a.Admissions.Delete)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,11 +14,12 @@ import (
 	"moh.gov.bz/mch/emtct/internal/app"
 	"moh.gov.bz/mch/emtct/internal/business/data/contactTracing"
 	"moh.gov.bz/mch/emtct/internal/business/data/patient"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type ContactTracingRoutes struct {
-	ContactTracings contactTracing.ContactTracings
-	Patient         patient.Patients
+	ContactTracings contactTracing.Store
+	Patient         patient.Store
 }
 
 type contactTracingRequest struct {
@@ -45,7 +47,7 @@ func (a *ContactTracingRoutes) ContactTracingHandler(w http.ResponseWriter, r *h
 				"body":    r.Body,
 				"handler": handlerName,
 			}).WithError(err).Error("error decoding json payload")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		location, _ := time.LoadLocation("Local")
@@ -58,16 +60,18 @@ func (a *ContactTracingRoutes) ContactTracingHandler(w http.ResponseWriter, r *h
 			Date:       request.Date.In(location),
 			CreatedBy:  user,
 			CreatedAt:  time.Now(),
+			Version:    1,
 		}
-		if err := a.ContactTracings.Create(contactTracing); err != nil {
+		if err := a.ContactTracings.Create(r.Context(), contactTracing); err != nil {
 			log.WithFields(log.Fields{
 				"user":           user,
 				"contactTracing": contactTracing,
 				"handler":        handlerName,
 			}).WithError(err).Error("error when inserting contact tracing")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, contactTracing.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(contactTracing); err != nil {
 			log.WithFields(log.Fields{
@@ -93,29 +97,34 @@ func (a *ContactTracingRoutes) ContactTracingHandler(w http.ResponseWriter, r *h
 			http.Error(w, "patient id must be a valid number", http.StatusInternalServerError)
 			return
 		}
-		contacts, err := a.ContactTracings.FindByPatientId(patientId)
+		q, ok := pageQuery(w, r)
+		if !ok {
+			return
+		}
+		contacts, page, err := a.ContactTracings.FindByPatientId(r.Context(), patientId, q)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"patientId": patientId,
 				"handler":   handlerName,
 			}).WithError(err).Error("error retrieving patient's contact tracings")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		patient, err := a.Patient.FindBasicInfo(patientId)
+		patient, err := a.Patient.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"patientId": patientId,
 				"handler":   handlerName,
 			}).WithError(err).Error("error retrieving patient's basic information")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		response := map[string]interface{}{
 			"patient":        patient,
 			"contactTracing": contacts,
+			"page":           page,
 		}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -131,6 +140,10 @@ func (a *ContactTracingRoutes) ContactTracingHandler(w http.ResponseWriter, r *h
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
 		today := time.Now()
+		version, ok := ifMatch(w, r)
+		if !ok {
+			return
+		}
 		var contactTracing contactTracing.ContactTracing
 		if err := json.NewDecoder(r.Body).Decode(&contactTracing); err != nil {
 			log.WithFields(log.Fields{
@@ -138,22 +151,42 @@ func (a *ContactTracingRoutes) ContactTracingHandler(w http.ResponseWriter, r *h
 				"handler": handlerName,
 				"body":    r.Body,
 			}).WithError(err).Error("error decoding payload")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		contactTracing.UpdatedBy = user
 		contactTracing.UpdatedAt = &today
 		location, _ := time.LoadLocation("Local")
 		contactTracing.Date = contactTracing.Date.In(location)
-		if err := a.ContactTracings.Edit(contactTracing); err != nil {
+		contactTracing.Version = version
+		err := a.ContactTracings.Edit(r.Context(), contactTracing)
+		if errors.Is(err, db.ErrVersionConflict) {
+			current, err := a.ContactTracings.FindById(r.Context(), contactTracing.Id)
+			if err != nil {
+				log.WithFields(log.Fields{"user": user, "contactTracingId": contactTracing.Id, "handler": handlerName}).
+					WithError(err).
+					Error("error retrieving the current contact tracing")
+				internalError(w, err)
+				return
+			}
+			if current == nil {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			preconditionFailed(w, current, current.Version)
+			return
+		}
+		if err != nil {
 			log.WithFields(log.Fields{
 				"user":           user,
 				"contactTracing": contactTracing,
 				"handler":        handlerName,
 			}).WithError(err).Error("error updaging contact tracing record")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		contactTracing.Version++
+		setETag(w, contactTracing.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(contactTracing); err != nil {
 			log.WithFields(log.Fields{
@@ -166,3 +199,14 @@ func (a *ContactTracingRoutes) ContactTracingHandler(w http.ResponseWriter, r *h
 		}
 	}
 }
+
+// DeleteContactTracingHandler soft-deletes a contact tracing that was recorded by mistake.
+func (a *ContactTracingRoutes) DeleteContactTracingHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodDelete:
+		softDelete(w, r, "contactTracing", "contactTracingId", a.ContactTracings.Delete)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,12 +13,14 @@ import (
 
 	"moh.gov.bz/mch/emtct/internal/app"
 	"moh.gov.bz/mch/emtct/internal/business/data/contraceptives"
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
 	"moh.gov.bz/mch/emtct/internal/business/data/patient"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type ContraceptivesRoutes struct {
-	Contraceptives contraceptives.Contraceptives
-	Patients       patient.Patients
+	Contraceptives contraceptives.Store
+	Patients       patient.Store
 }
 
 type newContraceptivesRequest struct {
@@ -31,6 +34,7 @@ type newContraceptivesRequest struct {
 type contraceptivesResponse struct {
 	Contraceptives []contraceptives.ContraceptiveUsed `json:"contraceptives"`
 	Patient        patient.BasicInfo                  `json:"patient"`
+	Page           paging.Info                        `json:"page"`
 }
 
 func (a *ContraceptivesRoutes) ContraceptivesByPatientHandler(w http.ResponseWriter, r *http.Request) {
@@ -51,27 +55,32 @@ func (a *ContraceptivesRoutes) ContraceptivesByPatientHandler(w http.ResponseWri
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		contraceptives, err := a.Contraceptives.FindByPatientId(id)
+		q, ok := pageQuery(w, r)
+		if !ok {
+			return
+		}
+		contraceptives, page, err := a.Contraceptives.FindByPatientId(r.Context(), id, q)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": patientId,
 			}).WithError(err).Error("failure retrieving a patient's contraceptives used")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		patient, err := a.Patients.FindBasicInfo(id)
+		patient, err := a.Patients.FindBasicInfo(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId":      id,
 				"contraceptives": contraceptives,
 				"handler":        "ContraceptivesByPatientHandler",
 			}).WithError(err).Error("error retrieving patient's information")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		response := contraceptivesResponse{
 			Contraceptives: contraceptives,
 			Patient:        *patient,
+			Page:           page,
 		}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -112,17 +121,19 @@ func (a *ContraceptivesRoutes) ContraceptivesHandler(w http.ResponseWriter, r *h
 			UpdatedAt:      nil,
 			CreatedBy:      user,
 			UpdatedBy:      nil,
+			Version:        1,
 		}
-		err := a.Contraceptives.Create(contraceptive)
+		err := a.Contraceptives.Create(r.Context(), contraceptive)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":          user,
 				"request":       req,
 				"contraceptive": contraceptive,
 			}).WithError(err).Error("failed to create new contraceptive used")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, contraceptive.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(contraceptive); err != nil {
 			log.WithFields(log.Fields{
@@ -135,6 +146,10 @@ func (a *ContraceptivesRoutes) ContraceptivesHandler(w http.ResponseWriter, r *h
 	case http.MethodPut:
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
+		version, ok := ifMatch(w, r)
+		if !ok {
+			return
+		}
 		var req contraceptives.ContraceptiveUsed
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.WithError(err).Error("failed to parse the body for creating a contraceptive")
@@ -151,18 +166,38 @@ func (a *ContraceptivesRoutes) ContraceptivesHandler(w http.ResponseWriter, r *h
 			DateUsed:       req.DateUsed,
 			UpdatedAt:      &updated,
 			UpdatedBy:      &user,
+			Version:        version,
 		}
 
-		if err := a.Contraceptives.Edit(contraceptive); err != nil {
+		err := a.Contraceptives.Edit(r.Context(), contraceptive)
+		if errors.Is(err, db.ErrVersionConflict) {
+			current, err := a.Contraceptives.FindById(r.Context(), req.Id)
+			if err != nil {
+				log.WithFields(log.Fields{"user": user, "contraceptiveId": req.Id}).
+					WithError(err).
+					Error("error retrieving the current contraceptive")
+				internalError(w, err)
+				return
+			}
+			if current == nil {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			preconditionFailed(w, current, current.Version)
+			return
+		}
+		if err != nil {
 			log.WithFields(log.Fields{
 				"user":          user,
 				"request":       req,
 				"contraceptive": contraceptive,
 			}).WithError(err).Error("failed to create new contraceptive used")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 
+		contraceptive.Version++
+		setETag(w, contraceptive.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(contraceptive); err != nil {
 			log.WithFields(log.Fields{
@@ -174,3 +209,14 @@ func (a *ContraceptivesRoutes) ContraceptivesHandler(w http.ResponseWriter, r *h
 		}
 	}
 }
+
+// DeleteContraceptiveHandler soft-deletes a contraceptive that was recorded by mistake.
+func (a *ContraceptivesRoutes) DeleteContraceptiveHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodDelete:
+		softDelete(w, r, "contraceptiveUsed", "contraceptiveId", a.Contraceptives.Delete)
+	}
+}
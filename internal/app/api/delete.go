@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/app"
+)
+
+// DeleteFunc soft-deletes the record with the given id. It returns false when the record
+// does not exist or was already deleted.
+type DeleteFunc func(ctx context.Context, id, user, reason string) (bool, error)
+
+// RestoreFunc undoes the deletion of the record with the given id by user. It returns false
+// when the record does not exist or is not deleted.
+type RestoreFunc func(ctx context.Context, id, user string) (bool, error)
+
+type deleteRequest struct {
+	Reason string `json:"reason"`
+}
+
+// softDelete deletes the record of the given kind whose id is in the idVar of the route. The
+// body must give the reason for the deletion. It responds with a 204, or a 404 when there is
+// no record to delete.
+func softDelete(w http.ResponseWriter, r *http.Request, kind, idVar string, del DeleteFunc) {
+	id := mux.Vars(r)[idVar]
+	token := r.Context().Value("user").(app.JwtToken)
+	var req deleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(strings.TrimSpace(req.Reason)) == 0 {
+		http.Error(w, "the reason for deleting the record is required", http.StatusBadRequest)
+		return
+	}
+	deleted, err := del(r.Context(), id, token.Email, req.Reason)
+	if err != nil {
+		log.WithFields(log.Fields{"kind": kind, "id": id, "user": token.Email}).
+			WithError(err).
+			Error("error deleting the record")
+		internalError(w, err)
+		return
+	}
+	if !deleted {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	log.WithFields(log.Fields{"kind": kind, "id": id, "user": token.Email, "reason": req.Reason}).
+		Info("deleted record")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/lib/pq"
+)
+
+// queryCanceled is the postgres error code of a statement that was cancelled, either by a
+// statement_timeout or by the driver when the context of the query expired.
+const queryCanceled = "57014"
+
+// isTimeout reports whether err was caused by a database query that ran out of time.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == queryCanceled
+}
+
+// internalError responds with a 504 when err is a database query that timed out, so that
+// clients can tell a slow ACSIS apart from a failure. Any other error is a 500.
+func internalError(w http.ResponseWriter, err error) {
+	if isTimeout(err) {
+		http.Error(w, "the database took too long to respond, please try again later", http.StatusGatewayTimeout)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
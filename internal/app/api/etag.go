@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// setETag sends the version of a record as its ETag. The ETag must be sent back in the
+// If-Match header to edit the record.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatch returns the version of the record in the If-Match header of an edit. When the header
+// is missing it responds with a 428, or with a 400 when it is not the ETag of a record, and
+// returns false.
+func ifMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	etag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if len(etag) == 0 {
+		http.Error(w, "the If-Match header with the ETag of the record is required", http.StatusPreconditionRequired)
+		return 0, false
+	}
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(etag, "W/"), `"`))
+	if err != nil {
+		http.Error(w, "the If-Match header is not the ETag of a record", http.StatusBadRequest)
+		return 0, false
+	}
+	return version, true
+}
+
+// preconditionFailed responds to an edit of a record that was saved by someone else in the
+// meantime. The current record is sent with a 412 so that the user can redo their changes.
+func preconditionFailed(w http.ResponseWriter, current interface{}, version int) {
+	setETag(w, version)
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusPreconditionFailed)
+	if err := json.NewEncoder(w).Encode(current); err != nil {
+		log.WithFields(log.Fields{"record": current}).
+			WithError(err).
+			Error("error marshalling the current version of the record")
+	}
+}
@@ -1,20 +1,51 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 
+	"moh.gov.bz/mch/emtct/internal/app"
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/business/data/infant"
 	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/business/etl"
 )
 
+// PregnancySyncer is implemented by etl.PregnancySync.
+type PregnancySyncer interface {
+	SyncYear(ctx context.Context, trigger etlRuns.Trigger, user string, year int) (*etl.YearResult, error)
+	PreviewYear(ctx context.Context, year int) (*etl.YearPreview, error)
+	SyncModified(ctx context.Context, trigger etlRuns.Trigger, user string) (*pregnancy.UpsertResult, error)
+}
+
+// BirthSyncer is implemented by etl.BirthSync.
+type BirthSyncer interface {
+	Sync(ctx context.Context, trigger etlRuns.Trigger, user string) (*infant.BirthsUpsertResult, error)
+}
+
+// LabResultSyncer is implemented by etl.LabResultSync.
+type LabResultSyncer interface {
+	Sync(ctx context.Context, trigger etlRuns.Trigger, user string) (*etl.LabResultSyncResult, error)
+}
+
 type Etl struct {
-	Pregnancies pregnancy.Pregnancies
+	Sync        PregnancySyncer
+	Births      BirthSyncer
+	LabResults  LabResultSyncer
+	Runs        etlRuns.Store
+	Pregnancies pregnancy.Store
 }
 
 type pregnancyEtlRequest struct {
 	Year int `json:"year"`
+	// DryRun returns what the sync would change without writing anything.
+	DryRun bool `json:"dryRun"`
 }
 
 func (e Etl) PregnancyEtlHandler(w http.ResponseWriter, r *http.Request) {
@@ -25,65 +56,320 @@ func (e Etl) PregnancyEtlHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	case http.MethodPost:
 		method := "POST"
+		token := r.Context().Value("user").(app.JwtToken)
+		user := token.Email
 
 		var req pregnancyEtlRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.WithFields(log.Fields{
+				"user":    user,
 				"method":  method,
 				"handler": handlerName,
 			}).WithError(err).Error("could not decode request")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 
 		yr := req.Year
-		existingPregnancies, err := e.Pregnancies.FindExistingPregnanciesByYear(yr)
-		if err != nil {
+		if req.DryRun {
+			e.previewYear(r.Context(), w, yr)
+			return
+		}
+		result, err := e.Sync.SyncYear(r.Context(), etlRuns.Manual, user, yr)
+		if errors.Is(err, etl.ErrSyncInProgress) {
 			log.WithFields(log.Fields{
 				"year":    yr,
+				"user":    user,
 				"method":  method,
 				"handler": handlerName,
-			}).WithError(err).Error("error while fetching existing pregnancies")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}).WithError(err).Info("refusing to start a second pregnancy sync")
+			http.Error(w, "a pregnancy sync is already in progress, try again later", http.StatusConflict)
 			return
 		}
-
-		acsisPregnancies, err := e.Pregnancies.FindPregnanciesInBhisByYear(yr)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"year":    yr,
+				"user":    user,
 				"method":  method,
 				"handler": handlerName,
-			}).WithError(err).Error("error retrieving pregnancies from acsis")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}).WithError(err).Error("error syncing pregnancies from acsis")
+			internalError(w, err)
 			return
 		}
-		var pregs []pregnancy.Pregnancy
-		for _, p := range acsisPregnancies {
-			if !p.Include(existingPregnancies) {
-				pregs = append(pregs, p)
-			}
-		}
-		err = e.Pregnancies.Create(r.Context(), pregs)
-		if err != nil {
+		pregs := result.Inserted
+		w.Header().Add("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(pregs); err != nil {
 			log.WithFields(log.Fields{
 				"year":        yr,
 				"pregnancies": pregs,
 				"method":      method,
 				"handler":     handlerName,
-			}).WithError(err).Error("error inserting pregnancy into emtct db")
+			}).WithError(err).Error("failed to encode pregnancies")
+			http.Error(w, "inserted pregnancies but failed to encode them", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// previewYear writes what a sync of the given year would insert, update and remove.
+func (e Etl) previewYear(ctx context.Context, w http.ResponseWriter, year int) {
+	preview, err := e.Sync.PreviewYear(ctx, year)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"year":    year,
+			"handler": "PregnancyEtlHandler",
+		}).WithError(err).Error("error previewing the pregnancy sync")
+		internalError(w, err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		log.WithFields(log.Fields{
+			"year":    year,
+			"preview": preview,
+			"handler": "PregnancyEtlHandler",
+		}).WithError(err).Error("failed to encode the pregnancy sync preview")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// IncrementalPregnancyEtlHandler upserts the ACSIS pregnancies that were modified since the
+// last incremental sync.
+func (e Etl) IncrementalPregnancyEtlHandler(w http.ResponseWriter, r *http.Request) {
+	handlerName := "IncrementalPregnancyEtlHandler"
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodPost:
+		method := "POST"
+		token := r.Context().Value("user").(app.JwtToken)
+		user := token.Email
+		result, err := e.Sync.SyncModified(r.Context(), etlRuns.Manual, user)
+		if errors.Is(err, etl.ErrSyncInProgress) {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Info("refusing to start a second pregnancy sync")
+			http.Error(w, "a pregnancy sync is already in progress, try again later", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("error syncing modified pregnancies from acsis")
+			internalError(w, err)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(result); err != nil {
+			log.WithFields(log.Fields{
+				"result":  result,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("failed to encode the pregnancy sync result")
+			http.Error(w, "synced pregnancies but failed to encode the result", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// BirthsEtlHandler upserts the ACSIS births of the mothers with a pregnancy in the emtct
+// database, and links each infant to the pregnancy it was born from.
+func (e Etl) BirthsEtlHandler(w http.ResponseWriter, r *http.Request) {
+	handlerName := "BirthsEtlHandler"
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodPost:
+		method := "POST"
+		token := r.Context().Value("user").(app.JwtToken)
+		user := token.Email
+		result, err := e.Births.Sync(r.Context(), etlRuns.Manual, user)
+		if errors.Is(err, etl.ErrSyncInProgress) {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Info("refusing to start a second births sync")
+			http.Error(w, "a births sync is already in progress, try again later", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("error syncing births from acsis")
+			internalError(w, err)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(result); err != nil {
+			log.WithFields(log.Fields{
+				"result":  result,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("failed to encode the births sync result")
+			http.Error(w, "synced births but failed to encode the result", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// LabResultsEtlHandler copies the ACSIS lab results of the emtct pregnancies into the emtct database.
+func (e Etl) LabResultsEtlHandler(w http.ResponseWriter, r *http.Request) {
+	handlerName := "LabResultsEtlHandler"
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodPost:
+		method := "POST"
+		token := r.Context().Value("user").(app.JwtToken)
+		user := token.Email
+		result, err := e.LabResults.Sync(r.Context(), etlRuns.Manual, user)
+		if errors.Is(err, etl.ErrSyncInProgress) {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Info("refusing to start a second lab results sync")
+			http.Error(w, "a lab results sync is already in progress, try again later", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("error syncing lab results from acsis")
+			internalError(w, err)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(result); err != nil {
+			log.WithFields(log.Fields{
+				"result":  result,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("failed to encode the lab results sync result")
+			http.Error(w, "synced lab results but failed to encode the result", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// defaultEtlRunsLimit is the number of runs returned when the limit query parameter is missing.
+const defaultEtlRunsLimit = 50
+
+// EtlRunsHandler lists the most recent etl runs, newest first.
+func (e Etl) EtlRunsHandler(w http.ResponseWriter, r *http.Request) {
+	handlerName := "EtlRunsHandler"
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		limit := defaultEtlRunsLimit
+		if l := r.URL.Query().Get("limit"); len(l) > 0 {
+			n, err := strconv.Atoi(l)
+			if err != nil || n < 1 {
+				log.WithFields(log.Fields{
+					"limit":   l,
+					"handler": handlerName,
+				}).WithError(err).Error("limit is not a positive number")
+				http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		runs, err := e.Runs.FindLatest(r.Context(), limit)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"limit":   limit,
+				"handler": handlerName,
+			}).WithError(err).Error("error retrieving etl runs")
+			internalError(w, err)
+			return
+		}
+		// Return an empty array if no results are found
+		if runs == nil {
+			runs = []etlRuns.Run{}
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(runs); err != nil {
+			log.WithFields(log.Fields{
+				"runs":    runs,
+				"handler": handlerName,
+			}).WithError(err).Error("error encoding etl runs")
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
+	}
+}
+
+type etlRunResponse struct {
+	Run     etlRuns.Run           `json:"run"`
+	Changes []pregnancy.Change    `json:"changes"`
+	Errors  []etlRuns.RecordError `json:"errors"`
+}
+
+// EtlRunHandler returns a single etl run with the pregnancy fields it changed and the
+// records it skipped.
+func (e Etl) EtlRunHandler(w http.ResponseWriter, r *http.Request) {
+	handlerName := "EtlRunHandler"
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		runId := mux.Vars(r)["runId"]
+		run, err := e.Runs.FindById(r.Context(), runId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"runId":   runId,
+				"handler": handlerName,
+			}).WithError(err).Error("error retrieving etl run")
+			internalError(w, err)
+			return
+		}
+		if run == nil {
+			http.Error(w, "etl run does not exist", http.StatusNotFound)
+			return
+		}
+		changes, err := e.Pregnancies.FindChangesByRun(r.Context(), runId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"runId":   runId,
+				"handler": handlerName,
+			}).WithError(err).Error("error retrieving the pregnancy changes of an etl run")
+			internalError(w, err)
+			return
+		}
+		if changes == nil {
+			changes = []pregnancy.Change{}
+		}
+		errs, err := e.Runs.FindErrors(r.Context(), runId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"runId":   runId,
+				"handler": handlerName,
+			}).WithError(err).Error("error retrieving the errors of an etl run")
+			internalError(w, err)
+			return
+		}
+		if errs == nil {
+			errs = []etlRuns.RecordError{}
+		}
+		response := etlRunResponse{Run: *run, Changes: changes, Errors: errs}
 		w.Header().Add("Content-Type", "application/json")
-		if err = json.NewEncoder(w).Encode(pregs); err != nil {
+		if err := json.NewEncoder(w).Encode(response); err != nil {
 			log.WithFields(log.Fields{
-				"year":        yr,
-				"pregnancies": pregs,
-				"method":      method,
-				"handler":     handlerName,
-			}).WithError(err).Error("failed to encode pregnancies")
-			http.Error(w, "inserted pregnancies but failed to encode them", http.StatusInternalServerError)
+				"response": response,
+				"handler":  handlerName,
+			}).WithError(err).Error("error encoding etl run")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
 	}
@@ -10,43 +10,167 @@ import (
 
 	"moh.gov.bz/mch/emtct/internal/app"
 	"moh.gov.bz/mch/emtct/internal/business/data/admissions"
+	"moh.gov.bz/mch/emtct/internal/business/data/audit"
 	"moh.gov.bz/mch/emtct/internal/business/data/contactTracing"
 	"moh.gov.bz/mch/emtct/internal/business/data/contraceptives"
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
 	"moh.gov.bz/mch/emtct/internal/business/data/hiv"
+	"moh.gov.bz/mch/emtct/internal/business/data/hivScreenings"
 	"moh.gov.bz/mch/emtct/internal/business/data/homeVisits"
 	"moh.gov.bz/mch/emtct/internal/business/data/infant"
 	"moh.gov.bz/mch/emtct/internal/business/data/labs"
 	"moh.gov.bz/mch/emtct/internal/business/data/partners"
 	"moh.gov.bz/mch/emtct/internal/business/data/patient"
 	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/business/etl"
+	"moh.gov.bz/mch/emtct/internal/cache"
 )
 
-func API(app app.App) *mux.Router {
-	r := mux.NewRouter()
+// Stores are the data stores and etl jobs that are used by the handlers. Tests use the
+// in-memory fakes of the data packages instead of the databases.
+type Stores struct {
+	Pregnancies            pregnancy.Store
+	Labs                   labs.Store
+	Infants                infant.Store
+	Patients               patient.Store
+	Hiv                    hiv.Store
+	HivScreenings          hivScreenings.Store
+	SyphilisScreeningRules screeningRules.Store
+	HomeVisits             homeVisits.Store
+	Admissions             admissions.Store
+	Contraceptives         contraceptives.Store
+	ContactTracings        contactTracing.Store
+	Partners               partners.Store
+	Runs                   etlRuns.Store
+	Audit                  audit.Store
+	PregnancySync          PregnancySyncer
+	BirthSync              BirthSyncer
+	LabResultSync          LabResultSyncer
+	Caches                 Caches
+}
 
+func API(app app.App) *mux.Router {
 	// Instantiate an aut0 client with a Cache with a key capacity of
 	// 60 tokens and a ttl of 24 hours.
 	auth0Client := auth0.NewAuth0(60, 518400)
 
 	// Middleware that verifies JWT token and also enables CORS.
 	authMid := NewChain(EnableCors(), VerifyToken(app.Auth.JwkUrl, app.Auth.Aud, app.Auth.Iss, auth0Client))
+	adminMid := authMid.Append(RequireAdmin(app.Auth.Admins))
 
 	pregnancies := pregnancy.New(app.EmtctDb, app.AcsisDb)
-	lab := labs.New(app.AcsisDb)
+	lab := labs.New(app.AcsisDb, app.EmtctDb)
+	runs := etlRuns.New(app.EmtctDb)
+	inf := infant.New(app.AcsisDb, app.EmtctDb)
+	patients := patient.New(app.AcsisDb)
+	Hiv := hiv.New(app.AcsisDb)
+	screenings := hivScreenings.New(app.EmtctDb)
+	visits := homeVisits.New(app.EmtctDb)
+	hospitalAdmissions := admissions.New(app.EmtctDb)
+	contraceptive := contraceptives.New(app.EmtctDb)
+	tracing := contactTracing.New(app.EmtctDb)
+	syphilisTreatments := partners.New(app.EmtctDb)
+	auditLog := audit.New(app.EmtctDb)
+	stores := Stores{
+		Pregnancies:            &pregnancies,
+		Labs:                   &lab,
+		Infants:                &inf,
+		Patients:               &patients,
+		Hiv:                    &Hiv,
+		HivScreenings:          &screenings,
+		SyphilisScreeningRules: labs.NewSyphilisScreeningRules(app.EmtctDb),
+		HomeVisits:             &visits,
+		Admissions:             &hospitalAdmissions,
+		Contraceptives:         &contraceptive,
+		ContactTracings:        &tracing,
+		Partners:               &syphilisTreatments,
+		Runs:                   &runs,
+		Audit:                  &auditLog,
+		PregnancySync:          etl.NewPregnancySync(pregnancies, runs),
+		BirthSync:              etl.NewBirthSync(pregnancies, inf, runs),
+		LabResultSync:          etl.NewLabResultSync(app.EmtctDb, lab, runs),
+	}
+	// Cache the ACSIS lookups that are repeated on every request.
+	if app.Cache.Patients > 0 {
+		stores.Caches.Patients = cache.New(app.Cache.Patients)
+		stores.Patients = patient.NewCached(stores.Patients, stores.Caches.Patients)
+	}
+	if app.Cache.Infants > 0 {
+		stores.Caches.Infants = cache.New(app.Cache.Infants)
+		stores.Infants = infant.NewCached(stores.Infants, stores.Caches.Infants)
+	}
+	return NewRouter(stores, authMid, adminMid)
+}
+
+// NewRouter registers every route of the api. The handlers are wrapped in authMid, which
+// must put the app.JwtToken of the user in the "user" value of the request context. The
+// admin handlers are wrapped in adminMid, which must also only let admins through.
+func NewRouter(s Stores, authMid, adminMid Chain) *mux.Router {
+	r := mux.NewRouter()
+
+	// Admin
+	adminRoutes := AdminRoutes{
+		Caches: s.Caches,
+		Restorers: map[string]RestoreFunc{
+			"homeVisits":         s.HomeVisits.Restore,
+			"hospitalAdmissions": s.Admissions.Restore,
+			"contraceptivesUsed": s.Contraceptives.Restore,
+			"contactTracing":     s.ContactTracings.Restore,
+			"syphilisTreatments": s.Partners.RestorePartnerSyphilisTreatment,
+			"hivScreenings":      s.HivScreenings.Restore,
+		},
+		HivScreeningRules:      s.HivScreenings,
+		SyphilisScreeningRules: s.SyphilisScreeningRules,
+	}
+	adminRouter := r.PathPrefix("/api/admin").Subrouter()
+	adminRouter.HandleFunc("/cache", adminMid.Then(adminRoutes.CacheStatsHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
+	adminRouter.HandleFunc("/cache/patients/{patientId}", adminMid.Then(adminRoutes.InvalidatePatientHandler)).
+		Methods(http.MethodOptions, http.MethodDelete)
+	adminRouter.HandleFunc("/hivScreeningRules", adminMid.Then(adminRoutes.HivScreeningRulesHandler)).
+		Methods(http.MethodOptions, http.MethodGet, http.MethodPost)
+	adminRouter.HandleFunc("/hivScreeningRules/{ruleId}", adminMid.Then(adminRoutes.DeleteHivScreeningRuleHandler)).
+		Methods(http.MethodOptions, http.MethodDelete)
+	adminRouter.HandleFunc("/syphilisScreeningRules", adminMid.Then(adminRoutes.SyphilisScreeningRulesHandler)).
+		Methods(http.MethodOptions, http.MethodGet, http.MethodPost)
+	adminRouter.HandleFunc("/syphilisScreeningRules/{ruleId}", adminMid.Then(adminRoutes.DeleteSyphilisScreeningRuleHandler)).
+		Methods(http.MethodOptions, http.MethodDelete)
+	adminRouter.HandleFunc("/{kind}/{id}/restore", adminMid.Then(adminRoutes.RestoreHandler)).
+		Methods(http.MethodOptions, http.MethodPost)
+
+	// History of the EMTCT records
+	history := HistoryRoutes{Audit: s.Audit}
+
 	// ETL
-	etl := Etl{
-		Pregnancies: pregnancies,
+	etlRoutes := Etl{
+		Sync:        s.PregnancySync,
+		Births:      s.BirthSync,
+		LabResults:  s.LabResultSync,
+		Runs:        s.Runs,
+		Pregnancies: s.Pregnancies,
 	}
 	eltRouter := r.PathPrefix("/api/etl").Subrouter()
-	eltRouter.HandleFunc("/pregnancies", authMid.Then(etl.PregnancyEtlHandler)).
+	eltRouter.HandleFunc("/pregnancies", authMid.Then(etlRoutes.PregnancyEtlHandler)).
+		Methods(http.MethodOptions, http.MethodPost)
+	eltRouter.HandleFunc("/pregnancies/incremental", authMid.Then(etlRoutes.IncrementalPregnancyEtlHandler)).
+		Methods(http.MethodOptions, http.MethodPost)
+	eltRouter.HandleFunc("/births", authMid.Then(etlRoutes.BirthsEtlHandler)).
 		Methods(http.MethodOptions, http.MethodPost)
+	eltRouter.HandleFunc("/labResults", authMid.Then(etlRoutes.LabResultsEtlHandler)).
+		Methods(http.MethodOptions, http.MethodPost)
+	eltRouter.HandleFunc("/runs", authMid.Then(etlRoutes.EtlRunsHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
+	eltRouter.HandleFunc("/runs/{runId}", authMid.Then(etlRoutes.EtlRunHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
 
 	// Infants
-	inf := infant.New(app.AcsisDb.DB)
 	infantRoutes := InfantRoutes{
-		Infant:      infant.Infants{Acsis: inf.Acsis},
-		Pregnancies: pregnancies,
-		Labs:        lab,
+		Infant:                 s.Infants,
+		HivScreenings:          s.HivScreenings,
+		SyphilisScreeningRules: s.SyphilisScreeningRules,
+		Pregnancies:            s.Pregnancies,
+		Labs:                   s.Labs,
 	}
 	infantRouter := r.PathPrefix("/api/infants").Subrouter()
 	infantRouter.HandleFunc("/diagnoses/{infantId}", authMid.Then(infantRoutes.InfantDiagnosesHandler)).
@@ -55,21 +179,25 @@ func API(app app.App) *mux.Router {
 		Methods(http.MethodGet, http.MethodOptions)
 	infantRouter.HandleFunc("/{infantId}/syphilisScreenings", authMid.Then(infantRoutes.InfantSyphilisScreeninngHandler)).
 		Methods(http.MethodOptions, http.MethodGet)
+	infantRouter.HandleFunc("/{infantId}/hivTestingSchedule", authMid.Then(infantRoutes.HivTestingScheduleHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
+	infantRouter.HandleFunc("/{infantId}/pregnancy", authMid.Then(infantRoutes.InfantPregnancyHandler)).
+		Methods(http.MethodOptions, http.MethodGet, http.MethodPut)
 	infantRouter.HandleFunc("/{patientId}", authMid.Then(infantRoutes.InfantHandlers)).
 		Methods(http.MethodOptions, http.MethodGet)
 
 	// Patients
-	patients := patient.New(app.AcsisDb.DB)
 	patientRouter := r.PathPrefix("/api/patients").Subrouter()
 
 	// HomeVisits
-	visits := homeVisits.New(app.EmtctDb.DB)
 	homeVisitRoutes := HomeVisitRoutes{
-		HomeVisits: visits,
-		Patients:   patients,
+		HomeVisits: s.HomeVisits,
+		Patients:   s.Patients,
 	}
 	homeVisitsRouter := r.PathPrefix("/api/homeVisits").Subrouter()
 	homeVisitsRouter.HandleFunc("/{homeVisitId}", authMid.Then(homeVisitRoutes.HomeVisitsHandler)).
+		Methods(http.MethodOptions, http.MethodGet, http.MethodDelete)
+	homeVisitsRouter.HandleFunc("/{homeVisitId}/history", authMid.Then(history.HistoryHandler("home_visit", "homeVisitId"))).
 		Methods(http.MethodOptions, http.MethodGet)
 	homeVisitsRouter.HandleFunc("", authMid.Then(homeVisitRoutes.HomeVisitsHandler)).
 		Methods(http.MethodOptions, http.MethodPost, http.MethodPut)
@@ -77,55 +205,68 @@ func API(app app.App) *mux.Router {
 		Methods(http.MethodPost, http.MethodGet, http.MethodOptions)
 
 	// Admissions
-	hospitalAdmissions := admissions.New(app.EmtctDb.DB)
 	admissionRoutes := AdmissionRoutes{
-		Admissions: hospitalAdmissions,
-		Patients:   patients,
+		Admissions: s.Admissions,
+		Patients:   s.Patients,
 	}
 	admissionRouter := r.PathPrefix("/api/hospitalAdmissions").Subrouter()
 	admissionRouter.HandleFunc("", authMid.Then(admissionRoutes.AdmissionsHandler)).
 		Methods(http.MethodPost, http.MethodPut, http.MethodOptions)
+	admissionRouter.HandleFunc("/{admissionId}", authMid.Then(admissionRoutes.DeleteAdmissionHandler)).
+		Methods(http.MethodOptions, http.MethodDelete)
+	admissionRouter.HandleFunc("/{admissionId}/history", authMid.Then(history.HistoryHandler("hospital_admission", "admissionId"))).
+		Methods(http.MethodOptions, http.MethodGet)
 	patientRouter.HandleFunc("/{patientId}/hospitalAdmissions", authMid.Then(admissionRoutes.AdmissionsByPatientHandler)).
 		Methods(http.MethodOptions, http.MethodGet)
 
 	// Contraceptives
-	contraceptive := contraceptives.New(app.EmtctDb.DB)
 	contraceptiveRoutes := ContraceptivesRoutes{
-		Contraceptives: contraceptive,
-		Patients:       patients,
+		Contraceptives: s.Contraceptives,
+		Patients:       s.Patients,
 	}
 	contraceptiveRouter := r.PathPrefix("/api/contraceptivesUsed").Subrouter()
 	contraceptiveRouter.HandleFunc("", authMid.Then(contraceptiveRoutes.ContraceptivesHandler)).
 		Methods(http.MethodPost, http.MethodPut, http.MethodOptions)
+	contraceptiveRouter.HandleFunc("/{contraceptiveId}", authMid.Then(contraceptiveRoutes.DeleteContraceptiveHandler)).
+		Methods(http.MethodOptions, http.MethodDelete)
+	contraceptiveRouter.HandleFunc("/{contraceptiveId}/history",
+		authMid.Then(history.HistoryHandler("contraceptive_used", "contraceptiveId"))).Methods(http.MethodOptions, http.MethodGet)
 	patientRouter.HandleFunc("/{patientId}/contraceptivesUsed", authMid.Then(contraceptiveRoutes.ContraceptivesByPatientHandler)).
 		Methods(http.MethodOptions, http.MethodGet)
 
 	// Partners Router
 	partnersRouter := r.PathPrefix("/api/partners").Subrouter()
 	partnerRoutes := partnersRoutes{
-		Patient:  patients,
-		Partners: partners.New(app.EmtctDb),
+		Patient:  s.Patients,
+		Partners: s.Partners,
 	}
 
 	// Contact Tracing
-	tracing := contactTracing.New(app.EmtctDb.DB)
 	tracingRoutes := ContactTracingRoutes{
-		ContactTracings: tracing,
-		Patient:         patients,
+		ContactTracings: s.ContactTracings,
+		Patient:         s.Patients,
 	}
 	partnersRouter.HandleFunc("/contactTracing", authMid.Then(tracingRoutes.ContactTracingHandler)).
 		Methods(http.MethodOptions, http.MethodPost, http.MethodPut)
+	partnersRouter.HandleFunc("/contactTracing/{contactTracingId}", authMid.Then(tracingRoutes.DeleteContactTracingHandler)).
+		Methods(http.MethodOptions, http.MethodDelete)
+	partnersRouter.HandleFunc("/contactTracing/{contactTracingId}/history",
+		authMid.Then(history.HistoryHandler("contact_tracing", "contactTracingId"))).Methods(http.MethodOptions, http.MethodGet)
+	partnersRouter.HandleFunc("/syphilisTreatments/{treatmentId}", authMid.Then(partnerRoutes.DeleteSyphilisTreatmentHandler)).
+		Methods(http.MethodOptions, http.MethodDelete)
+	partnersRouter.HandleFunc("/syphilisTreatments/{treatmentId}/history",
+		authMid.Then(history.HistoryHandler("syphilis_treatment_partner", "treatmentId"))).Methods(http.MethodOptions, http.MethodGet)
 	partnersRouter.HandleFunc("/{patientId}/contactTracing", authMid.Then(tracingRoutes.ContactTracingHandler)).
 		Methods(http.MethodOptions, http.MethodGet)
 	partnersRouter.HandleFunc("/{patientId}/syphilisTreatments", authMid.Then(partnerRoutes.SyphilisTreatmentHandler)).
 		Methods(http.MethodOptions, http.MethodGet, http.MethodPost, http.MethodPut)
 
 	// Pregnancies
-	preg := pregnancy.New(app.EmtctDb, app.AcsisDb)
-	Hiv := hiv.New(app.AcsisDb)
-	pregRoutes := pregnancyRoutes{Pregnancies: preg, Patient: patients, Lab: lab, Hiv: Hiv}
+	pregRoutes := pregnancyRoutes{Pregnancies: s.Pregnancies, Patient: s.Patients, Lab: s.Labs, Hiv: s.Hiv}
+	patientRouter.HandleFunc("/{patientId}/pregnancies", authMid.Then(pregRoutes.PregnanciesHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
 	patientRouter.HandleFunc("/{patientId}/currentPregnancy",
-		authMid.Then(pregRoutes.FindCurrentPregnancy)).Methods(http.MethodOptions, http.MethodGet)
+		authMid.Then(pregRoutes.CurrentPregnancyHandler)).Methods(http.MethodOptions, http.MethodGet)
 	patientRouter.HandleFunc("/{patientId}/currentPregnancy/labResults",
 		authMid.Then(pregRoutes.FindPregnancyLabResults)).Methods(http.MethodOptions, http.MethodGet)
 	patientRouter.HandleFunc("/{patientId}/obstetricHistory", authMid.Then(pregRoutes.ObstetricHistoryHandler)).
@@ -134,8 +275,23 @@ func API(app app.App) *mux.Router {
 		Methods(http.MethodOptions, http.MethodGet)
 	patientRouter.HandleFunc("/{patientId}/syphilisTreatments", authMid.Then(pregRoutes.PatientSyphilisTreatmentHandler)).
 		Methods(http.MethodOptions, http.MethodGet)
+	// The pregnancy of these urls is the one in pregnancyId; the patient urls above are about
+	// the current pregnancy.
+	pregnancyRouter := r.PathPrefix("/api/pregnancies").Subrouter()
+	pregnancyRouter.HandleFunc("/{pregnancyId}/vitals", authMid.Then(pregRoutes.VitalsHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
+	pregnancyRouter.HandleFunc("/{pregnancyId}/labResults", authMid.Then(pregRoutes.FindPregnancyLabResults)).
+		Methods(http.MethodOptions, http.MethodGet)
+	pregnancyRouter.HandleFunc("/{pregnancyId}/arvs", authMid.Then(pregRoutes.ArvsHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
+	pregnancyRouter.HandleFunc("/{pregnancyId}/infants", authMid.Then(infantRoutes.PregnancyInfantsHandler)).
+		Methods(http.MethodOptions, http.MethodGet)
 	patientRouter.HandleFunc("/{motherId}/infant/{infantId}/hivScreenings", authMid.Then(infantRoutes.HivScreeningHandler)).
 		Methods(http.MethodOptions, http.MethodPost, http.MethodPut, http.MethodGet)
+	patientRouter.HandleFunc("/{motherId}/infant/{infantId}/hivScreenings/{screeningId}",
+		authMid.Then(infantRoutes.DeleteHivScreeningHandler)).Methods(http.MethodOptions, http.MethodDelete)
+	patientRouter.HandleFunc("/{motherId}/infant/{infantId}/hivScreenings/{screeningId}/history",
+		authMid.Then(history.HistoryHandler("hiv_screening", "screeningId"))).Methods(http.MethodOptions, http.MethodGet)
 
 	patientRouter.HandleFunc("/{id}", authMid.Then(pregRoutes.RetrievePatientHandler)).
 		Methods(http.MethodOptions, http.MethodGet)
@@ -0,0 +1,783 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"moh.gov.bz/mch/emtct/internal/app"
+	"moh.gov.bz/mch/emtct/internal/business/data/admissions"
+	"moh.gov.bz/mch/emtct/internal/business/data/audit"
+	"moh.gov.bz/mch/emtct/internal/business/data/contactTracing"
+	"moh.gov.bz/mch/emtct/internal/business/data/contraceptives"
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/business/data/hiv"
+	"moh.gov.bz/mch/emtct/internal/business/data/hivScreenings"
+	"moh.gov.bz/mch/emtct/internal/business/data/homeVisits"
+	"moh.gov.bz/mch/emtct/internal/business/data/infant"
+	"moh.gov.bz/mch/emtct/internal/business/data/labs"
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/business/data/partners"
+	"moh.gov.bz/mch/emtct/internal/business/data/patient"
+	"moh.gov.bz/mch/emtct/internal/business/data/person"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/cache"
+)
+
+const (
+	testUser  = "nurse@example.com"
+	testAdmin = "admin@example.com"
+)
+
+// guidelines are the hiv screening rules that the migration adds.
+var guidelines = []screeningRules.Rule{
+	{Id: 1, TestName: "PCR 1", DueDays: 3},
+	{Id: 2, TestName: "PCR 2", DueDays: 41},
+	{Id: 3, TestName: "PCR 3", DueDays: 90},
+	{Id: 4, TestName: "ELISA", DueMonths: 18},
+}
+
+func fakeStores() Stores {
+	return Stores{
+		Pregnancies:            pregnancy.NewFake(),
+		Labs:                   labs.NewFake(),
+		Infants:                infant.NewFake(),
+		Patients:               patient.NewFake(patient.Patient{Id: "100", FirstName: "Maria", LastName: "Cal"}),
+		Hiv:                    hiv.NewFake(),
+		HivScreenings:          hivScreenings.NewFake(),
+		SyphilisScreeningRules: screeningRules.NewFake(),
+		HomeVisits:             homeVisits.NewFake(),
+		Admissions:             admissions.NewFake(),
+		Contraceptives:         contraceptives.NewFake(),
+		ContactTracings:        contactTracing.NewFake(),
+		Partners:               partners.NewFake(),
+		Runs:                   etlRuns.NewFake(),
+		Audit:                  audit.NewFake(),
+	}
+}
+
+func serve(t *testing.T, s Stores, method, url, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveAs(t, testUser, s, method, url, body)
+}
+
+// serveAs serves the request as user, in place of VerifyToken. Only testAdmin can use the
+// admin endpoints.
+func serveAs(t *testing.T, user string, s Stores, method, url, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveRequest(t, user, s, httptest.NewRequest(method, url, strings.NewReader(body)))
+}
+
+// serveRequest serves req as user, for requests that need headers.
+func serveRequest(t *testing.T, user string, s Stores, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	withUser := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), "user", app.JwtToken{Email: user})
+			next(w, r.WithContext(ctx))
+		}
+	}
+	w := httptest.NewRecorder()
+	authMid := NewChain(withUser)
+	NewRouter(s, authMid, authMid.Append(RequireAdmin([]string{testAdmin}))).ServeHTTP(w, req)
+	return w
+}
+
+func TestAdmissionsHandlers(t *testing.T) {
+	s := fakeStores()
+	w := serve(t, s, http.MethodPost, "/api/hospitalAdmissions",
+		`{"patientId": 100, "dateAdmitted": "2020-06-01T00:00:00Z", "facility": "KHMH", "reason": "Fever"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	var created admissions.HospitalAdmission
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("error decoding the created admission: %v", err)
+	}
+	if created.CreatedBy != testUser {
+		t.Errorf("want: created by %s got: %s", testUser, created.CreatedBy)
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/patients/100/hospitalAdmissions", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	var resp admissionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding the admissions: %v", err)
+	}
+	if len(resp.HospitalAdmissions) != 1 || resp.HospitalAdmissions[0].Id != created.Id {
+		t.Errorf("want: the created admission got: %+v", resp.HospitalAdmissions)
+	}
+	if resp.Patient.FirstName != "Maria" {
+		t.Errorf("want: patient Maria got: %+v", resp.Patient)
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/patients/abc/hospitalAdmissions", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want: status 400 for a patient id that is not a number got: %d", w.Code)
+	}
+}
+
+// slowAdmissions is an admissions store whose queries time out.
+type slowAdmissions struct {
+	*admissions.Fake
+}
+
+func (slowAdmissions) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]admissions.HospitalAdmission, paging.Info, error) {
+	return nil, paging.Info{}, fmt.Errorf("error retrieving hospital admissions: %w", &pq.Error{Code: queryCanceled})
+}
+
+func TestQueryTimeout(t *testing.T) {
+	s := fakeStores()
+	s.Admissions = slowAdmissions{admissions.NewFake()}
+	w := serve(t, s, http.MethodGet, "/api/patients/100/hospitalAdmissions", "")
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("want: status 504 for a query that timed out got: %d", w.Code)
+	}
+
+	if !isTimeout(fmt.Errorf("error retrieving patient: %w", context.DeadlineExceeded)) {
+		t.Error("want: an expired context to be a timeout")
+	}
+	if isTimeout(fmt.Errorf("error retrieving patient: %w", &pq.Error{Code: "23505"})) {
+		t.Error("want: a unique violation not to be a timeout")
+	}
+}
+
+func TestFindPregnancyLabResults(t *testing.T) {
+	lmp := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	received := lmp.AddDate(0, 2, 0)
+	tests := []struct {
+		name   string
+		synced map[int][]labs.LabResult
+		want   string
+	}{
+		{
+			name:   "synced pregnancy",
+			synced: map[int][]labs.LabResult{7: {{Id: 1, TestName: "HIV (synced)"}}},
+			want:   "HIV (synced)",
+		},
+		{
+			name: "pregnancy that was never synced",
+			want: "HIV (acsis)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fakeStores()
+			s.Pregnancies = pregnancy.NewFake(pregnancy.Pregnancy{PregnancyId: 7, PatientId: 100, Lmp: &lmp})
+			l := labs.NewFake()
+			l.LabResults[100] = []labs.LabResult{{Id: 2, TestName: "HIV (acsis)", DateOrderReceivedByLab: &received}}
+			if tt.synced != nil {
+				l.SyncedLabResults = tt.synced
+			}
+			s.Labs = l
+
+			w := serve(t, s, http.MethodGet, "/api/patients/100/currentPregnancy/labResults", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+			}
+			var resp pregnancyLabResultsResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("error decoding the lab results: %v", err)
+			}
+			if len(resp.LabResults) != 1 || resp.LabResults[0].TestName != tt.want {
+				t.Errorf("want: %s got: %+v", tt.want, resp.LabResults)
+			}
+		})
+	}
+
+	w := serve(t, fakeStores(), http.MethodGet, "/api/patients/100/currentPregnancy/labResults", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 without a current pregnancy got: %d", w.Code)
+	}
+}
+
+func TestPregnancyRoutes(t *testing.T) {
+	s := fakeStores()
+	lmp := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	earlier := lmp.AddDate(-2, 0, 0)
+	pregs := pregnancy.NewFake(
+		pregnancy.Pregnancy{PregnancyId: 7, PatientId: 100, Lmp: &lmp},
+		pregnancy.Pregnancy{PregnancyId: 5, PatientId: 100, Lmp: &earlier},
+		pregnancy.Pregnancy{PregnancyId: 8, PatientId: 300, Lmp: &lmp},
+	)
+	pregs.Vitals[5] = pregnancy.Vitals{Id: 5, Lmp: &earlier, Para: 1}
+	pregs.Vitals[7] = pregnancy.Vitals{Id: 7, Lmp: &lmp, Para: 2}
+	s.Pregnancies = pregs
+	infants := infant.NewFake()
+	five := 5
+	for _, id := range []int{200, 201} {
+		infants.Infants[id] = infant.Infant{Infant: person.Person{PatientId: id}}
+		infants.Births[id] = infant.Birth{InfantId: id, MotherId: 100, PregnancyId: &five}
+	}
+	s.Infants = infants
+	l := labs.NewFake()
+	l.SyncedLabResults = map[int][]labs.LabResult{5: {{Id: 1, TestName: "HIV (2018)"}}, 7: {{Id: 2, TestName: "HIV (2020)"}}}
+	s.Labs = l
+
+	w := serve(t, s, http.MethodGet, "/api/patients/100/pregnancies", "")
+	var list pregnanciesResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("error decoding the pregnancies: %v", err)
+	}
+	if len(list.Pregnancies) != 2 || list.Pregnancies[0].PregnancyId != 7 || list.Pregnancies[1].PregnancyId != 5 {
+		t.Errorf("want: pregnancies 7 and 5, latest first got: %+v", list.Pregnancies)
+	}
+	if g := list.Pregnancies[0].Gestation; g == nil || g.DatedBy != pregnancy.DatedByLmp || !g.Edd.Equal(lmp.AddDate(0, 0, 280)) {
+		t.Errorf("want: the gestation of pregnancy 7 dated by its lmp got: %+v", g)
+	}
+
+	for _, url := range []string{"/api/pregnancies/5/vitals", "/api/patients/100/currentPregnancy"} {
+		w = serve(t, s, http.MethodGet, url, "")
+		var resp pregnancyResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding the vitals of %s: %v", url, err)
+		}
+		want := 5
+		if strings.HasPrefix(url, "/api/patients") {
+			want = 7
+		}
+		if resp.Vitals == nil || resp.Vitals.Id != want {
+			t.Errorf("want: the vitals of pregnancy %d from %s got: %+v", want, url, resp.Vitals)
+		}
+	}
+
+	// A patient without a pregnancy still has a current pregnancy, with null vitals.
+	w = serve(t, s, http.MethodGet, "/api/patients/999/currentPregnancy", "")
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"vitals":null,"diagnoses":[]}` {
+		t.Errorf("want: status 200 with null vitals got: %d %s", w.Code, w.Body.String())
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/pregnancies/5/labResults", "")
+	var results pregnancyLabResultsResponse
+	if err := json.NewDecoder(w.Body).Decode(&results); err != nil {
+		t.Fatalf("error decoding the lab results: %v", err)
+	}
+	if len(results.LabResults) != 1 || results.LabResults[0].TestName != "HIV (2018)" {
+		t.Errorf("want: the lab results of the earlier pregnancy got: %+v", results.LabResults)
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/pregnancies/5/infants", "")
+	var born pregnancyInfantsResponse
+	if err := json.NewDecoder(w.Body).Decode(&born); err != nil {
+		t.Fatalf("error decoding the infants: %v", err)
+	}
+	if len(born.Infants) != 2 || born.Pregnancy.PregnancyId != 5 {
+		t.Errorf("want: the twins of pregnancy 5 got: %+v", born)
+	}
+
+	if w := serve(t, s, http.MethodGet, "/api/pregnancies/8/labResults", ""); w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for the lab results of a patient that does not exist got: %d", w.Code)
+	}
+	if w := serve(t, s, http.MethodGet, "/api/pregnancies/9/vitals", ""); w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for a pregnancy that does not exist got: %d", w.Code)
+	}
+	if w := serve(t, s, http.MethodGet, "/api/pregnancies/abc/arvs", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("want: status 400 for a pregnancy id that is not a number got: %d", w.Code)
+	}
+}
+
+func TestInfantPregnancyHandler(t *testing.T) {
+	s := fakeStores()
+	s.Pregnancies = pregnancy.NewFake(pregnancy.Pregnancy{PregnancyId: 7, PatientId: 100})
+	infants := infant.NewFake()
+	dob := time.Date(2020, 9, 30, 0, 0, 0, 0, time.UTC)
+	infants.Infants[200] = infant.Infant{Infant: person.Person{PatientId: 200, Dob: &dob}}
+	s.Infants = infants
+
+	for _, url := range []string{"/api/infants/diagnoses/201", "/api/infants/201/syphilisTreatments"} {
+		if w := serve(t, s, http.MethodGet, url, ""); w.Code != http.StatusNotFound {
+			t.Errorf("want: status 404 for %s of an infant that does not exist got: %d", url, w.Code)
+		}
+	}
+	w := serve(t, s, http.MethodPut, "/api/infants/201/pregnancy", `{"pregnancyId": 7}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for an infant that does not exist got: %d", w.Code)
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/infants/200/pregnancy", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for an infant that is not linked got: %d", w.Code)
+	}
+
+	w = serve(t, s, http.MethodPut, "/api/infants/200/pregnancy", `{"pregnancyId": 8}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want: status 400 for a pregnancy that does not exist got: %d", w.Code)
+	}
+
+	w = serve(t, s, http.MethodPut, "/api/infants/200/pregnancy", `{"pregnancyId": 7}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	w = serve(t, s, http.MethodGet, "/api/infants/200/pregnancy", "")
+	var birth infant.Birth
+	if err := json.NewDecoder(w.Body).Decode(&birth); err != nil {
+		t.Fatalf("error decoding the birth: %v", err)
+	}
+	if birth.PregnancyId == nil || *birth.PregnancyId != 7 || birth.MotherId != 100 || !birth.LinkedManually {
+		t.Errorf("want: infant linked by hand to pregnancy 7 of mother 100 got: %+v", birth)
+	}
+	if birth.LinkedBy == nil || *birth.LinkedBy != testUser {
+		t.Errorf("want: linked by %s got: %v", testUser, birth.LinkedBy)
+	}
+}
+
+func TestHivTestingSchedule(t *testing.T) {
+	s := fakeStores()
+	infants := infant.NewFake()
+	dob := time.Now().AddDate(0, 0, -60)
+	infants.Infants[200] = infant.Infant{Infant: person.Person{PatientId: 200, Dob: &dob}}
+	infants.Infants[201] = infant.Infant{Infant: person.Person{PatientId: 201}}
+	s.Infants = infants
+	taken := dob.AddDate(0, 0, 1)
+	screenings := hivScreenings.NewFake(hivScreenings.HivScreening{Id: "a", PatientId: 200, TestName: "PCR 1", DateSampleTaken: &taken})
+	screenings.SetRules(guidelines...)
+	s.HivScreenings = screenings
+
+	if w := serve(t, s, http.MethodGet, "/api/infants/201/hivTestingSchedule", ""); w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for an infant without a birth date got: %d", w.Code)
+	}
+	w := serve(t, s, http.MethodGet, "/api/infants/200/hivTestingSchedule", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	var response hivTestingScheduleResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("error decoding the schedule: %v", err)
+	}
+	var statuses []string
+	for _, st := range response.Schedule {
+		statuses = append(statuses, st.Status)
+	}
+	want := []string{hivScreenings.StatusDoneOnTime, hivScreenings.StatusOverdue, hivScreenings.StatusPending, hivScreenings.StatusPending}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("want: %v got: %v", want, statuses)
+	}
+	if response.Schedule[0].Screening == nil || response.Schedule[0].Screening.Id != "a" {
+		t.Errorf("want: PCR 1 matched to screening a got: %+v", response.Schedule[0].Screening)
+	}
+}
+
+func TestEditHivScreening(t *testing.T) {
+	s := fakeStores()
+	infants := infant.NewFake()
+	dob := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	infants.Infants[200] = infant.Infant{Infant: person.Person{PatientId: 200, Dob: &dob}}
+	s.Infants = infants
+	taken, due := dob.AddDate(0, 0, 2), dob.AddDate(0, 0, 3)
+	screenings := hivScreenings.NewFake(hivScreenings.HivScreening{Id: "a", PatientId: 200, TestName: "PCR 1", DateSampleTaken: &taken, DueDate: &due, Version: 1})
+	screenings.SetRules(guidelines...)
+	s.HivScreenings = screenings
+
+	// The screening was a PCR 2, so it is due on the day of the PCR 2, whatever the body says.
+	body := `{"id": "a", "patientId": 200, "testName": "PCR 2", "dateSampleTaken": "2020-04-05T00:00:00Z", "dueDate": "2020-03-04T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/patients/100/infant/200/hivScreenings", strings.NewReader(body))
+	req.Header.Set("If-Match", `"1"`)
+	w := serveRequest(t, testUser, s, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	var saved hivScreenings.HivScreening
+	if err := json.NewDecoder(w.Body).Decode(&saved); err != nil {
+		t.Fatalf("error decoding the screening: %v", err)
+	}
+	if want := dob.AddDate(0, 0, 41); saved.DueDate == nil || !saved.DueDate.Equal(want) || !saved.Timely {
+		t.Errorf("want: a timely PCR 2 due %s got: %+v", want, saved)
+	}
+	if stored, _ := screenings.FindById(context.Background(), "a"); stored.DueDate == nil || !stored.DueDate.Equal(*saved.DueDate) {
+		t.Errorf("want: the due date of the PCR 2 saved got: %+v", stored)
+	}
+
+	infants.Infants[201] = infant.Infant{Infant: person.Person{PatientId: 201, Dob: &dob}}
+	for _, tt := range []struct {
+		url, body string
+		want      int
+	}{
+		{"/api/patients/100/infant/201/hivScreenings", `{"id": "a", "patientId": 201, "testName": "PCR 2", "dateSampleTaken": "2020-04-05T00:00:00Z"}`, http.StatusNotFound},
+		{"/api/patients/100/infant/200/hivScreenings", `{"id": "a", "patientId": 201, "testName": "PCR 2", "dateSampleTaken": "2020-04-05T00:00:00Z"}`, http.StatusBadRequest},
+		{"/api/patients/100/infant/200/hivScreenings", `{"id": "a", "patientId": 200, "testName": "PCR 2"}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPut, tt.url, strings.NewReader(tt.body))
+		req.Header.Set("If-Match", `"2"`)
+		if w := serveRequest(t, testUser, s, req); w.Code != tt.want {
+			t.Errorf("want: status %d for %s %s got: %d", tt.want, tt.url, tt.body, w.Code)
+		}
+	}
+}
+
+func TestHivScreeningRules(t *testing.T) {
+	s := fakeStores()
+	screenings := hivScreenings.NewFake()
+	screenings.SetRules(guidelines...)
+	s.HivScreenings = screenings
+	infants := infant.NewFake()
+	dob := time.Now().AddDate(0, 0, -35)
+	infants.Infants[200] = infant.Infant{Infant: person.Person{PatientId: 200, Dob: &dob}}
+	s.Infants = infants
+
+	rule := `{"testName": "PCR 2", "effectiveFrom": "2021-01-01T00:00:00Z", "dueDays": 28}`
+	if w := serve(t, s, http.MethodPost, "/api/admin/hivScreeningRules", rule); w.Code != http.StatusForbidden {
+		t.Errorf("want: status 403 for a user that is not an admin got: %d", w.Code)
+	}
+	if w := serveAs(t, testAdmin, s, http.MethodPost, "/api/admin/hivScreeningRules", `{"testName": "PCR 2"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("want: status 400 for a rule without dates got: %d", w.Code)
+	}
+	w := serveAs(t, testAdmin, s, http.MethodPost, "/api/admin/hivScreeningRules", rule)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want: status 201 got: %d (%s)", w.Code, w.Body)
+	}
+	var created screeningRules.Rule
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("error decoding the rule: %v", err)
+	}
+	if created.Id == 0 || created.CreatedBy != testAdmin {
+		t.Errorf("want: a rule created by %s got: %+v", testAdmin, created)
+	}
+	if w := serveAs(t, testAdmin, s, http.MethodPost, "/api/admin/hivScreeningRules", rule); w.Code != http.StatusConflict {
+		t.Errorf("want: status 409 for a second rule on the same day got: %d", w.Code)
+	}
+
+	// The infant was born after the new rule, so PCR 2 was due a week ago.
+	w = serve(t, s, http.MethodGet, "/api/infants/200/hivTestingSchedule", "")
+	var response hivTestingScheduleResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("error decoding the schedule: %v", err)
+	}
+	if len(response.Schedule) != 4 || response.Schedule[1].Status != hivScreenings.StatusOverdue {
+		t.Errorf("want: PCR 2 overdue by the new rule got: %+v", response.Schedule)
+	}
+
+	url := fmt.Sprintf("/api/admin/hivScreeningRules/%d", created.Id)
+	if w := serveAs(t, testAdmin, s, http.MethodDelete, url, ""); w.Code != http.StatusNoContent {
+		t.Errorf("want: status 204 got: %d", w.Code)
+	}
+	if w := serveAs(t, testAdmin, s, http.MethodDelete, url, ""); w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for a deleted rule got: %d", w.Code)
+	}
+	w = serveAs(t, testAdmin, s, http.MethodGet, "/api/admin/hivScreeningRules", "")
+	var rules []screeningRules.Rule
+	if err := json.NewDecoder(w.Body).Decode(&rules); err != nil || len(rules) != len(guidelines) {
+		t.Errorf("want: %d rules got: %+v, %v", len(guidelines), rules, err)
+	}
+}
+
+func TestInfantSyphilisScreenings(t *testing.T) {
+	s := fakeStores()
+	infants := infant.NewFake()
+	dob := time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)
+	infants.Infants[200] = infant.Infant{Infant: person.Person{PatientId: 200, Dob: &dob}}
+	infants.Infants[201] = infant.Infant{Infant: person.Person{PatientId: 201}}
+	s.Infants = infants
+	l := labs.NewFake()
+	l.SyphilisScreenings[200] = []labs.SyphilisScreening{{Id: 211, TestName: "RPR", Result: "Reactive", Titre: "1:4", Timely: labs.Timely}}
+	s.Labs = l
+
+	if w := serve(t, s, http.MethodGet, "/api/infants/201/syphilisScreenings", ""); w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for an infant without a birth date got: %d", w.Code)
+	}
+	w := serve(t, s, http.MethodGet, "/api/infants/200/syphilisScreenings", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	var response infantSyphilisScreeningResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("error decoding the screenings: %v", err)
+	}
+	if len(response.Screenings) != 1 || response.Screenings[0].Titre != "1:4" {
+		t.Errorf("want: the RPR with a titre of 1:4 got: %+v", response.Screenings)
+	}
+
+	// The syphilis schedule has its own rules.
+	rule := `{"testName": "Birth", "effectiveFrom": "1900-01-01T00:00:00Z", "dueDays": 3}`
+	if w := serveAs(t, testAdmin, s, http.MethodPost, "/api/admin/syphilisScreeningRules", rule); w.Code != http.StatusCreated {
+		t.Fatalf("want: status 201 got: %d (%s)", w.Code, w.Body)
+	}
+	for url, want := range map[string]int{"/api/admin/syphilisScreeningRules": 1, "/api/admin/hivScreeningRules": 0} {
+		w = serveAs(t, testAdmin, s, http.MethodGet, url, "")
+		var rules []screeningRules.Rule
+		if err := json.NewDecoder(w.Body).Decode(&rules); err != nil || len(rules) != want {
+			t.Errorf("want: %d rules from %s got: %+v, %v", want, url, rules, err)
+		}
+	}
+	if w := serveAs(t, testAdmin, s, http.MethodDelete, "/api/admin/syphilisScreeningRules/1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("want: status 204 got: %d", w.Code)
+	}
+}
+
+func TestEtlRunHandler(t *testing.T) {
+	s := fakeStores()
+	runs := etlRuns.NewFake(etlRuns.Run{Id: "run-1", Name: "pregnancies", StartedAt: time.Now()})
+	if err := runs.SaveErrors(context.Background(), "run-1", []etlRuns.RecordError{{RecordId: 5, Error: "pregnancy has no patient"}}); err != nil {
+		t.Fatal(err)
+	}
+	s.Runs = runs
+
+	w := serve(t, s, http.MethodGet, "/api/etl/runs/run-2", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for a run that does not exist got: %d", w.Code)
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/etl/runs/run-1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	var resp etlRunResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding the etl run: %v", err)
+	}
+	if resp.Run.Id != "run-1" || len(resp.Errors) != 1 || resp.Errors[0].RecordId != 5 {
+		t.Errorf("want: run-1 with the error of record 5 got: %+v", resp)
+	}
+}
+
+// countingPatients counts the lookups that reach the patient store.
+type countingPatients struct {
+	*patient.Fake
+	lookups int
+}
+
+func (c *countingPatients) FindBasicInfo(ctx context.Context, patientId int) (*patient.BasicInfo, error) {
+	c.lookups++
+	return c.Fake.FindBasicInfo(ctx, patientId)
+}
+
+func TestPatientCache(t *testing.T) {
+	s := fakeStores()
+	acsis := &countingPatients{Fake: patient.NewFake(patient.Patient{Id: "100", FirstName: "Maria", LastName: "Cal"})}
+	s.Caches.Patients = cache.New(time.Hour)
+	s.Patients = patient.NewCached(acsis, s.Caches.Patients)
+
+	for i := 0; i < 2; i++ {
+		w := serve(t, s, http.MethodGet, "/api/patients/100/hospitalAdmissions", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+		}
+	}
+	if acsis.lookups != 1 {
+		t.Errorf("want: 1 lookup of the patient got: %d", acsis.lookups)
+	}
+
+	w := serve(t, s, http.MethodDelete, "/api/admin/cache/patients/100", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("want: status 403 for a user that is not an admin got: %d", w.Code)
+	}
+	w = serveAs(t, testAdmin, s, http.MethodDelete, "/api/admin/cache/patients/100", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("want: status 204 got: %d (%s)", w.Code, w.Body)
+	}
+	serve(t, s, http.MethodGet, "/api/patients/100/hospitalAdmissions", "")
+	if acsis.lookups != 2 {
+		t.Errorf("want: the patient to be looked up again after the invalidation got: %d lookups", acsis.lookups)
+	}
+
+	w = serveAs(t, testAdmin, s, http.MethodGet, "/api/admin/cache", "")
+	var stats cacheStatsResponse
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("error decoding the cache stats: %v", err)
+	}
+	if stats.Patients == nil || stats.Patients.Hits != 1 || stats.Patients.Misses != 2 {
+		t.Errorf("want: 1 hit and 2 misses got: %+v", stats.Patients)
+	}
+	if stats.Infants != nil {
+		t.Errorf("want: no stats for the disabled infant cache got: %+v", stats.Infants)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	s := fakeStores()
+	s.Admissions = admissions.NewFake(admissions.HospitalAdmission{Id: "a1", PatientId: 100, Facility: "KHMH"})
+	countAdmissions := func() int {
+		w := serve(t, s, http.MethodGet, "/api/patients/100/hospitalAdmissions", "")
+		var resp admissionsResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding the admissions: %v", err)
+		}
+		return len(resp.HospitalAdmissions)
+	}
+
+	w := serve(t, s, http.MethodDelete, "/api/hospitalAdmissions/a1", `{}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want: status 400 without a reason got: %d", w.Code)
+	}
+	w = serve(t, s, http.MethodDelete, "/api/hospitalAdmissions/a1", `{"reason": "wrong patient"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("want: status 204 got: %d (%s)", w.Code, w.Body)
+	}
+	if n := countAdmissions(); n != 0 {
+		t.Errorf("want: the deleted admission to be hidden got: %d admissions", n)
+	}
+	w = serve(t, s, http.MethodDelete, "/api/hospitalAdmissions/a1", `{"reason": "wrong patient"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for an admission that is already deleted got: %d", w.Code)
+	}
+
+	w = serve(t, s, http.MethodPost, "/api/admin/hospitalAdmissions/a1/restore", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("want: status 403 for a user that is not an admin got: %d", w.Code)
+	}
+	w = serveAs(t, testAdmin, s, http.MethodPost, "/api/admin/hospitalAdmissions/a1/restore", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("want: status 204 got: %d (%s)", w.Code, w.Body)
+	}
+	if n := countAdmissions(); n != 1 {
+		t.Errorf("want: the restored admission got: %d admissions", n)
+	}
+	w = serveAs(t, testAdmin, s, http.MethodPost, "/api/admin/patients/100/restore", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for records that can not be restored got: %d", w.Code)
+	}
+}
+
+func TestDeleteHivScreening(t *testing.T) {
+	s := fakeStores()
+	s.HivScreenings = hivScreenings.NewFake(hivScreenings.HivScreening{Id: "s1", PatientId: 200, MotherId: 100})
+
+	w := serve(t, s, http.MethodDelete, "/api/patients/100/infant/201/hivScreenings/s1", `{"reason": "wrong infant"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want: status 404 for the screening of another infant got: %d", w.Code)
+	}
+	w = serve(t, s, http.MethodDelete, "/api/patients/100/infant/200/hivScreenings/s1", `{"reason": "wrong infant"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("want: status 204 got: %d (%s)", w.Code, w.Body)
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	s := fakeStores()
+	s.Audit = audit.NewFake(
+		audit.Entry{Id: 1, EntityType: "hiv_screening", EntityId: "s1", Action: "create", User: "nurse@example.com",
+			Before: json.RawMessage(`null`), After: json.RawMessage(`{"result": "Negative"}`)},
+		audit.Entry{Id: 2, EntityType: "hiv_screening", EntityId: "s1", Action: "update", User: "doctor@example.com",
+			Before: json.RawMessage(`{"result": "Negative"}`), After: json.RawMessage(`{"result": "Positive"}`)},
+		audit.Entry{Id: 3, EntityType: "home_visit", EntityId: "s1", Action: "create", User: "nurse@example.com"},
+	)
+
+	w := serve(t, s, http.MethodGet, "/api/patients/100/infant/200/hivScreenings/s1/history", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	var resp historyResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding the history: %v", err)
+	}
+	if len(resp.History) != 2 || resp.History[1].User != "doctor@example.com" {
+		t.Errorf("want: the 2 writes to the screening got: %+v", resp.History)
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/homeVisits/v2/history", "")
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"history":[]}` {
+		t.Errorf("want: an empty history got: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestEditWithStaleVersion(t *testing.T) {
+	s := fakeStores()
+	s.HomeVisits = homeVisits.NewFake(homeVisits.HomeVisit{Id: "v1", PatientId: 100, Reason: "Follow up", Version: 1})
+	edit := func(etag, comments string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/homeVisits",
+			strings.NewReader(`{"id": "v1", "reason": "Follow up", "comments": "`+comments+`"}`))
+		if len(etag) > 0 {
+			req.Header.Set("If-Match", etag)
+		}
+		return serveRequest(t, testUser, s, req)
+	}
+
+	if w := edit("", "Taking her ARVs"); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("want: status 428 without If-Match got: %d", w.Code)
+	}
+	w := edit(`"1"`, "Taking her ARVs")
+	if w.Code != http.StatusOK {
+		t.Fatalf("want: status 200 got: %d (%s)", w.Code, w.Body)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("want: ETag \"2\" got: %s", etag)
+	}
+
+	// A second nurse still has version 1.
+	w = edit(`"1"`, "Missed her appointment")
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("want: status 412 got: %d (%s)", w.Code, w.Body)
+	}
+	var current homeVisits.HomeVisit
+	if err := json.NewDecoder(w.Body).Decode(&current); err != nil {
+		t.Fatalf("error decoding the current home visit: %v", err)
+	}
+	if current.Comments != "Taking her ARVs" || current.Version != 2 || w.Header().Get("ETag") != `"2"` {
+		t.Errorf("want: the home visit saved by the first nurse got: %+v", current)
+	}
+
+	w = serve(t, s, http.MethodGet, "/api/homeVisits/v1", "")
+	if etag := w.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("want: ETag \"2\" got: %s", etag)
+	}
+	if w := edit(`"2"`, "Missed her appointment"); w.Code != http.StatusOK {
+		t.Errorf("want: status 200 with the current version got: %d", w.Code)
+	}
+}
+
+func TestPaging(t *testing.T) {
+	s := fakeStores()
+	day := func(d int) time.Time { return time.Date(2021, time.March, d, 0, 0, 0, 0, time.UTC) }
+	s.Admissions = admissions.NewFake(
+		admissions.HospitalAdmission{Id: "a1", PatientId: 100, DateAdmitted: day(1)},
+		admissions.HospitalAdmission{Id: "a2", PatientId: 100, DateAdmitted: day(2)},
+		admissions.HospitalAdmission{Id: "a3", PatientId: 100, DateAdmitted: day(3)},
+	)
+	list := func(query string) admissionsResponse {
+		t.Helper()
+		w := serve(t, s, http.MethodGet, "/api/patients/100/hospitalAdmissions"+query, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("want: status 200 for %q got: %d (%s)", query, w.Code, w.Body)
+		}
+		var resp admissionsResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding the admissions: %v", err)
+		}
+		return resp
+	}
+	ids := func(resp admissionsResponse) string {
+		var ids []string
+		for _, a := range resp.HospitalAdmissions {
+			ids = append(ids, a.Id)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	first := list("?limit=2")
+	if got := ids(first); got != "a3,a2" || first.Page.Total != 3 || first.Page.NextCursor == nil {
+		t.Fatalf("want: the 2 newest of 3 admissions and a cursor got: %s, %+v", got, first.Page)
+	}
+	next := list("?limit=2&cursor=" + *first.Page.NextCursor)
+	if got := ids(next); got != "a1" || next.Page.NextCursor != nil {
+		t.Errorf("want: the last admission and no cursor got: %s, %+v", got, next.Page)
+	}
+	if got := ids(list("?sort=date")); got != "a1,a2,a3" {
+		t.Errorf("want: the oldest admission first got: %s", got)
+	}
+	filtered := list("?from=2021-03-02&to=2021-03-02")
+	if got := ids(filtered); got != "a2" || filtered.Page.Total != 1 {
+		t.Errorf("want: the admission of 2 March got: %s, %+v", got, filtered.Page)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=501", "?cursor=nonsense", "?sort=name", "?from=March"} {
+		w := serve(t, s, http.MethodGet, "/api/patients/100/hospitalAdmissions"+query, "")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("want: status 400 for %q got: %d", query, w.Code)
+		}
+	}
+
+	// The ids of the infant diagnoses are numbers, so a cursor of another list is rejected.
+	foreign := paging.Cursor{Date: time.Now(), Id: "a1"}.String()
+	if w := serve(t, s, http.MethodGet, "/api/infants/diagnoses/200?cursor="+foreign, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("want: status 400 for a cursor with an id that is not a number got: %d", w.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/audit"
+)
+
+type HistoryRoutes struct {
+	Audit audit.Store
+}
+
+type historyResponse struct {
+	History []audit.Entry `json:"history"`
+}
+
+// HistoryHandler returns the handler that lists every write to a record of table, oldest
+// first. The id of the record is in the idVar of the route.
+func (h HistoryRoutes) HistoryHandler(table, idVar string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodOptions:
+			return
+		case http.MethodGet:
+			id := mux.Vars(r)[idVar]
+			entries, err := h.Audit.History(r.Context(), table, id)
+			if err != nil {
+				log.WithFields(log.Fields{"entityType": table, "id": id}).
+					WithError(err).
+					Error("error retrieving the history of the record")
+				internalError(w, err)
+				return
+			}
+			if entries == nil {
+				entries = []audit.Entry{}
+			}
+			w.Header().Add("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(historyResponse{History: entries}); err != nil {
+				log.WithFields(log.Fields{"entityType": table, "id": id}).
+					WithError(err).
+					Error("error marshalling the history of the record")
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -13,17 +15,20 @@ import (
 
 	"moh.gov.bz/mch/emtct/internal/app"
 	"moh.gov.bz/mch/emtct/internal/business/data/homeVisits"
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
 	"moh.gov.bz/mch/emtct/internal/business/data/patient"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type HomeVisitRoutes struct {
-	HomeVisits homeVisits.HomeVisits
-	Patients   patient.Patients
+	HomeVisits homeVisits.Store
+	Patients   patient.Store
 }
 
 type homeVisitResponse struct {
 	HomeVisits []homeVisits.HomeVisit `json:"homeVisits"`
 	Patient    patient.BasicInfo      `json:"patient"`
+	Page       paging.Info            `json:"page"`
 }
 
 func (h HomeVisitRoutes) FindByPatientHandler(w http.ResponseWriter, r *http.Request) {
@@ -41,26 +46,31 @@ func (h HomeVisitRoutes) FindByPatientHandler(w http.ResponseWriter, r *http.Req
 			http.Error(w, "the patient id must be a valid number", http.StatusBadRequest)
 			return
 		}
-		homeVisits, err := h.HomeVisits.FindByPatientId(patientId)
+		q, ok := pageQuery(w, r)
+		if !ok {
+			return
+		}
+		homeVisits, page, err := h.HomeVisits.FindByPatientId(r.Context(), patientId, q)
 		if err != nil {
 			log.WithFields(log.Fields{"patientId": patientId}).
 				WithError(err).
 				Error("database error while retrieving home visits")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		patient, err := h.Patients.FindBasicInfo(patientId)
+		patient, err := h.Patients.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": patient,
 				"handler":   "FindHomeVisitsByPatient",
 			}).WithError(err).Error("error retrieving patient information")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		response := homeVisitResponse{
 			HomeVisits: homeVisits,
 			Patient:    *patient,
+			Page:       page,
 		}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -97,14 +107,17 @@ func (h HomeVisitRoutes) HomeVisitsHandler(w http.ResponseWriter, r *http.Reques
 	case http.MethodGet:
 		vars := mux.Vars(r)
 		id := vars["homeVisitId"]
-		visit, err := h.HomeVisits.FindById(id)
+		visit, err := h.HomeVisits.FindById(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{"homeVisitId": id}).
 				WithError(err).
 				Error("error retrieving home visit from the database")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		if visit != nil {
+			setETag(w, visit.Version)
+		}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(visit); err != nil {
 			log.WithFields(log.Fields{"homeVisit": visit, "homeVisitId": id}).
@@ -113,9 +126,15 @@ func (h HomeVisitRoutes) HomeVisitsHandler(w http.ResponseWriter, r *http.Reques
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
+	case http.MethodDelete:
+		softDelete(w, r, "homeVisit", "homeVisitId", h.HomeVisits.Delete)
 	case http.MethodPut:
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
+		version, ok := ifMatch(w, r)
+		if !ok {
+			return
+		}
 		var req homeVisitRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.WithFields(log.Fields{
@@ -126,15 +145,32 @@ func (h HomeVisitRoutes) HomeVisitsHandler(w http.ResponseWriter, r *http.Reques
 			http.Error(w, "could not decode your request", http.StatusInternalServerError)
 			return
 		}
-		visit, err := h.editHomeVisit(user, req)
+		visit, err := h.editHomeVisit(r.Context(), user, version, req)
+		if errors.Is(err, db.ErrVersionConflict) {
+			current, err := h.HomeVisits.FindById(r.Context(), req.ID)
+			if err != nil {
+				log.WithFields(log.Fields{"user": user, "homeVisitId": req.ID}).
+					WithError(err).
+					Error("error retrieving the current home visit")
+				internalError(w, err)
+				return
+			}
+			if current == nil {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			preconditionFailed(w, current, current.Version)
+			return
+		}
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"homeVisit": req,
 			}).WithError(err).Error("error editing home visit")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, visit.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(visit); err != nil {
 			log.WithFields(log.Fields{
@@ -157,14 +193,15 @@ func (h HomeVisitRoutes) HomeVisitsHandler(w http.ResponseWriter, r *http.Reques
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		visit, err := h.createHomeVisit(user, req)
+		visit, err := h.createHomeVisit(r.Context(), user, req)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"request": req,
 			}).WithError(err).Error("error creating home visit")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, visit.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(visit); err != nil {
 			log.WithFields(log.Fields{
@@ -177,13 +214,18 @@ func (h HomeVisitRoutes) HomeVisitsHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
-func (h HomeVisitRoutes) editHomeVisit(user string, r homeVisitRequest) (*homeVisits.HomeVisit, error) {
+// editHomeVisit saves the changes of user to version of a home visit. It returns
+// db.ErrVersionConflict when the home visit is at another version or does not exist.
+func (h HomeVisitRoutes) editHomeVisit(ctx context.Context, user string, version int, r homeVisitRequest) (*homeVisits.HomeVisit, error) {
 
-	v, err := h.HomeVisits.FindById(r.ID)
+	v, err := h.HomeVisits.FindById(ctx, r.ID)
 	if err != nil {
-		return nil, fmt.Errorf("home visit with given id does not exist: %+v", err)
+		return nil, fmt.Errorf("home visit with given id does not exist: %w", err)
+	}
+	if v == nil {
+		return nil, db.ErrVersionConflict
 	}
-	modified, err := h.HomeVisits.Edit(homeVisits.HomeVisit{
+	modified, err := h.HomeVisits.Edit(ctx, homeVisits.HomeVisit{
 		Id:             v.Id,
 		PatientId:      v.PatientId,
 		MchEncounterId: v.MchEncounterId,
@@ -194,11 +236,12 @@ func (h HomeVisitRoutes) editHomeVisit(user string, r homeVisitRequest) (*homeVi
 		UpdatedAt:      v.UpdatedAt,
 		CreatedBy:      v.CreatedBy,
 		UpdatedBy:      &user,
+		Version:        version,
 	})
 	return modified, err
 }
 
-func (h HomeVisitRoutes) createHomeVisit(user string, r newHomeVisitRequest) (*homeVisits.HomeVisit, error) {
+func (h HomeVisitRoutes) createHomeVisit(ctx context.Context, user string, r newHomeVisitRequest) (*homeVisits.HomeVisit, error) {
 	id := uuid.New().String()
 	if len(user) == 0 {
 		return nil, fmt.Errorf("user did not provide an email")
@@ -215,11 +258,12 @@ func (h HomeVisitRoutes) createHomeVisit(user string, r newHomeVisitRequest) (*h
 		UpdatedAt:      nil,
 		CreatedBy:      user,
 		UpdatedBy:      nil,
+		Version:        1,
 	}
 
-	err := h.HomeVisits.Create(visit)
+	err := h.HomeVisits.Create(ctx, visit)
 	if err != nil {
-		return nil, fmt.Errorf("error creating home visit: %+v", err)
+		return nil, fmt.Errorf("error creating home visit: %w", err)
 	}
 
 	return &visit, nil
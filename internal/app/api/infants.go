@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,16 +14,22 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"moh.gov.bz/mch/emtct/internal/app"
+	"moh.gov.bz/mch/emtct/internal/business/data/hivScreenings"
 	"moh.gov.bz/mch/emtct/internal/business/data/infant"
 	"moh.gov.bz/mch/emtct/internal/business/data/labs"
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
 	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
 	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type InfantRoutes struct {
-	Infant      infant.Infants
-	Pregnancies pregnancy.Pregnancies
-	Labs        labs.Labs
+	Infant                 infant.Store
+	HivScreenings          hivScreenings.Store
+	SyphilisScreeningRules screeningRules.Store
+	Pregnancies            pregnancy.Store
+	Labs                   labs.Store
 }
 
 func (i InfantRoutes) InfantHandlers(w http.ResponseWriter, r *http.Request) {
@@ -30,47 +38,36 @@ func (i InfantRoutes) InfantHandlers(w http.ResponseWriter, r *http.Request) {
 	case http.MethodOptions:
 		return
 	case http.MethodGet:
-		vars := mux.Vars(r)
-		motherId := vars["patientId"]
-		id, err := strconv.Atoi(motherId)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"motherId": motherId,
-			}).WithError(err).Error("motherId is not a valid number")
-			http.Error(w, "motherId is not a valid number", http.StatusBadRequest)
-			return
-		}
 		// Find current pregnancy
-		preg, err := i.Pregnancies.FindLatest(id)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"motherId": id,
-			}).WithError(err).Error("error retrieving this patient's latest pregnancy")
-			http.Error(w, "could not retrieve the mother's latest pregnancy", http.StatusInternalServerError)
+		preg := findPregnancy(w, r, i.Pregnancies)
+		if preg == nil {
 			return
 		}
+		motherId := preg.PatientId
 		log.WithFields(log.Fields{"pregnancy": preg}).Info("pregnancy for infant")
-		infant, err := i.Infant.FindPregnancyInfant(*preg)
+		infants, err := i.Infant.FindPregnancyInfants(r.Context(), *preg)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"motherId": motherId,
 			}).WithError(err).Error("error retrieving pregnancy infant")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		if infant == nil {
+		if len(infants) == 0 {
 			log.WithFields(log.Fields{
 				"motherId": motherId,
 			}).Error("no infant exists for current pregnancy")
 			http.Error(w, "no infant exists for relevant pregnancy", http.StatusNotFound)
 			return
 		}
+		// The latest born infant of the pregnancy.
+		infant := infants[0]
 		result, err := json.Marshal(infant)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"infant": infant,
 			}).WithError(err).Error("error marshalling infant data")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		w.Header().Add("Content-Type", "application/json")
@@ -78,9 +75,56 @@ func (i InfantRoutes) InfantHandlers(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type pregnancyInfantsResponse struct {
+	Pregnancy pregnancy.Pregnancy  `json:"pregnancy"`
+	Gestation *pregnancy.Gestation `json:"gestation"`
+	Infants   []infant.Infant      `json:"infants"`
+}
+
+// PregnancyInfantsHandler lists the infants of a pregnancy, the latest born first.
+func (i InfantRoutes) PregnancyInfantsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		preg := findPregnancy(w, r, i.Pregnancies)
+		if preg == nil {
+			return
+		}
+		infants, err := i.Infant.FindPregnancyInfants(r.Context(), *preg)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"pregnancy": preg,
+				"handler":   "PregnancyInfantsHandler",
+			}).WithError(err).Error("error retrieving the infants of the pregnancy")
+			internalError(w, err)
+			return
+		}
+		// Return an empty array if no results are found
+		if infants == nil {
+			infants = []infant.Infant{}
+		}
+		gestation := findGestation(w, r, i.Pregnancies, *preg)
+		if gestation == nil {
+			return
+		}
+		response := pregnancyInfantsResponse{Pregnancy: *preg, Gestation: gestation, Infants: infants}
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.WithFields(log.Fields{
+				"pregnancy": preg,
+				"handler":   "PregnancyInfantsHandler",
+			}).WithError(err).Error("error encoding the infants of the pregnancy")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
 type infantDiagnosesResponse struct {
 	Diagnoses []infant.Diagnoses `json:"diagnoses"`
 	Infant    infant.Infant      `json:"infant"`
+	Page      paging.Info        `json:"page"`
 }
 
 func (i InfantRoutes) InfantDiagnosesHandler(w http.ResponseWriter, r *http.Request) {
@@ -97,17 +141,17 @@ func (i InfantRoutes) InfantDiagnosesHandler(w http.ResponseWriter, r *http.Requ
 			http.Error(w, "infant id must be a numeric value", http.StatusBadRequest)
 			return
 		}
-		mId := vars["motherId"]
-		motherId, err := strconv.Atoi(mId)
-		if err != nil {
-			log.WithFields(log.Fields{"motherId": id}).WithError(err).
-				Error("the mother id is not a valid number")
-			http.Error(w, "mother id must be a numeric value", http.StatusBadRequest)
-			return
-		}
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
-		diagnoses, err := i.Infant.FindInfantDiagnoses(infantId)
+		q, ok := pageQuery(w, r)
+		if !ok {
+			return
+		}
+		diagnoses, page, err := i.Infant.FindInfantDiagnoses(r.Context(), infantId, q)
+		if errors.Is(err, paging.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			log.WithFields(log.Fields{
 				"infantId": infantId,
@@ -116,7 +160,7 @@ func (i InfantRoutes) InfantDiagnosesHandler(w http.ResponseWriter, r *http.Requ
 			}).
 				WithError(err).
 				Error("error while fetching infant diagnoses")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		// Return an empty array if no results are found
@@ -124,40 +168,35 @@ func (i InfantRoutes) InfantDiagnosesHandler(w http.ResponseWriter, r *http.Requ
 			diagnoses = []infant.Diagnoses{}
 		}
 
-		// Find current pregnancy
-		preg, err := i.Pregnancies.FindLatest(motherId)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"motherId": id,
-			}).WithError(err).Error("error retrieving this patient's latest pregnancy")
-			http.Error(w, "could not retrieve the mother's latest pregnancy", http.StatusInternalServerError)
-			return
-		}
-		infantInfo, err := i.Infant.FindPregnancyInfant(*preg)
+		infantInfo, err := i.Infant.FindInfant(r.Context(), infantId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"infantId": infantId,
-				"motherId": motherId,
 				"user":     user,
 			}).WithError(err).Error("could not find infant info")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
+			return
+		}
+		if infantInfo == nil {
+			http.Error(w, fmt.Sprintf("infant with id %d does not exist", infantId), http.StatusNotFound)
 			return
 		}
 
 		response := infantDiagnosesResponse{
 			Diagnoses: diagnoses,
 			Infant:    *infantInfo,
+			Page:      page,
 		}
 		result, err := json.Marshal(response)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"motherId": id,
+				"infantId": id,
 				"user":     user,
 				"response": response,
 			}).
 				WithError(err).
 				Error("error while marshalling the infant diagnoses")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		w.Header().Add("Content-Type", "application/json")
@@ -182,8 +221,8 @@ type newHivScreeningRequest struct {
 }
 
 type hivScreeningsResponse struct {
-	HivScreenings []infant.HivScreening `json:"hivScreening"`
-	Infant        infant.Infant         `json:"patient"`
+	HivScreenings []hivScreenings.HivScreening `json:"hivScreening"`
+	Infant        infant.Infant                `json:"patient"`
 }
 
 type infantTreatmentResponse struct {
@@ -191,10 +230,10 @@ type infantTreatmentResponse struct {
 	Infant        infant.Infant               `json:"infant"`
 }
 
-func (i InfantRoutes) CreateHivScreening(user string, r newHivScreeningRequest, timely bool, dueDate time.Time) (*infant.HivScreening, error) {
+func (i InfantRoutes) CreateHivScreening(ctx context.Context, user string, r newHivScreeningRequest, timely bool, dueDate *time.Time) (*hivScreenings.HivScreening, error) {
 	id := uuid.New().String()
 
-	s := infant.HivScreening{
+	s := hivScreenings.HivScreening{
 		Id:                     id,
 		PatientId:              r.PatientId,
 		TestName:               r.TestName,
@@ -208,15 +247,16 @@ func (i InfantRoutes) CreateHivScreening(user string, r newHivScreeningRequest,
 		DateResultShared:       r.DateResultShared,
 		DateSampleTaken:        &r.DateSampleTaken,
 		MotherId:               r.MotherId,
-		DueDate:                &dueDate,
+		DueDate:                dueDate,
 		CreatedAt:              time.Now(),
 		UpdatedAt:              nil,
 		CreatedBy:              user,
 		UpdatedBy:              nil,
 		Timely:                 timely,
+		Version:                1,
 	}
 
-	err := i.Infant.CreateHivScreening(s)
+	err := i.HivScreenings.Create(ctx, s)
 	if err != nil {
 		return nil, err
 	}
@@ -236,31 +276,46 @@ func (i InfantRoutes) HivScreeningHandler(w http.ResponseWriter, r *http.Request
 		var req newHivScreeningRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.WithError(err).Error("error parsing request body for creating an hiv screening")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		infant, err := i.Infant.FindInfant(req.PatientId)
+		infantInfo, err := i.Infant.FindInfant(r.Context(), req.PatientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":    user,
 				"request": req,
 				"handler": "CreateHivScreeningHandler",
-			}).WithError(err).Error("")
+			}).WithError(err).Error("error retrieving the infant of the hiv screening")
+			internalError(w, err)
+			return
+		}
+		if infantInfo == nil || infantInfo.Infant.Dob == nil {
 			http.Error(w, fmt.Sprintf("no birth was found for this infant id: %d", req.PatientId), http.StatusBadRequest)
 			return
 		}
-		timely := i.Infant.IsHivScreeningTimely(*infant.Infant.Dob, req.TestName, req.DateSampleTaken)
-		dueDate := i.Infant.HivScreeningDueDate(req.TestName, *infant.Infant.Dob)
-		screening, err := i.CreateHivScreening(user, req, timely, dueDate)
+		rules, err := i.HivScreenings.FindRules(r.Context())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"request": req,
+				"handler": "CreateHivScreeningHandler",
+			}).WithError(err).Error("error retrieving the hiv screening rules")
+			internalError(w, err)
+			return
+		}
+		timely := rules.IsTimely(*infantInfo.Infant.Dob, req.TestName, req.DateSampleTaken)
+		dueDate := rules.DueDate(req.TestName, *infantInfo.Infant.Dob)
+		screening, err := i.CreateHivScreening(r.Context(), user, req, timely, dueDate)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"hivScreeningRequest": req,
 				"handler":             "CreateHivScreeningHandler",
 				"user":                user,
 			}).WithError(err).Error("failed to create an hiv screening")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, screening.Version)
 		w.Header().Add("Content-Type", "application/json")
 
 		if err := json.NewEncoder(w).Encode(screening); err != nil {
@@ -273,7 +328,16 @@ func (i InfantRoutes) HivScreeningHandler(w http.ResponseWriter, r *http.Request
 			return
 		}
 	case http.MethodPut:
-		var screening infant.HivScreening
+		infantId, err := strconv.Atoi(mux.Vars(r)["infantId"])
+		if err != nil {
+			http.Error(w, "infant id must be a numeric value", http.StatusBadRequest)
+			return
+		}
+		version, ok := ifMatch(w, r)
+		if !ok {
+			return
+		}
+		var screening hivScreenings.HivScreening
 		if err := json.NewDecoder(r.Body).Decode(&screening); err != nil {
 			log.WithFields(log.Fields{
 				"request": r.Body,
@@ -282,13 +346,17 @@ func (i InfantRoutes) HivScreeningHandler(w http.ResponseWriter, r *http.Request
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		s, err := i.Infant.FindHivScreeningById(screening.Id)
+		if screening.DateSampleTaken == nil {
+			http.Error(w, "the hiv screening needs a dateSampleTaken", http.StatusBadRequest)
+			return
+		}
+		s, err := i.HivScreenings.FindById(r.Context(), screening.Id)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"screeningRequest": screening,
 				"user":             user,
 			}).WithError(err).Error("error when querying database for hiv screening")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		if s == nil {
@@ -299,29 +367,72 @@ func (i InfantRoutes) HivScreeningHandler(w http.ResponseWriter, r *http.Request
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		infant, err := i.Infant.FindInfant(screening.PatientId)
+		// Only edit the screening through the url of its own infant, and never move it to another
+		// infant, whose birth date would time it.
+		if s.PatientId != infantId {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if screening.PatientId != s.PatientId {
+			http.Error(w, "the infant of an hiv screening can not be changed", http.StatusBadRequest)
+			return
+		}
+		infantInfo, err := i.Infant.FindInfant(r.Context(), s.PatientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":    user,
 				"request": screening,
-				"handler": "CreateHivScreeningHandler",
-			}).WithError(err).Error("")
-			http.Error(w, fmt.Sprintf("no birth was found for infant Id: %s", screening.PatientId), http.StatusBadRequest)
+				"handler": "EditHivScreeningHandler",
+			}).WithError(err).Error("error retrieving the infant of the hiv screening")
+			internalError(w, err)
 			return
 		}
-		timely := i.Infant.IsHivScreeningTimely(*infant.Infant.Dob, screening.TestName, *screening.DateSampleTaken)
+		if infantInfo == nil || infantInfo.Infant.Dob == nil {
+			http.Error(w, fmt.Sprintf("no birth was found for infant Id: %d", s.PatientId), http.StatusBadRequest)
+			return
+		}
+		rules, err := i.HivScreenings.FindRules(r.Context())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"user":    user,
+				"request": screening,
+				"handler": "EditHivScreeningHandler",
+			}).WithError(err).Error("error retrieving the hiv screening rules")
+			internalError(w, err)
+			return
+		}
+		timely := rules.IsTimely(*infantInfo.Infant.Dob, screening.TestName, *screening.DateSampleTaken)
 		screening.UpdatedBy = &user
 		screening.Timely = timely
-		saved, err := i.Infant.EditHivScreening(screening)
+		screening.DueDate = rules.DueDate(screening.TestName, *infantInfo.Infant.Dob)
+		screening.Version = version
+		saved, err := i.HivScreenings.Edit(r.Context(), screening)
+		if errors.Is(err, db.ErrVersionConflict) {
+			current, err := i.HivScreenings.FindById(r.Context(), screening.Id)
+			if err != nil {
+				log.WithFields(log.Fields{"user": user, "screeningId": screening.Id}).
+					WithError(err).
+					Error("error retrieving the current hiv screening")
+				internalError(w, err)
+				return
+			}
+			if current == nil {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			preconditionFailed(w, current, current.Version)
+			return
+		}
 		if err != nil {
 			log.WithFields(log.Fields{
 				"screeningId": screening.Id,
 				"user":        user,
 				"request":     screening,
 			}).WithError(err).Error("db failure while editing an hiv screening")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, saved.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(saved); err != nil {
 			log.WithFields(log.Fields{
@@ -341,20 +452,24 @@ func (i InfantRoutes) HivScreeningHandler(w http.ResponseWriter, r *http.Request
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		screenings, err := i.Infant.FindHivScreeningsByPatient(id)
+		screenings, err := i.HivScreenings.FindByPatientId(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": patientId,
 				"handler":   "HivScreeningsByPatientIdHandler",
 			}).WithError(err).Error("error retrieving hiv screenings for patient")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		infant, err := i.Infant.FindInfant(id)
+		infant, err := i.Infant.FindInfant(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{"patientId": id, "screenings": screenings}).WithError(err).
 				Error("error retrieving patient when fetching hiv screenings")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
+			return
+		}
+		if infant == nil {
+			http.Error(w, fmt.Sprintf("infant with id %d does not exist", id), http.StatusNotFound)
 			return
 		}
 		response := hivScreeningsResponse{
@@ -377,6 +492,81 @@ func (i InfantRoutes) HivScreeningHandler(w http.ResponseWriter, r *http.Request
 	}
 }
 
+type hivTestingScheduleResponse struct {
+	Schedule []hivScreenings.ScheduledTest `json:"schedule"`
+	Infant   infant.Infant                 `json:"infant"`
+}
+
+// HivTestingScheduleHandler returns the tests that an HIV-exposed infant is expected to have,
+// with their due dates and status. It is worked out from the birth date in ACSIS and the hiv
+// screening rules on every request.
+func (i InfantRoutes) HivTestingScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	handlerName := "HivTestingScheduleHandler"
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		id := mux.Vars(r)["infantId"]
+		infantId, err := strconv.Atoi(id)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": id,
+				"handler":  handlerName,
+			}).WithError(err).Error("infant id is not a valid number")
+			http.Error(w, "infant id must be a numeric value", http.StatusBadRequest)
+			return
+		}
+		inf, err := i.Infant.FindInfant(r.Context(), infantId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": infantId,
+				"handler":  handlerName,
+			}).WithError(err).Error("error retrieving the infant")
+			internalError(w, err)
+			return
+		}
+		if inf == nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if inf.Infant.Dob == nil {
+			http.Error(w, "the infant has no birth date", http.StatusNotFound)
+			return
+		}
+		screenings, err := i.HivScreenings.FindByPatientId(r.Context(), infantId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": infantId,
+				"handler":  handlerName,
+			}).WithError(err).Error("error retrieving the hiv screenings of the infant")
+			internalError(w, err)
+			return
+		}
+		rules, err := i.HivScreenings.FindRules(r.Context())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": infantId,
+				"handler":  handlerName,
+			}).WithError(err).Error("error retrieving the hiv screening rules")
+			internalError(w, err)
+			return
+		}
+		response := hivTestingScheduleResponse{
+			Schedule: hivScreenings.Schedule(rules, *inf.Infant.Dob, screenings, time.Now()),
+			Infant:   *inf,
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.WithFields(log.Fields{
+				"infantId": infantId,
+				"handler":  handlerName,
+			}).WithError(err).Error("error encoding the hiv testing schedule")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
 func (i InfantRoutes) InfantSyphilisTreatmentHandler(w http.ResponseWriter, r *http.Request) {
 	switch method := r.Method; method {
 	case http.MethodOptions:
@@ -395,23 +585,27 @@ func (i InfantRoutes) InfantSyphilisTreatmentHandler(w http.ResponseWriter, r *h
 			http.Error(w, "patient id is not a valid number", http.StatusBadRequest)
 			return
 		}
-		treatments, err := i.Infant.FindInfantSyphilisTreatment(infantId)
+		treatments, err := i.Infant.FindInfantSyphilisTreatment(r.Context(), infantId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": infantId,
 				"user":      user,
 			}).WithError(err).Error("error retrieving syphilis treatment")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		infant, err := i.Infant.FindInfant(infantId)
+		infant, err := i.Infant.FindInfant(r.Context(), infantId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": infantId,
 				"user":      user,
 				"handler":   "SyphilisTreatmentHandler",
 			}).WithError(err).Error("error retrieving patient information")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
+			return
+		}
+		if infant == nil {
+			http.Error(w, fmt.Sprintf("infant with id %d does not exist", infantId), http.StatusNotFound)
 			return
 		}
 		response := infantTreatmentResponse{
@@ -450,17 +644,17 @@ func (i InfantRoutes) InfantSyphilisScreeninngHandler(w http.ResponseWriter, r *
 			log.WithFields(log.Fields{
 				"infantId": infantId,
 				"handler":  "InfantSyphilisScreeningHandler",
-			}).WithError(err).Error("infantId is not a valid number: %+v", err)
+			}).WithError(err).Error("infantId is not a valid number")
 			http.Error(w, "infantId is not a valid number", http.StatusBadRequest)
 			return
 		}
-		infantInfo, err := i.Infant.FindInfant(id)
+		infantInfo, err := i.Infant.FindInfant(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"infantId": id,
 				"handler":  "InfantSyphilisScreeningHandler",
 			}).WithError(err).Error("error retrieving infant information")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		if infantInfo == nil {
@@ -471,14 +665,28 @@ func (i InfantRoutes) InfantSyphilisScreeninngHandler(w http.ResponseWriter, r *
 			http.Error(w, fmt.Sprintf("infant with id %d does not exist", id), http.StatusNotFound)
 			return
 		}
-		screenings, err := i.Labs.FindInfantSyphilisScreenings(id, *infantInfo.Infant.Dob)
+		// The due dates and timeliness of the screenings are counted from the birth date.
+		if infantInfo.Infant.Dob == nil {
+			http.Error(w, "the infant has no birth date", http.StatusNotFound)
+			return
+		}
+		rules, err := i.SyphilisScreeningRules.FindRules(r.Context())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": id,
+				"handler":  "InfantSyphilisScreeningHandler",
+			}).WithError(err).Error("error retrieving the syphilis screening rules")
+			internalError(w, err)
+			return
+		}
+		screenings, err := i.Labs.FindInfantSyphilisScreenings(r.Context(), id, *infantInfo.Infant.Dob, rules)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"infantId":   id,
 				"handler":    "InfantSyphilisScreeningHandler",
 				"infantInfo": infantInfo,
 			}).WithError(err).Error("error retrieving syphilis screenings for infant")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		response := infantSyphilisScreeningResponse{
@@ -497,3 +705,162 @@ func (i InfantRoutes) InfantSyphilisScreeninngHandler(w http.ResponseWriter, r *
 		}
 	}
 }
+
+type infantPregnancyRequest struct {
+	PregnancyId int `json:"pregnancyId"`
+}
+
+// InfantPregnancyHandler returns the pregnancy that an infant is linked to, and lets data
+// managers correct a wrong link by hand. A link that was set by hand is kept by the births etl.
+func (i InfantRoutes) InfantPregnancyHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	handlerName := "InfantPregnancyHandler"
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		id := mux.Vars(r)["infantId"]
+		infantId, err := strconv.Atoi(id)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": id,
+				"handler":  handlerName,
+			}).WithError(err).Error("infant id is not a valid number")
+			http.Error(w, "infant id must be a numeric value", http.StatusBadRequest)
+			return
+		}
+		birth, err := i.Infant.FindBirth(r.Context(), infantId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": infantId,
+				"handler":  handlerName,
+			}).WithError(err).Error("error retrieving the infant's pregnancy")
+			internalError(w, err)
+			return
+		}
+		if birth == nil {
+			http.Error(w, "the infant is not linked to a pregnancy", http.StatusNotFound)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(birth); err != nil {
+			log.WithFields(log.Fields{
+				"birth":   birth,
+				"handler": handlerName,
+			}).WithError(err).Error("error encoding the infant's pregnancy")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPut:
+		method := "PUT"
+		token := r.Context().Value("user").(app.JwtToken)
+		user := token.Email
+		id := mux.Vars(r)["infantId"]
+		infantId, err := strconv.Atoi(id)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": id,
+				"user":     user,
+				"method":   method,
+				"handler":  handlerName,
+			}).WithError(err).Error("infant id is not a valid number")
+			http.Error(w, "infant id must be a numeric value", http.StatusBadRequest)
+			return
+		}
+		var req infantPregnancyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.WithFields(log.Fields{
+				"infantId": infantId,
+				"user":     user,
+				"method":   method,
+				"handler":  handlerName,
+			}).WithError(err).Error("could not decode request")
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		pregs, err := i.Pregnancies.FindByIds(r.Context(), []int{req.PregnancyId})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId":    infantId,
+				"pregnancyId": req.PregnancyId,
+				"user":        user,
+				"method":      method,
+				"handler":     handlerName,
+			}).WithError(err).Error("error retrieving the pregnancy to link the infant to")
+			internalError(w, err)
+			return
+		}
+		preg, ok := pregs[req.PregnancyId]
+		if !ok {
+			http.Error(w, "the pregnancy does not exist", http.StatusBadRequest)
+			return
+		}
+		infantInfo, err := i.Infant.FindInfant(r.Context(), infantId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"infantId": infantId,
+				"user":     user,
+				"method":   method,
+				"handler":  handlerName,
+			}).WithError(err).Error("error retrieving the infant to link")
+			internalError(w, err)
+			return
+		}
+		if infantInfo == nil {
+			http.Error(w, "the infant does not exist", http.StatusNotFound)
+			return
+		}
+		birth := infant.Birth{
+			InfantId:    infantId,
+			MotherId:    preg.PatientId,
+			PregnancyId: &preg.PregnancyId,
+			BirthDate:   infantInfo.Infant.Dob,
+		}
+		saved, err := i.Infant.LinkBirth(r.Context(), birth, user)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"birth":   birth,
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("error linking the infant to the pregnancy")
+			internalError(w, err)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(saved); err != nil {
+			log.WithFields(log.Fields{
+				"birth":   saved,
+				"user":    user,
+				"method":  method,
+				"handler": handlerName,
+			}).WithError(err).Error("error encoding the infant's pregnancy")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// DeleteHivScreeningHandler soft-deletes an hiv screening that was recorded by mistake.
+func (i InfantRoutes) DeleteHivScreeningHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodDelete:
+		infantId, err := strconv.Atoi(mux.Vars(r)["infantId"])
+		if err != nil {
+			http.Error(w, "infant id must be a numeric value", http.StatusBadRequest)
+			return
+		}
+		// Only delete the screening through the url of its own infant.
+		del := func(ctx context.Context, id, user, reason string) (bool, error) {
+			screening, err := i.HivScreenings.FindById(ctx, id)
+			if err != nil || screening == nil || screening.PatientId != infantId {
+				return false, err
+			}
+			return i.HivScreenings.Delete(ctx, id, user, reason)
+		}
+		softDelete(w, r, "hivScreening", "screeningId", del)
+	}
+}
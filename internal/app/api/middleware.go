@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/uris77/auth0"
 
@@ -15,7 +16,8 @@ func EnableCors() Middleware {
 		return func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Referer, Connection")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Referer, Connection, If-Match")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
 			f(w, r)
 		}
 	}
@@ -44,3 +46,25 @@ func VerifyToken(jwkUrl, aud, iss string, auth0Client auth0.Auth0) Middleware {
 		}
 	}
 }
+
+// RequireAdmin only lets the users in admins through. It must run after VerifyToken.
+func RequireAdmin(admins []string) Middleware {
+	allowed := make(map[string]bool, len(admins))
+	for _, a := range admins {
+		allowed[strings.ToLower(a)] = true
+	}
+	return func(f http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" {
+				f(w, r)
+				return
+			}
+			token, ok := r.Context().Value("user").(app.JwtToken)
+			if !ok || !allowed[strings.ToLower(token.Email)] {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			f(w, r)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+)
+
+// pageQuery reads the page of a list from the query string of r:
+// limit is the number of records, up to paging.MaxLimit;
+// cursor is the nextCursor of the previous page;
+// sort is -date, newest first, which is the default, or date, oldest first;
+// from and to are the first and the last day of the records, as 2006-01-02.
+// It responds with a 400 and returns false when one of them is not valid.
+func pageQuery(w http.ResponseWriter, r *http.Request) (paging.Query, bool) {
+	var q paging.Query
+	if err := parsePageQuery(r, &q); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return q, false
+	}
+	return q, true
+}
+
+func parsePageQuery(r *http.Request, q *paging.Query) error {
+	params := r.URL.Query()
+	if s := params.Get("limit"); len(s) > 0 {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > paging.MaxLimit {
+			return fmt.Errorf("the limit must be a number from 1 to %d", paging.MaxLimit)
+		}
+		q.Limit = limit
+	}
+	if s := params.Get("cursor"); len(s) > 0 {
+		after, err := paging.ParseCursor(s)
+		if err != nil {
+			return err
+		}
+		q.After = after
+	}
+	switch params.Get("sort") {
+	case "", "-date":
+	case "date":
+		q.Ascending = true
+	default:
+		return fmt.Errorf("the sort must be date or -date")
+	}
+	if s := params.Get("from"); len(s) > 0 {
+		from, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return fmt.Errorf("from must be a date like 2006-01-02")
+		}
+		q.From = &from
+	}
+	if s := params.Get("to"); len(s) > 0 {
+		to, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return fmt.Errorf("to must be a date like 2006-01-02")
+		}
+		// Include the records of the last day.
+		to = to.AddDate(0, 0, 1)
+		q.To = &to
+	}
+	return nil
+}
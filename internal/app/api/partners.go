@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,11 +15,12 @@ import (
 	"moh.gov.bz/mch/emtct/internal/business/data/partners"
 	"moh.gov.bz/mch/emtct/internal/business/data/patient"
 	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type partnersRoutes struct {
-	Patient  patient.Patients
-	Partners partners.Partners
+	Patient  patient.Store
+	Partners partners.Store
 }
 
 type newSyphilisTreatmentRequest struct {
@@ -49,29 +51,34 @@ func (p *partnersRoutes) SyphilisTreatmentHandler(w http.ResponseWriter, r *http
 			http.Error(w, "patient id must be a valid number", http.StatusBadRequest)
 			return
 		}
-		treatments, err := p.Partners.FindPartnerSyphilisTreatments(patientId)
+		q, ok := pageQuery(w, r)
+		if !ok {
+			return
+		}
+		treatments, page, err := p.Partners.FindPartnerSyphilisTreatments(r.Context(), patientId, q)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"patientId": patientId,
 				"handler":   handlerName,
 			}).WithError(err).Error("error while finding partner's syphilis treatment")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		patient, err := p.Patient.FindBasicInfo(patientId)
+		patient, err := p.Patient.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"patientId": patientId,
 				"handler":   handlerName,
 			}).WithError(err).Error("error querying patient's basic info")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		response := map[string]interface{}{
 			"patient":    patient,
 			"treatments": treatments,
+			"page":       page,
 		}
 		w.Header().Add("Content-type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -103,7 +110,7 @@ func (p *partnersRoutes) SyphilisTreatmentHandler(w http.ResponseWriter, r *http
 				"body":      r.Body,
 				"handler":   handlerName,
 			}).WithError(err).Error("error decoding request")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		location, _ := time.LoadLocation("Local")
@@ -116,17 +123,19 @@ func (p *partnersRoutes) SyphilisTreatmentHandler(w http.ResponseWriter, r *http
 			Date:       treatmentReq.Date.In(location),
 			CreatedBy:  user,
 			CreatedAt:  time.Now(),
+			Version:    1,
 		}
-		if err := p.Partners.AddPartnerSyphilisTreatment(treatment); err != nil {
+		if err := p.Partners.AddPartnerSyphilisTreatment(r.Context(), treatment); err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"request":   treatmentReq,
 				"treatment": treatment,
 				"handler":   handlerName,
 			}).WithError(err).Error("error adding a partner's syphilis treatment")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		setETag(w, treatment.Version)
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(treatment); err != nil {
 			log.WithFields(log.Fields{
@@ -138,6 +147,10 @@ func (p *partnersRoutes) SyphilisTreatmentHandler(w http.ResponseWriter, r *http
 	case http.MethodPut:
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
+		version, ok := ifMatch(w, r)
+		if !ok {
+			return
+		}
 		var treatment prescription.SyphilisTreatment
 		if err := json.NewDecoder(r.Body).Decode(&treatment); err != nil {
 			log.WithFields(log.Fields{
@@ -145,7 +158,7 @@ func (p *partnersRoutes) SyphilisTreatmentHandler(w http.ResponseWriter, r *http
 				"body":    r.Body,
 				"handler": handlerName,
 			}).WithError(err).Error("error decoding the request")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		treatment.UpdatedBy = user
@@ -153,15 +166,36 @@ func (p *partnersRoutes) SyphilisTreatmentHandler(w http.ResponseWriter, r *http
 		treatment.UpdatedAt = &today
 		location, _ := time.LoadLocation("Local")
 		treatment.Date = treatment.Date.In(location)
-		if err := p.Partners.UpdatePartnerSyphilisTreatment(treatment); err != nil {
+		treatment.Version = version
+		err := p.Partners.UpdatePartnerSyphilisTreatment(r.Context(), treatment)
+		if errors.Is(err, db.ErrVersionConflict) {
+			current, err := p.Partners.FindPartnerSyphilisTreatment(r.Context(), treatment.Id)
+			if err != nil {
+				log.WithFields(log.Fields{"user": user, "treatmentId": treatment.Id, "handler": handlerName}).
+					WithError(err).
+					Error("error retrieving the current treatment")
+				internalError(w, err)
+				return
+			}
+			if current == nil {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			preconditionFailed(w, current, current.Version)
+			return
+		}
+		if err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
 				"treatment": treatment,
 				"handler":   handlerName,
 			}).WithError(err).Error("failed to update treatment")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
+		treatment.Version++
+		setETag(w, treatment.Version)
+		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(treatment); err != nil {
 			log.WithFields(log.Fields{
 				"user":      user,
@@ -171,3 +205,14 @@ func (p *partnersRoutes) SyphilisTreatmentHandler(w http.ResponseWriter, r *http
 		}
 	}
 }
+
+// DeleteSyphilisTreatmentHandler soft-deletes a partner's syphilis treatment that was recorded by mistake.
+func (p *partnersRoutes) DeleteSyphilisTreatmentHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodDelete:
+		softDelete(w, r, "syphilisTreatment", "treatmentId", p.Partners.DeletePartnerSyphilisTreatment)
+	}
+}
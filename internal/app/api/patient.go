@@ -38,11 +38,11 @@ func (a *pregnancyRoutes) RetrievePatientHandler(w http.ResponseWriter, r *http.
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		patient, err := a.Patient.FindByPatientId(id)
+		patient, err := a.Patient.FindByPatientId(r.Context(), id)
 		if err != nil {
 			log.WithFields(
 				log.Fields{"request": r}).WithError(err).Error("could not find patient with specified id")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		// We assume that the patient's HIV status is negative.
@@ -50,14 +50,14 @@ func (a *pregnancyRoutes) RetrievePatientHandler(w http.ResponseWriter, r *http.
 
 		// Retrieve all the hiv diagnoses so we can get the
 		// patient's HIV status and first date of diagnoses.
-		hivDiagnoses, err := a.Hiv.FindHivDiagnoses(id)
+		hivDiagnoses, err := a.Hiv.FindHivDiagnoses(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": id,
 				"patient":   patient,
 				"handler":   "RetrievePatient",
-			}).WithError(err).Error("error retrieving patient hiv diagnoses: %+v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}).WithError(err).Error("error retrieving patient hiv diagnoses")
+			internalError(w, err)
 			return
 		}
 		// If there are any diagnoses, we retrieve the first diagnosis and use its date as the diagnosis date.
@@ -66,35 +66,33 @@ func (a *pregnancyRoutes) RetrievePatientHandler(w http.ResponseWriter, r *http.
 			patient.HivDiagnosisDate = &hivDiagnoses[0].Date
 		}
 
-		diagnoses, err := a.Pregnancies.FindDiagnosesBeforePregnancy(id)
+		// The diagnoses and the anc encounter are the ones of the current pregnancy.
+		current, err := a.Pregnancies.FindLatest(r.Context(), id)
 		if err != nil {
-			log.WithFields(
-				log.Fields{"request": r}).WithError(err).Error("could not retrieve obstetric history for the patient")
+			log.WithFields(log.Fields{"patientId": id}).WithError(err).Error("could not retrieve the current pregnancy")
+		}
+		var diagnoses []pregnancy.Diagnosis
+		var ancEncounter *pregnancy.AntenatalEncounter
+		if current != nil {
+			diagnoses, err = a.Pregnancies.FindDiagnosesBeforePregnancy(r.Context(), *current)
+			if err != nil {
+				log.WithFields(
+					log.Fields{"request": r}).WithError(err).Error("could not retrieve obstetric history for the patient")
+			}
+			ancEncounter, err = a.Pregnancies.FindAntenatalEncounter(r.Context(), *current)
+			if err != nil {
+				log.WithFields(log.Fields{"patientId": id}).WithError(err).Error("could not retrieve anc encounter")
+			}
 		}
 		if diagnoses == nil {
 			diagnoses = []pregnancy.Diagnosis{}
 		}
 
-		obstetricHistory, err := a.Pregnancies.FindObstetricHistory(id)
+		obstetricHistory, err := a.Pregnancies.FindObstetricHistory(r.Context(), id)
 		if err != nil {
 			log.WithFields(log.Fields{"request": r}).WithError(err).Error("could not retrieve obstetric history")
 		}
 
-		v, err := a.Pregnancies.FindObstetricDetails(id)
-		var lmp *time.Time
-		if err != nil {
-			log.WithFields(log.Fields{
-				"patientId": id,
-			}).WithError(err).Error("could not find patient's obstetric details, using nil for lmp")
-		}
-		if v != nil {
-			lmp = v.Lmp
-		}
-		ancEncounter, err := a.Pregnancies.FindLatestAntenatalEncounter(id, lmp)
-		if err != nil {
-			log.WithFields(log.Fields{"patientId": id}).WithError(err).Error("could not retrieve anc encounter")
-		}
-
 		w.Header().Add("Content-Type", "application/json")
 
 		if patient == nil {
@@ -121,8 +119,9 @@ func (a *pregnancyRoutes) RetrievePatientHandler(w http.ResponseWriter, r *http.
 }
 
 type arvsResponse struct {
-	Arvs    []prescription.Prescription `json:"arvs"`
-	Patient patient.BasicInfo           `json:"patient"`
+	Arvs      []prescription.Prescription `json:"arvs"`
+	Patient   patient.BasicInfo           `json:"patient"`
+	Gestation *pregnancy.Gestation        `json:"gestation"`
 }
 
 func (a *pregnancyRoutes) ArvsHandler(w http.ResponseWriter, r *http.Request) {
@@ -134,78 +133,57 @@ func (a *pregnancyRoutes) ArvsHandler(w http.ResponseWriter, r *http.Request) {
 		method := "Get"
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
-		vars := mux.Vars(r)
-		id := vars["patientId"]
-		patientId, err := strconv.Atoi(id)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"patientId": id,
-				"user":      user,
-				"handler":   handlerName,
-				"method":    method,
-			}).
-				WithError(err).
-				Error("patient id is not a valid number")
-			http.Error(w, "patientId is not a valid number", http.StatusBadRequest)
-			return
-		}
 		// Find the pregnancy and the lmp so we can get the date bounds
-		pregs, err := a.Pregnancies.FindCurrentPregnancy(patientId)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"patientId": id,
-				"user":      user,
-				"handler":   handlerName,
-				"method":    method,
-			}).
-				WithError(err).
-				Error("error retrieving patient's current pregnancy")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		preg := findPregnancy(w, r, a.Pregnancies)
+		if preg == nil {
 			return
 		}
-		if pregs == nil {
+		patientId := preg.PatientId
+		lmp, nextDate, ok := preg.Period()
+		if !ok {
 			log.WithFields(log.Fields{
-				"patientId": id,
+				"pregnancy": preg,
 				"user":      user,
 				"handler":   handlerName,
 				"method":    method,
-			}).
-				WithError(err).
-				Error("patient does not have a current pregnancy")
-			http.Error(w, "patient does not have a current pregnancy", http.StatusNotFound)
+			}).Error("the pregnancy does not have an lmp")
+			http.Error(w, "the pregnancy does not have an lmp", http.StatusNotFound)
 			return
 		}
-		lmp := pregs.Lmp
-		nextDate := lmp.Add(time.Hour * 24 * 7 * 54)
-		arvs, err := a.Patient.FindArvsByPatient(patientId, *lmp, nextDate)
+		arvs, err := a.Patient.FindArvsByPatient(r.Context(), patientId, lmp, nextDate)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"patientId": id,
+				"patientId": patientId,
 				"user":      user,
 				"handler":   handlerName,
 				"method":    method,
 			}).
 				WithError(err).
-				Error("patient does not have a current pregnancy")
-			http.Error(w, "patient does not have a current pregnancy", http.StatusNotFound)
+				Error("error retrieving the patient's arvs")
+			internalError(w, err)
 			return
 		}
-		patientInfo, err := a.Patient.FindBasicInfo(patientId)
+		patientInfo, err := a.Patient.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"patientId": id,
+				"patientId": patientId,
 				"user":      user,
 				"handler":   handlerName,
 				"method":    method,
 			}).
 				WithError(err).
 				Error("error retrieving patient's basic info")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
+			return
+		}
+		gestation := findGestation(w, r, a.Pregnancies, *preg)
+		if gestation == nil {
 			return
 		}
 		arvsResponse := arvsResponse{
-			Arvs:    arvs,
-			Patient: *patientInfo,
+			Arvs:      arvs,
+			Patient:   *patientInfo,
+			Gestation: gestation,
 		}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(arvsResponse); err != nil {
@@ -246,24 +224,24 @@ func (a *pregnancyRoutes) PatientSyphilisTreatmentHandler(w http.ResponseWriter,
 			http.Error(w, "patient id is not a valid number", http.StatusBadRequest)
 			return
 		}
-		basicInfo, err := a.Patient.FindBasicInfo(patientId)
+		basicInfo, err := a.Patient.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": id,
 				"handler":   handlerName,
 				"method":    method,
 			}).WithError(err).Error("failed to retrieve patient basic info")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		preg, err := a.Pregnancies.FindLatest(patientId)
+		preg, err := a.Pregnancies.FindLatest(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": id,
 				"handler":   handlerName,
 				"method":    method,
 			}).WithError(err).Error("failed to retrieve patient latest pregnancy")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		if preg == nil {
@@ -276,7 +254,7 @@ func (a *pregnancyRoutes) PatientSyphilisTreatmentHandler(w http.ResponseWriter,
 		}
 		lmp := preg.Lmp
 		endDate := lmp.Add(time.Hour * 24 * 7 * 52)
-		treatments, err := a.Patient.FindSyphilisTreatment(patientId, lmp, &endDate)
+		treatments, err := a.Patient.FindSyphilisTreatment(r.Context(), patientId, lmp, &endDate)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": id,
@@ -285,7 +263,7 @@ func (a *pregnancyRoutes) PatientSyphilisTreatmentHandler(w http.ResponseWriter,
 				"lmp":       lmp,
 				"endDate":   endDate,
 			}).WithError(err).Error("failed to retrieve patient syphilis treatment")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		response := treatmentResponse{
@@ -2,7 +2,6 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -17,125 +16,288 @@ import (
 )
 
 type pregnancyRoutes struct {
-	Pregnancies pregnancy.Pregnancies
-	Patient     patient.Patients
-	Hiv         hiv.HIV
-	Lab         labs.Labs
+	Pregnancies pregnancy.Store
+	Patient     patient.Store
+	Hiv         hiv.Store
+	Lab         labs.Store
 }
 
-type pregnancyResponse struct {
-	Vitals    *pregnancy.Vitals     `json:"vitals"`
-	Diagnoses []pregnancy.Diagnosis `json:"diagnoses"`
+// findPregnancy resolves the pregnancy that a request is about: the pregnancy in the pregnancyId of
+// its url, or else the current pregnancy of the patient in patientId, which is the one with the
+// latest LMP. It responds with a 400, 404 or 500 and returns nil when there is no such pregnancy.
+func findPregnancy(w http.ResponseWriter, r *http.Request, pregnancies pregnancy.Store) *pregnancy.Pregnancy {
+	vars := mux.Vars(r)
+	if id, ok := vars["pregnancyId"]; ok {
+		pregnancyId, err := strconv.Atoi(id)
+		if err != nil {
+			log.WithFields(log.Fields{"pregnancyId": id}).WithError(err).Error("pregnancy id is not a number")
+			http.Error(w, "the pregnancy id must be a valid number", http.StatusBadRequest)
+			return nil
+		}
+		ps, err := pregnancies.FindByIds(r.Context(), []int{pregnancyId})
+		if err != nil {
+			log.WithFields(log.Fields{"pregnancyId": pregnancyId}).
+				WithError(err).
+				Error("error retrieving the pregnancy")
+			internalError(w, err)
+			return nil
+		}
+		p, ok := ps[pregnancyId]
+		if !ok {
+			http.Error(w, "the pregnancy does not exist", http.StatusNotFound)
+			return nil
+		}
+		return &p
+	}
+	id := vars["patientId"]
+	patientId, err := strconv.Atoi(id)
+	if err != nil {
+		log.WithFields(log.Fields{"patientId": id}).WithError(err).Error("patient id is not a number")
+		http.Error(w, "the patient id must be a valid number", http.StatusBadRequest)
+		return nil
+	}
+	p, err := pregnancies.FindLatest(r.Context(), patientId)
+	if err != nil {
+		log.WithFields(log.Fields{"patientId": patientId}).
+			WithError(err).
+			Error("error retrieving the patient's current pregnancy")
+		internalError(w, err)
+		return nil
+	}
+	if p == nil {
+		http.Error(w, "the patient does not have a pregnancy", http.StatusNotFound)
+		return nil
+	}
+	return p
 }
 
-func (a *pregnancyRoutes) FindCurrentPregnancy(w http.ResponseWriter, r *http.Request) {
-	if r.Method == "OPTIONS" {
-		return
+// findGestation returns the gestation of a pregnancy today. It responds with a 500 and returns
+// nil when it cannot be found.
+func findGestation(w http.ResponseWriter, r *http.Request, pregnancies pregnancy.Store, p pregnancy.Pregnancy) *pregnancy.Gestation {
+	g, err := pregnancies.FindGestation(r.Context(), p)
+	if err != nil {
+		log.WithFields(log.Fields{"pregnancy": p}).
+			WithError(err).
+			Error("error retrieving the gestation of the pregnancy")
+		internalError(w, err)
+		return nil
 	}
+	return g
+}
+
+type pregnancyWithGestation struct {
+	pregnancy.Pregnancy
+	Gestation *pregnancy.Gestation `json:"gestation"`
+}
+
+type pregnanciesResponse struct {
+	Pregnancies []pregnancyWithGestation `json:"pregnancies"`
+	Patient     patient.BasicInfo        `json:"patient"`
+}
+
+// PregnanciesHandler lists every pregnancy of a patient, the latest LMP first. The id of a
+// pregnancy addresses it in the /api/pregnancies/{pregnancyId} urls.
+func (a *pregnancyRoutes) PregnanciesHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodOptions:
 		return
 	case http.MethodGet:
-		vars := mux.Vars(r)
-		patientId := vars["patientId"]
-		id, err := strconv.Atoi(patientId)
+		id := mux.Vars(r)["patientId"]
+		patientId, err := strconv.Atoi(id)
 		if err != nil {
-			log.WithFields(log.Fields{"patientId": patientId}).WithError(err).Error("patient id is not a number")
-			http.Error(w, "the patient id provided is invalid", http.StatusBadRequest)
+			log.WithFields(log.Fields{"patientId": id}).WithError(err).Error("patient id is not a number")
+			http.Error(w, "the patient id must be a valid number", http.StatusBadRequest)
 			return
 		}
-		preg, err := a.Pregnancies.FindCurrentPregnancy(id)
+		pregnancies, err := a.Pregnancies.FindByPatientId(r.Context(), patientId)
 		if err != nil {
-			log.WithFields(log.Fields{"patientId": patientId}).
+			log.WithFields(log.Fields{"patientId": patientId, "handler": "PregnanciesHandler"}).
 				WithError(err).
-				Error("error retrieving current pregnancy from database")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				Error("error retrieving the patient's pregnancies")
+			internalError(w, err)
 			return
 		}
-
-		diagnoses, err := a.Pregnancies.FindDiagnosesDuringPregnancy(id)
+		patientInfo, err := a.Patient.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
-			log.WithFields(log.Fields{"patientId": patientId, "pregnancy": preg}).
+			log.WithFields(log.Fields{"patientId": patientId, "handler": "PregnanciesHandler"}).
 				WithError(err).
-				Error("error fetching diagnoses for a pregnancy")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				Error("error retrieving patient basic info")
+			internalError(w, err)
 			return
 		}
-
-		if preg == nil {
-			w.Header().Add("Content-Type", "application/json")
-			r, _ := json.Marshal(nil)
-			fmt.Fprintf(w, string(r))
+		if patientInfo == nil {
+			http.Error(w, "the patient does not exist", http.StatusNotFound)
+			return
 		}
-
-		response := pregnancyResponse{
-			Vitals:    preg,
-			Diagnoses: diagnoses,
+		gestations, err := a.Pregnancies.FindGestations(r.Context(), pregnancies)
+		if err != nil {
+			log.WithFields(log.Fields{"patientId": patientId, "handler": "PregnanciesHandler"}).
+				WithError(err).
+				Error("error retrieving the gestations of the patient's pregnancies")
+			internalError(w, err)
+			return
 		}
-
+		withGestation := []pregnancyWithGestation{}
+		for i, p := range pregnancies {
+			withGestation = append(withGestation, pregnancyWithGestation{Pregnancy: p, Gestation: &gestations[i]})
+		}
+		response := pregnanciesResponse{Pregnancies: withGestation, Patient: *patientInfo}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
-			log.WithFields(log.Fields{"patientId": patientId, "pregnancy": preg, "diagnoses": diagnoses}).
+			log.WithFields(log.Fields{"patientId": patientId, "pregnancies": pregnancies}).
 				WithError(err).
-				Error("error marshalling pregnancy")
+				Error("error marshalling pregnancies")
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
 	}
+}
 
+type pregnancyResponse struct {
+	Vitals    *pregnancy.Vitals     `json:"vitals"`
+	Diagnoses []pregnancy.Diagnosis `json:"diagnoses"`
 }
 
-type pregnancyLabResultsResponse struct {
-	LabResults []labs.LabResult  `json:"labResults"`
-	Patient    patient.BasicInfo `json:"patient"`
+// VitalsHandler returns the vitals of a pregnancy and the diagnoses during it. The vitals are
+// null when the pregnancy has no antenatal encounter.
+func (a *pregnancyRoutes) VitalsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		p := findPregnancy(w, r, a.Pregnancies)
+		if p == nil {
+			return
+		}
+		a.writeVitals(w, r, *p)
+	}
 }
 
-func (a *pregnancyRoutes) FindPregnancyLabResults(w http.ResponseWriter, r *http.Request) {
-	handlerName := "FindPregnancyLabResults"
+// CurrentPregnancyHandler returns the vitals of the current pregnancy of a patient and the
+// diagnoses during it, like VitalsHandler. A patient without a pregnancy, e.g. one that was not
+// synced from ACSIS yet, has null vitals and no diagnoses, as before pregnancies were addressed
+// by id, instead of a 404.
+func (a *pregnancyRoutes) CurrentPregnancyHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodOptions:
 		return
 	case http.MethodGet:
-		method := "GET"
-		vars := mux.Vars(r)
-		patientId := vars["patientId"]
-		id, err := strconv.Atoi(patientId)
+		id := mux.Vars(r)["patientId"]
+		patientId, err := strconv.Atoi(id)
 		if err != nil {
-			log.WithFields(log.Fields{"patientId": patientId}).
-				WithError(err).Error("patient id is not a numeric value")
-			http.Error(w, "patient id must be a valid number", http.StatusBadRequest)
+			log.WithFields(log.Fields{"patientId": id}).WithError(err).Error("patient id is not a number")
+			http.Error(w, "the patient id must be a valid number", http.StatusBadRequest)
 			return
 		}
-		preg, err := a.Pregnancies.FindCurrentPregnancy(id)
+		p, err := a.Pregnancies.FindLatest(r.Context(), patientId)
 		if err != nil {
-			log.WithFields(log.Fields{
-				"patientId": patientId,
-				"handler":   handlerName,
-				"method":    method,
-			}).WithError(err).Error("no current pregnancy found")
-			http.Error(w, "did not find any current pregnancy", http.StatusNotFound)
+			log.WithFields(log.Fields{"patientId": patientId}).
+				WithError(err).
+				Error("error retrieving the patient's current pregnancy")
+			internalError(w, err)
+			return
+		}
+		if p == nil {
+			w.Header().Add("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(pregnancyResponse{Diagnoses: []pregnancy.Diagnosis{}}); err != nil {
+				log.WithFields(log.Fields{"patientId": patientId}).
+					WithError(err).
+					Error("error marshalling pregnancy")
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+		a.writeVitals(w, r, *p)
+	}
+}
+
+// writeVitals writes the vitals of p and the diagnoses during it.
+func (a *pregnancyRoutes) writeVitals(w http.ResponseWriter, r *http.Request, p pregnancy.Pregnancy) {
+	preg, err := a.Pregnancies.FindVitals(r.Context(), p)
+	if err != nil {
+		log.WithFields(log.Fields{"pregnancy": p}).
+			WithError(err).
+			Error("error retrieving pregnancy vitals from database")
+		internalError(w, err)
+		return
+	}
+
+	diagnoses, err := a.Pregnancies.FindDiagnosesDuringPregnancy(r.Context(), p)
+	if err != nil {
+		log.WithFields(log.Fields{"pregnancy": p}).
+			WithError(err).
+			Error("error fetching diagnoses for a pregnancy")
+		internalError(w, err)
+		return
+	}
+	// Return an empty array if no results are found
+	if diagnoses == nil {
+		diagnoses = []pregnancy.Diagnosis{}
+	}
+
+	response := pregnancyResponse{
+		Vitals:    preg,
+		Diagnoses: diagnoses,
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.WithFields(log.Fields{"pregnancy": p, "vitals": preg, "diagnoses": diagnoses}).
+			WithError(err).
+			Error("error marshalling pregnancy")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+type pregnancyLabResultsResponse struct {
+	LabResults []labs.LabResult     `json:"labResults"`
+	Patient    patient.BasicInfo    `json:"patient"`
+	Gestation  *pregnancy.Gestation `json:"gestation"`
+}
+
+func (a *pregnancyRoutes) FindPregnancyLabResults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		return
+	case http.MethodGet:
+		preg := findPregnancy(w, r, a.Pregnancies)
+		if preg == nil {
 			return
 		}
-		labResults, err := a.Lab.FindLabTestsDuringPregnancy(id, preg.Lmp)
+		patientId := preg.PatientId
+		// Read the synced lab results, and only go to acsis for pregnancies that were never synced.
+		labResults, synced, err := a.Lab.FindSyncedLabResults(r.Context(), preg.PregnancyId)
+		if err == nil && !synced {
+			labResults, err = a.Lab.FindLabTestsDuringPregnancy(r.Context(), patientId, preg.Lmp)
+		}
 		if err != nil {
 			log.WithFields(log.Fields{"patientId": patientId}).
 				WithError(err).
 				Error("error while retrieving lab results")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		patient, err := a.Patient.FindBasicInfo(id)
+		patient, err := a.Patient.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"patientId": id,
+				"patientId": patientId,
 				"handler":   "FindPregnancyLabResults",
 			}).
 				WithError(err).
 				Error("error fetching patient information")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		response := pregnancyLabResultsResponse{Patient: *patient, LabResults: labResults}
+		if patient == nil {
+			http.Error(w, "the patient does not exist", http.StatusNotFound)
+			return
+		}
+		gestation := findGestation(w, r, a.Pregnancies, *preg)
+		if gestation == nil {
+			return
+		}
+		response := pregnancyLabResultsResponse{Patient: *patient, LabResults: labResults, Gestation: gestation}
 		w.Header().Add("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			log.WithFields(log.Fields{"labResults": labResults, "patientId": patientId}).
@@ -167,17 +329,17 @@ func (a *pregnancyRoutes) ObstetricHistoryHandler(w http.ResponseWriter, r *http
 		}
 		token := r.Context().Value("user").(app.JwtToken)
 		user := token.Email
-		obstetricHistory, err := a.Pregnancies.FindObstetricHistory(patientId)
+		obstetricHistory, err := a.Pregnancies.FindObstetricHistory(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": patientId,
 				"user":      user,
 				"handler":   "ObstetricHistoryHandler",
 			}).WithError(err).Error("error retrieving obstetric history")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
-		patientInfo, err := a.Patient.FindBasicInfo(patientId)
+		patientInfo, err := a.Patient.FindBasicInfo(r.Context(), patientId)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"patientId": patientId,
@@ -186,7 +348,7 @@ func (a *pregnancyRoutes) ObstetricHistoryHandler(w http.ResponseWriter, r *http
 			}).
 				WithError(err).
 				Error("error retrieving patient basic info")
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			internalError(w, err)
 			return
 		}
 		// Return an empty array if no results are found
@@ -1,17 +1,30 @@
 package app
 
-import "moh.gov.bz/mch/emtct/internal/db"
+import (
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
 
 type App struct {
 	EmtctDb *db.EmtctDb
 	AcsisDb *db.AcsisDb
 	Auth    Auth
+	Cache   CacheTtl
 }
 
 type Auth struct {
 	JwkUrl string
 	Iss    string
 	Aud    string
+	// Admins are the emails of the users that can use the admin endpoints.
+	Admins []string
+}
+
+// CacheTtl is how long the ACSIS lookups of each entity are cached. Zero disables the cache.
+type CacheTtl struct {
+	Patients time.Duration
+	Infants  time.Duration
 }
 
 type JwtToken struct {
@@ -1,24 +1,38 @@
 package admissions
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
-func (a *Admissions) FindByPatientId(patientId int) ([]HospitalAdmission, error) {
+// FindByPatientId returns the page q of the hospital admissions of a patient, by date admitted.
+func (a *Admissions) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]HospitalAdmission, paging.Info, error) {
+	ctx, cancel := a.WithTimeout(ctx)
+	defer cancel()
+	filter, args := q.Filter("date_admitted", 1)
+	var total int
+	count := `SELECT count(*) FROM hospital_admission WHERE patient_id=$1 AND deleted_at IS NULL` + filter
+	if err := a.QueryRowContext(ctx, count, append([]interface{}{patientId}, args...)...).Scan(&total); err != nil {
+		return nil, paging.Info{}, fmt.Errorf("error counting the hospital admissions: %w", err)
+	}
+	seek, args := q.Seek("date_admitted", "id", 1)
 	stmt := `
 	SELECT 
-	       id, patient_id, date_admitted, facility, reason, created_at, created_by, updated_at, updated_by, mch_encounter_id 
+	       id, patient_id, date_admitted, facility, reason, created_at, created_by, updated_at, updated_by, mch_encounter_id, version
 	FROM 
 	     hospital_admission 
 	WHERE 
-	      patient_id=$1`
+	      patient_id=$1 AND deleted_at IS NULL` + seek + q.OrderBy("date_admitted", "id")
 	var admissions []HospitalAdmission
-	rows, err := a.Query(stmt, patientId)
-	defer rows.Close()
+	rows, err := a.QueryContext(ctx, stmt, append([]interface{}{patientId}, args...)...)
 	if err != nil {
-		return admissions, fmt.Errorf("error when executing query to retrieve hospital admissions for a patient: %+v", err)
+		return nil, paging.Info{}, fmt.Errorf("error when executing query to retrieve hospital admissions for a patient: %w", err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var h HospitalAdmission
@@ -32,22 +46,28 @@ func (a *Admissions) FindByPatientId(patientId int) ([]HospitalAdmission, error)
 			&h.CreatedBy,
 			&h.UpdatedAt,
 			&h.UpdatedBy,
-			&h.MchEncounterId)
+			&h.MchEncounterId,
+			&h.Version)
 		if err != nil {
-			return admissions, fmt.Errorf("error scanning hotpsital admissions result from the database: %+v", err)
+			return nil, paging.Info{}, fmt.Errorf("error scanning hotpsital admissions result from the database: %w", err)
 		}
 		admissions = append(admissions, h)
 	}
-	return admissions, nil
+	info, n := q.NewInfo(total, len(admissions), func(i int) paging.Cursor {
+		return paging.Cursor{Date: admissions[i].DateAdmitted, Id: admissions[i].Id}
+	})
+	return admissions[:n], info, nil
 }
 
-func (a *Admissions) FindById(id string) (*HospitalAdmission, error) {
+func (a *Admissions) FindById(ctx context.Context, id string) (*HospitalAdmission, error) {
+	ctx, cancel := a.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
-	SELECT id, patient_id, date_admitted, facility, reason, created_at, created_by, updated_at, updated_by, mch_encounter_id
+	SELECT id, patient_id, date_admitted, facility, reason, created_at, created_by, updated_at, updated_by, mch_encounter_id, version
 	FROM hospital_admission 
-	WHERE id=$1`
+	WHERE id=$1 AND deleted_at IS NULL`
 	var admission HospitalAdmission
-	row := a.QueryRow(stmt, id)
+	row := a.QueryRowContext(ctx, stmt, id)
 	err := row.Scan(
 		&admission.Id,
 		&admission.PatientId,
@@ -58,39 +78,67 @@ func (a *Admissions) FindById(id string) (*HospitalAdmission, error) {
 		&admission.CreatedBy,
 		&admission.UpdatedAt,
 		&admission.UpdatedBy,
-		&admission.MchEncounterId)
+		&admission.MchEncounterId,
+		&admission.Version)
 	switch err {
 	case sql.ErrNoRows:
 		return nil, nil
 	case nil:
 		return &admission, nil
 	default:
-		return nil, fmt.Errorf("error retrieving hospital admission from database: %+v", err)
+		return nil, fmt.Errorf("error retrieving hospital admission from database: %w", err)
 	}
 }
 
-func (a *Admissions) Create(h HospitalAdmission) error {
+func (a *Admissions) Create(ctx context.Context, h HospitalAdmission) error {
+	ctx, cancel := a.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	INSERT INTO hospital_admission 
 	    (id, patient_id, date_admitted, facility, reason, created_at, created_by, mch_encounter_id) 
 	Values
 	       ($1, $2, $3, $4, $5, $6, $7, $8)`
-	_, err := a.Exec(stmt, h.Id, h.PatientId, h.DateAdmitted, h.Facility, h.Reason, h.CreatedAt, h.CreatedBy, h.MchEncounterId)
+	err := a.Audited(ctx, "hospital_admission", h.Id, db.AuditCreate, h.CreatedBy, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt, h.Id, h.PatientId, h.DateAdmitted, h.Facility, h.Reason, h.CreatedAt, h.CreatedBy, h.MchEncounterId)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error inserting a new hospital admission into the database: %+v", err)
+		return fmt.Errorf("error inserting a new hospital admission into the database: %w", err)
 	}
 	return nil
 }
 
-func (a *Admissions) Edit(h HospitalAdmission) error {
+// Edit saves h when it is still at h.Version. It returns db.ErrVersionConflict when the
+// hospital admission was saved by someone else, or deleted.
+func (a *Admissions) Edit(ctx context.Context, h HospitalAdmission) error {
+	ctx, cancel := a.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	UPDATE hospital_admission 
-	SET date_admitted=$1, facility=$2, reason=$3, updated_at=$4, updated_by=$5 
-	WHERE id=$6;
+	SET date_admitted=$1, facility=$2, reason=$3, updated_at=$4, updated_by=$5, version=version+1
+	WHERE id=$6 AND version=$7 AND deleted_at IS NULL;
 `
-	_, err := a.Exec(stmt, h.DateAdmitted, h.Facility, h.Reason, h.UpdatedAt, h.UpdatedBy, h.Id)
+	err := a.Audited(ctx, "hospital_admission", h.Id, db.AuditUpdate, db.AuditUser(h.UpdatedBy), func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, stmt, h.DateAdmitted, h.Facility, h.Reason, h.UpdatedAt, h.UpdatedBy, h.Id, h.Version)
+		if err != nil {
+			return err
+		}
+		return db.CheckVersion(res)
+	})
 	if err != nil {
 		return fmt.Errorf("error updating a hospital admission in the database: %w", err)
 	}
 	return nil
 }
+
+// Delete soft-deletes a hospital admission that was recorded by mistake. It returns false when
+// the hospital admission does not exist or was already deleted.
+func (a *Admissions) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	return a.EmtctDb.SoftDelete(ctx, "hospital_admission", id, user, reason)
+}
+
+// Restore undoes the deletion of a hospital admission. It returns false when the hospital
+// admission does not exist or is not deleted.
+func (a *Admissions) Restore(ctx context.Context, id, user string) (bool, error) {
+	return a.EmtctDb.Restore(ctx, "hospital_admission", id, user)
+}
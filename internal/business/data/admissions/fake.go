@@ -0,0 +1,104 @@
+package admissions
+
+import (
+	"context"
+	"sync"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu         sync.Mutex
+	admissions map[string]HospitalAdmission
+	deleted    map[string]HospitalAdmission
+}
+
+func NewFake(hs ...HospitalAdmission) *Fake {
+	f := &Fake{admissions: make(map[string]HospitalAdmission), deleted: make(map[string]HospitalAdmission)}
+	for _, h := range hs {
+		f.admissions[h.Id] = h
+	}
+	return f
+}
+
+func (f *Fake) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]HospitalAdmission, paging.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var hs []HospitalAdmission
+	for _, h := range f.admissions {
+		if h.PatientId == patientId {
+			hs = append(hs, h)
+		}
+	}
+	keys := make([]paging.Cursor, len(hs))
+	for i, h := range hs {
+		keys[i] = paging.Cursor{Date: h.DateAdmitted, Id: h.Id}
+	}
+	idx, info := q.Select(keys)
+	var page []HospitalAdmission
+	for _, i := range idx {
+		page = append(page, hs[i])
+	}
+	return page, info, nil
+}
+
+func (f *Fake) FindById(ctx context.Context, id string) (*HospitalAdmission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h, ok := f.admissions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &h, nil
+}
+
+func (f *Fake) Create(ctx context.Context, h HospitalAdmission) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.admissions[h.Id] = h
+	return nil
+}
+
+func (f *Fake) Edit(ctx context.Context, h HospitalAdmission) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old, ok := f.admissions[h.Id]
+	if !ok || old.Version != h.Version {
+		return db.ErrVersionConflict
+	}
+	old.DateAdmitted = h.DateAdmitted
+	old.Facility = h.Facility
+	old.Reason = h.Reason
+	old.UpdatedAt = h.UpdatedAt
+	old.UpdatedBy = h.UpdatedBy
+	old.Version++
+	f.admissions[h.Id] = old
+	return nil
+}
+
+// Delete hides the hospital admission from the Find methods until it is restored.
+func (f *Fake) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.admissions[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.admissions, id)
+	f.deleted[id] = v
+	return true, nil
+}
+
+func (f *Fake) Restore(ctx context.Context, id, user string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.deleted[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.deleted, id)
+	f.admissions[id] = v
+	return true, nil
+}
@@ -0,0 +1,54 @@
+package admissions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestAdmissionsIntegration(t *testing.T) {
+	emtct := dbtest.OpenEmtct(t)
+	dbtest.Load(t, emtct.DB, "emtct_patients")
+	a := New(emtct)
+	ctx := context.Background()
+
+	h := HospitalAdmission{
+		Id:             "a1",
+		PatientId:      100,
+		MchEncounterId: 1000,
+		DateAdmitted:   time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		Facility:       "KHMH",
+		Reason:         "Fever",
+		CreatedAt:      time.Now(),
+		CreatedBy:      "nurse@example.com",
+		Version:        1,
+	}
+	if err := a.Create(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	user := "doctor@example.com"
+	h.Reason, h.UpdatedAt, h.UpdatedBy = "Malaria", &now, &user
+	if err := a.Edit(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := a.FindById(ctx, "a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || saved.Reason != "Malaria" || saved.Facility != "KHMH" || saved.UpdatedBy == nil {
+		t.Errorf("want: the edited admission got: %+v", saved)
+	}
+	all, _, err := a.FindByPatientId(ctx, 100, paging.Query{})
+	if err != nil || len(all) != 1 {
+		t.Errorf("want: 1 admission of patient 100 got: %d, %v", len(all), err)
+	}
+	missing, err := a.FindById(ctx, "missing")
+	if err != nil || missing != nil {
+		t.Errorf("want: no admission got: %+v, %v", missing, err)
+	}
+}
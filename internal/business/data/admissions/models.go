@@ -1,16 +1,17 @@
 package admissions
 
 import (
-	"database/sql"
 	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type Admissions struct {
-	*sql.DB
+	*db.EmtctDb
 }
 
-func New(db *sql.DB) Admissions {
-	return Admissions{db}
+func New(emtctDb *db.EmtctDb) Admissions {
+	return Admissions{emtctDb}
 }
 
 type HospitalAdmission struct {
@@ -24,4 +25,5 @@ type HospitalAdmission struct {
 	UpdatedAt      *time.Time `json:"updatedAt"`
 	CreatedBy      string     `json:"createdBy"`
 	UpdatedBy      *string    `json:"updatedBy"`
+	Version        int        `json:"version"`
 }
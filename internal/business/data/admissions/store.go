@@ -0,0 +1,22 @@
+package admissions
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+)
+
+// Store is implemented by *Admissions and by Fake.
+type Store interface {
+	FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]HospitalAdmission, paging.Info, error)
+	FindById(ctx context.Context, id string) (*HospitalAdmission, error)
+	Create(ctx context.Context, h HospitalAdmission) error
+	Edit(ctx context.Context, h HospitalAdmission) error
+	Delete(ctx context.Context, id, user, reason string) (bool, error)
+	Restore(ctx context.Context, id, user string) (bool, error)
+}
+
+var (
+	_ Store = (*Admissions)(nil)
+	_ Store = (*Fake)(nil)
+)
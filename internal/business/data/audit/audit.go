@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+)
+
+// History returns the writes to the record of entityType with the given id, oldest first.
+func (d *AuditLog) History(ctx context.Context, entityType, entityId string) ([]Entry, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT id, entity_type, entity_id, action, user_email, created_at, before, after
+	FROM audit_log
+	WHERE entity_type=$1 AND entity_id=$2
+	ORDER BY created_at, id`
+	rows, err := d.QueryContext(ctx, stmt, entityType, entityId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying the history of %s %s: %w", entityType, entityId, err)
+	}
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var before, after []byte
+		err := rows.Scan(&e.Id, &e.EntityType, &e.EntityId, &e.Action, &e.User, &e.CreatedAt, &before, &after)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning the history of %s %s: %w", entityType, entityId, err)
+		}
+		e.Before, e.After = nullJson(before), nullJson(after)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading the history of %s %s: %w", entityType, entityId, err)
+	}
+	return entries, nil
+}
+
+// nullJson returns the JSON null for a column that is NULL.
+func nullJson(b []byte) []byte {
+	if b == nil {
+		return []byte("null")
+	}
+	return b
+}
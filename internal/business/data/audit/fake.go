@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewFake returns a Fake with the given entries, which must be oldest first.
+func NewFake(es ...Entry) *Fake {
+	return &Fake{entries: es}
+}
+
+func (f *Fake) History(ctx context.Context, entityType, entityId string) ([]Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var entries []Entry
+	for _, e := range f.entries {
+		if e.EntityType == entityType && e.EntityId == entityId {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/homeVisits"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestAuditLogIntegration(t *testing.T) {
+	emtct := dbtest.OpenEmtct(t)
+	visits := homeVisits.New(emtct)
+	d := New(emtct)
+	ctx := context.Background()
+
+	v := homeVisits.HomeVisit{
+		Id:          "v1",
+		PatientId:   100,
+		Reason:      "Follow up",
+		DateOfVisit: time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		CreatedAt:   time.Now(),
+		CreatedBy:   "nurse@example.com",
+		Version:     1,
+	}
+	if err := visits.Create(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	doctor := "doctor@example.com"
+	v.Comments, v.UpdatedBy = "Taking her ARVs", &doctor
+	if _, err := visits.Edit(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := visits.Delete(ctx, "v1", doctor, "wrong patient"); err != nil {
+		t.Fatal(err)
+	}
+	// Deleting it again changes nothing, so it is not recorded.
+	if _, err := visits.Delete(ctx, "v1", doctor, "wrong patient"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := visits.Restore(ctx, "v1", "admin@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := d.History(ctx, "home_visit", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action+" by "+e.User)
+	}
+	want := []string{
+		"create by nurse@example.com",
+		"update by doctor@example.com",
+		"delete by doctor@example.com",
+		"restore by admin@example.com",
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("want: %v got: %v", want, actions)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("want: %s got: %s", want[i], actions[i])
+		}
+	}
+
+	if string(entries[0].Before) != "null" {
+		t.Errorf("want: no row before the create got: %s", entries[0].Before)
+	}
+	var before, after struct {
+		Comments string `json:"comments"`
+	}
+	if err := json.Unmarshal(entries[1].Before, &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(entries[1].After, &after); err != nil {
+		t.Fatal(err)
+	}
+	if before.Comments != "" || after.Comments != "Taking her ARVs" {
+		t.Errorf("want: the comments before and after the update got: %q, %q", before.Comments, after.Comments)
+	}
+}
@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+type AuditLog struct {
+	*db.EmtctDb
+}
+
+func New(emtctDb *db.EmtctDb) AuditLog {
+	return AuditLog{emtctDb}
+}
+
+// Entry is a write to an EMTCT record. The entity type is the table of the record. Before and
+// After are the row as JSON; Before is null when the record was created.
+type Entry struct {
+	Id         int64           `json:"id"`
+	EntityType string          `json:"entityType"`
+	EntityId   string          `json:"entityId"`
+	Action     string          `json:"action"`
+	User       string          `json:"user"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+}
@@ -0,0 +1,13 @@
+package audit
+
+import "context"
+
+// Store is implemented by *AuditLog and by Fake.
+type Store interface {
+	History(ctx context.Context, entityType, entityId string) ([]Entry, error)
+}
+
+var (
+	_ Store = (*AuditLog)(nil)
+	_ Store = (*Fake)(nil)
+)
@@ -1,44 +1,62 @@
 package contactTracing
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
-func (d *ContactTracings) Create(c ContactTracing) error {
+func (d *ContactTracings) Create(ctx context.Context, c ContactTracing) error {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	INSERT INTO 
 	    contact_tracing (id, patient_id, test, test_result, comments, date, created_by, created_at)
 	VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
 `
-	_, err := d.Exec(stmt,
-		c.Id,
-		c.PatientId,
-		c.Test,
-		c.TestResult,
-		c.Comments,
-		c.Date,
-		c.CreatedBy,
-		c.CreatedAt,
-	)
+	err := d.Audited(ctx, "contact_tracing", c.Id, db.AuditCreate, c.CreatedBy, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt,
+			c.Id,
+			c.PatientId,
+			c.Test,
+			c.TestResult,
+			c.Comments,
+			c.Date,
+			c.CreatedBy,
+			c.CreatedAt,
+		)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error inserting contact tracing to the database: %+v", err)
+		return fmt.Errorf("error inserting contact tracing to the database: %w", err)
 	}
 	return nil
 }
 
-func (d *ContactTracings) FindByPatientId(patientId int) ([]ContactTracing, error) {
+// FindByPatientId returns the page q of the contact tracings of a patient, by date.
+func (d *ContactTracings) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]ContactTracing, paging.Info, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	filter, args := q.Filter("date", 1)
+	var total int
+	count := `SELECT count(*) FROM contact_tracing WHERE patient_id=$1 AND deleted_at IS NULL` + filter
+	if err := d.QueryRowContext(ctx, count, append([]interface{}{patientId}, args...)...).Scan(&total); err != nil {
+		return nil, paging.Info{}, fmt.Errorf("error counting the contact tracings: %w", err)
+	}
+	seek, args := q.Seek("date", "id", 1)
 	stmt := `
 	SELECT 
-	       id, patient_id, test, test_result, comments, date, created_by, created_at, updated_by, updated_at
+	       id, patient_id, test, test_result, comments, date, created_by, created_at, updated_by, updated_at, version
 	FROM contact_tracing
-	WHERE patient_id=$1;
-`
-	rows, err := d.Query(stmt, patientId)
-	defer rows.Close()
+	WHERE patient_id=$1 AND deleted_at IS NULL` + seek + q.OrderBy("date", "id")
+	rows, err := d.QueryContext(ctx, stmt, append([]interface{}{patientId}, args...)...)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving contact tracing from database: %+v", err)
+		return nil, paging.Info{}, fmt.Errorf("error retrieving contact tracing from database: %w", err)
 	}
+	defer rows.Close()
 	var contacts []ContactTracing
 	for rows.Next() {
 		var c ContactTracing
@@ -53,36 +71,98 @@ func (d *ContactTracings) FindByPatientId(patientId int) ([]ContactTracing, erro
 			&c.CreatedBy,
 			&c.CreatedAt,
 			&updatedBy,
-			&c.UpdatedAt)
+			&c.UpdatedAt,
+			&c.Version)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning contact tracing record: %+v", err)
+			return nil, paging.Info{}, fmt.Errorf("error scanning contact tracing record: %w", err)
 		}
 		if updatedBy.Valid {
 			c.UpdatedBy = updatedBy.String
 		}
 		contacts = append(contacts, c)
 	}
-	return contacts, nil
+	info, n := q.NewInfo(total, len(contacts), func(i int) paging.Cursor {
+		return paging.Cursor{Date: contacts[i].Date, Id: contacts[i].Id}
+	})
+	return contacts[:n], info, nil
+}
+
+// FindById returns nil when the contact tracing does not exist.
+func (d *ContactTracings) FindById(ctx context.Context, id string) (*ContactTracing, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT 
+	       id, patient_id, test, test_result, comments, date, created_by, created_at, updated_by, updated_at, version
+	FROM contact_tracing
+	WHERE id=$1 AND deleted_at IS NULL;
+`
+	var c ContactTracing
+	var updatedBy sql.NullString
+	err := d.QueryRowContext(ctx, stmt, id).Scan(
+		&c.Id,
+		&c.PatientId,
+		&c.Test,
+		&c.TestResult,
+		&c.Comments,
+		&c.Date,
+		&c.CreatedBy,
+		&c.CreatedAt,
+		&updatedBy,
+		&c.UpdatedAt,
+		&c.Version)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+		c.UpdatedBy = updatedBy.String
+		return &c, nil
+	default:
+		return nil, fmt.Errorf("error retrieving contact tracing from database: %w", err)
+	}
 }
 
-func (d *ContactTracings) Edit(c ContactTracing) error {
+// Edit saves c when it is still at c.Version. It returns db.ErrVersionConflict when the
+// contact tracing was saved by someone else, or deleted.
+func (d *ContactTracings) Edit(ctx context.Context, c ContactTracing) error {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	UPDATE 
-	    contact_tracing SET test=$1, test_result=$2, comments=$3, date=$4, updated_by=$5, updated_at=$6
-	WHERE id = $7
+	    contact_tracing SET test=$1, test_result=$2, comments=$3, date=$4, updated_by=$5, updated_at=$6, version=version+1
+	WHERE id = $7 AND version = $8 AND deleted_at IS NULL
 `
 
-	_, err := d.Exec(stmt,
-		c.Test,
-		c.TestResult,
-		c.Comments,
-		c.Date,
-		c.UpdatedBy,
-		c.UpdatedAt,
-		c.Id,
-	)
+	err := d.Audited(ctx, "contact_tracing", c.Id, db.AuditUpdate, c.UpdatedBy, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, stmt,
+			c.Test,
+			c.TestResult,
+			c.Comments,
+			c.Date,
+			c.UpdatedBy,
+			c.UpdatedAt,
+			c.Id,
+			c.Version,
+		)
+		if err != nil {
+			return err
+		}
+		return db.CheckVersion(res)
+	})
 	if err != nil {
-		return fmt.Errorf("error updating contact tracing in database: %+v", err)
+		return fmt.Errorf("error updating contact tracing in database: %w", err)
 	}
 	return nil
 }
+
+// Delete soft-deletes a contact tracing that was recorded by mistake. It returns false when
+// the contact tracing does not exist or was already deleted.
+func (d *ContactTracings) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	return d.EmtctDb.SoftDelete(ctx, "contact_tracing", id, user, reason)
+}
+
+// Restore undoes the deletion of a contact tracing. It returns false when the contact tracing
+// does not exist or is not deleted.
+func (d *ContactTracings) Restore(ctx context.Context, id, user string) (bool, error) {
+	return d.EmtctDb.Restore(ctx, "contact_tracing", id, user)
+}
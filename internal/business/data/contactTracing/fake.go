@@ -0,0 +1,105 @@
+package contactTracing
+
+import (
+	"context"
+	"sync"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu       sync.Mutex
+	contacts map[string]ContactTracing
+	deleted  map[string]ContactTracing
+}
+
+func NewFake(cs ...ContactTracing) *Fake {
+	f := &Fake{contacts: make(map[string]ContactTracing), deleted: make(map[string]ContactTracing)}
+	for _, c := range cs {
+		f.contacts[c.Id] = c
+	}
+	return f
+}
+
+func (f *Fake) Create(ctx context.Context, c ContactTracing) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contacts[c.Id] = c
+	return nil
+}
+
+func (f *Fake) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]ContactTracing, paging.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var cs []ContactTracing
+	for _, c := range f.contacts {
+		if c.PatientId == patientId {
+			cs = append(cs, c)
+		}
+	}
+	keys := make([]paging.Cursor, len(cs))
+	for i, c := range cs {
+		keys[i] = paging.Cursor{Date: c.Date, Id: c.Id}
+	}
+	idx, info := q.Select(keys)
+	var page []ContactTracing
+	for _, i := range idx {
+		page = append(page, cs[i])
+	}
+	return page, info, nil
+}
+
+func (f *Fake) FindById(ctx context.Context, id string) (*ContactTracing, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.contacts[id]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (f *Fake) Edit(ctx context.Context, c ContactTracing) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old, ok := f.contacts[c.Id]
+	if !ok || old.Version != c.Version {
+		return db.ErrVersionConflict
+	}
+	old.Test = c.Test
+	old.TestResult = c.TestResult
+	old.Comments = c.Comments
+	old.Date = c.Date
+	old.UpdatedBy = c.UpdatedBy
+	old.UpdatedAt = c.UpdatedAt
+	old.Version++
+	f.contacts[c.Id] = old
+	return nil
+}
+
+// Delete hides the contact tracing from the Find methods until it is restored.
+func (f *Fake) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.contacts[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.contacts, id)
+	f.deleted[id] = v
+	return true, nil
+}
+
+func (f *Fake) Restore(ctx context.Context, id, user string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.deleted[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.deleted, id)
+	f.contacts[id] = v
+	return true, nil
+}
@@ -0,0 +1,42 @@
+package contactTracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestContactTracingsIntegration(t *testing.T) {
+	d := New(dbtest.OpenEmtct(t))
+	ctx := context.Background()
+
+	c := ContactTracing{
+		Id:        "c1",
+		PatientId: 100,
+		Test:      "RPR",
+		Comments:  "Partner",
+		Date:      time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		CreatedBy: "nurse@example.com",
+		CreatedAt: time.Now(),
+		Version:   1,
+	}
+	if err := d.Create(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	c.TestResult, c.UpdatedBy, c.UpdatedAt = "Negative", "doctor@example.com", &now
+	if err := d.Edit(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	contacts, _, err := d.FindByPatientId(ctx, 100, paging.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contacts) != 1 || contacts[0].TestResult != "Negative" || contacts[0].UpdatedBy != "doctor@example.com" {
+		t.Errorf("want: the edited contact tracing got: %+v", contacts)
+	}
+}
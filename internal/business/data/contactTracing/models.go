@@ -1,16 +1,17 @@
 package contactTracing
 
 import (
-	"database/sql"
 	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type ContactTracings struct {
-	*sql.DB
+	*db.EmtctDb
 }
 
-func New(db *sql.DB) ContactTracings {
-	return ContactTracings{db}
+func New(emtctDb *db.EmtctDb) ContactTracings {
+	return ContactTracings{emtctDb}
 }
 
 type ContactTracing struct {
@@ -23,5 +24,6 @@ type ContactTracing struct {
 	CreatedBy  string     `json:"createdBy"`
 	CreatedAt  time.Time  `json:"createdAt"`
 	UpdatedBy  string     `json:"updatedBy"`
+	Version    int        `json:"version"`
 	UpdatedAt  *time.Time `json:"updatedAt"`
 }
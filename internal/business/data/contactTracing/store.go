@@ -0,0 +1,22 @@
+package contactTracing
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+)
+
+// Store is implemented by *ContactTracings and by Fake.
+type Store interface {
+	Create(ctx context.Context, c ContactTracing) error
+	FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]ContactTracing, paging.Info, error)
+	FindById(ctx context.Context, id string) (*ContactTracing, error)
+	Edit(ctx context.Context, c ContactTracing) error
+	Delete(ctx context.Context, id, user, reason string) (bool, error)
+	Restore(ctx context.Context, id, user string) (bool, error)
+}
+
+var (
+	_ Store = (*ContactTracings)(nil)
+	_ Store = (*Fake)(nil)
+)
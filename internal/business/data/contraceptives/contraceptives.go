@@ -1,46 +1,67 @@
 package contraceptives
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
-func (d *Contraceptives) Create(c ContraceptiveUsed) error {
+func (d *Contraceptives) Create(ctx context.Context, c ContraceptiveUsed) error {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 		INSERT INTO contraceptive_used 
     		(id, patient_id, contraceptive, comments, date_used, created_by, created_at, mch_encounter_id) 
 		VALUES($1, $2, $3, $4, $5, $6, $7, $8)`
-	_, err := d.Exec(stmt, c.Id, c.PatientId, c.Contraceptive, c.Comments, c.DateUsed, c.CreatedBy, c.CreatedAt, c.MchEncounterId)
+	err := d.Audited(ctx, "contraceptive_used", c.Id, db.AuditCreate, c.CreatedBy, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt, c.Id, c.PatientId, c.Contraceptive, c.Comments, c.DateUsed, c.CreatedBy, c.CreatedAt, c.MchEncounterId)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error inserting a new contraceptive into the database: %+v", err)
+		return fmt.Errorf("error inserting a new contraceptive into the database: %w", err)
 	}
 	return nil
 }
 
-func (d *Contraceptives) Edit(c ContraceptiveUsed) error {
+// Edit saves c when it is still at c.Version. It returns db.ErrVersionConflict when the
+// contraceptive was saved by someone else, or deleted.
+func (d *Contraceptives) Edit(ctx context.Context, c ContraceptiveUsed) error {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	UPDATE contraceptive_used 
-	SET contraceptive=$1, comments=$2, date_used=$3, updated_by=$4, updated_at=$5 
-	WHERE id=$6;
+	SET contraceptive=$1, comments=$2, date_used=$3, updated_by=$4, updated_at=$5, version=version+1
+	WHERE id=$6 AND version=$7 AND deleted_at IS NULL;
 `
-	_, err := d.Exec(stmt, c.Contraceptive, c.Comments, c.DateUsed, c.UpdatedBy, c.UpdatedAt, c.Id)
+	err := d.Audited(ctx, "contraceptive_used", c.Id, db.AuditUpdate, db.AuditUser(c.UpdatedBy), func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, stmt, c.Contraceptive, c.Comments, c.DateUsed, c.UpdatedBy, c.UpdatedAt, c.Id, c.Version)
+		if err != nil {
+			return err
+		}
+		return db.CheckVersion(res)
+	})
 	if err != nil {
-		return fmt.Errorf("error updating contraceptive in the database: %+v", err)
+		return fmt.Errorf("error updating contraceptive in the database: %w", err)
 	}
 	return nil
 }
 
-func (d *Contraceptives) FindById(id string) (*ContraceptiveUsed, error) {
+func (d *Contraceptives) FindById(ctx context.Context, id string) (*ContraceptiveUsed, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 		SELECT 
 		       id, patient_id, contraceptive, comments, created_at, created_by, updated_at, 
-		       updated_by, mch_encounter_id 
+		       updated_by, mch_encounter_id, version
 		FROM contraceptive_used 
 		WHERE 
-		      id=$1;
+		      id=$1 AND deleted_at IS NULL;
 `
 	var contraceptive ContraceptiveUsed
-	row := d.DB.QueryRow(stmt, id)
+	row := d.QueryRowContext(ctx, stmt, id)
 	err := row.Scan(
 		&contraceptive.Id,
 		&contraceptive.PatientId,
@@ -50,30 +71,41 @@ func (d *Contraceptives) FindById(id string) (*ContraceptiveUsed, error) {
 		&contraceptive.CreatedBy,
 		&contraceptive.UpdatedAt,
 		&contraceptive.UpdatedBy,
-		&contraceptive.MchEncounterId)
+		&contraceptive.MchEncounterId,
+		&contraceptive.Version)
 	switch err {
 	case sql.ErrNoRows:
 		return nil, nil
 	case nil:
 		return &contraceptive, nil
 	default:
-		return nil, fmt.Errorf("error retrieving contraceptive from database: %+v", err)
+		return nil, fmt.Errorf("error retrieving contraceptive from database: %w", err)
 	}
 }
 
-func (d *Contraceptives) FindByPatientId(patientId int) ([]ContraceptiveUsed, error) {
+// FindByPatientId returns the page q of the contraceptives used by a patient, by date used.
+func (d *Contraceptives) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]ContraceptiveUsed, paging.Info, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	filter, args := q.Filter("date_used", 1)
+	var total int
+	count := `SELECT count(*) FROM contraceptive_used WHERE patient_id=$1 AND deleted_at IS NULL` + filter
+	if err := d.QueryRowContext(ctx, count, append([]interface{}{patientId}, args...)...).Scan(&total); err != nil {
+		return nil, paging.Info{}, fmt.Errorf("error counting the contraceptives: %w", err)
+	}
+	seek, args := q.Seek("date_used", "id", 1)
 	stmt := `
 		SELECT 
 		       id, patient_id, contraceptive, comments, date_used, created_at, created_by, 
-		       updated_at, updated_by, mch_encounter_id 
+		       updated_at, updated_by, mch_encounter_id, version
 		FROM 
 		     contraceptive_used 
-		WHERE patient_id=$1`
+		WHERE patient_id=$1 AND deleted_at IS NULL` + seek + q.OrderBy("date_used", "id")
 	var contraceptives []ContraceptiveUsed
 
-	rows, err := d.Query(stmt, patientId)
+	rows, err := d.QueryContext(ctx, stmt, append([]interface{}{patientId}, args...)...)
 	if err != nil {
-		return contraceptives, fmt.Errorf("error when executing query to retrieve contraceptives by patient id: %+v", err)
+		return nil, paging.Info{}, fmt.Errorf("error when executing query to retrieve contraceptives by patient id: %w", err)
 	}
 	defer rows.Close()
 
@@ -89,12 +121,28 @@ func (d *Contraceptives) FindByPatientId(patientId int) ([]ContraceptiveUsed, er
 			&c.CreatedBy,
 			&c.UpdatedAt,
 			&c.UpdatedBy,
-			&c.MchEncounterId)
+			&c.MchEncounterId,
+			&c.Version)
 		if err != nil {
-			return contraceptives, fmt.Errorf("error scanning contraceptive result from the database: %+v", err)
+			return nil, paging.Info{}, fmt.Errorf("error scanning contraceptive result from the database: %w", err)
 		}
 		contraceptives = append(contraceptives, c)
 	}
 
-	return contraceptives, nil
+	info, n := q.NewInfo(total, len(contraceptives), func(i int) paging.Cursor {
+		return paging.Cursor{Date: contraceptives[i].DateUsed, Id: contraceptives[i].Id}
+	})
+	return contraceptives[:n], info, nil
+}
+
+// Delete soft-deletes a contraceptive that was recorded by mistake. It returns false when the
+// contraceptive does not exist or was already deleted.
+func (d *Contraceptives) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	return d.EmtctDb.SoftDelete(ctx, "contraceptive_used", id, user, reason)
+}
+
+// Restore undoes the deletion of a contraceptive. It returns false when the contraceptive does
+// not exist or is not deleted.
+func (d *Contraceptives) Restore(ctx context.Context, id, user string) (bool, error) {
+	return d.EmtctDb.Restore(ctx, "contraceptive_used", id, user)
 }
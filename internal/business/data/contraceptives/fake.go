@@ -0,0 +1,104 @@
+package contraceptives
+
+import (
+	"context"
+	"sync"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu             sync.Mutex
+	contraceptives map[string]ContraceptiveUsed
+	deleted        map[string]ContraceptiveUsed
+}
+
+func NewFake(cs ...ContraceptiveUsed) *Fake {
+	f := &Fake{contraceptives: make(map[string]ContraceptiveUsed), deleted: make(map[string]ContraceptiveUsed)}
+	for _, c := range cs {
+		f.contraceptives[c.Id] = c
+	}
+	return f
+}
+
+func (f *Fake) Create(ctx context.Context, c ContraceptiveUsed) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contraceptives[c.Id] = c
+	return nil
+}
+
+func (f *Fake) Edit(ctx context.Context, c ContraceptiveUsed) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old, ok := f.contraceptives[c.Id]
+	if !ok || old.Version != c.Version {
+		return db.ErrVersionConflict
+	}
+	old.Contraceptive = c.Contraceptive
+	old.Comments = c.Comments
+	old.DateUsed = c.DateUsed
+	old.UpdatedBy = c.UpdatedBy
+	old.UpdatedAt = c.UpdatedAt
+	old.Version++
+	f.contraceptives[c.Id] = old
+	return nil
+}
+
+func (f *Fake) FindById(ctx context.Context, id string) (*ContraceptiveUsed, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.contraceptives[id]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (f *Fake) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]ContraceptiveUsed, paging.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var cs []ContraceptiveUsed
+	for _, c := range f.contraceptives {
+		if c.PatientId == patientId {
+			cs = append(cs, c)
+		}
+	}
+	keys := make([]paging.Cursor, len(cs))
+	for i, c := range cs {
+		keys[i] = paging.Cursor{Date: c.DateUsed, Id: c.Id}
+	}
+	idx, info := q.Select(keys)
+	var page []ContraceptiveUsed
+	for _, i := range idx {
+		page = append(page, cs[i])
+	}
+	return page, info, nil
+}
+
+// Delete hides the contraceptive from the Find methods until it is restored.
+func (f *Fake) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.contraceptives[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.contraceptives, id)
+	f.deleted[id] = v
+	return true, nil
+}
+
+func (f *Fake) Restore(ctx context.Context, id, user string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.deleted[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.deleted, id)
+	f.contraceptives[id] = v
+	return true, nil
+}
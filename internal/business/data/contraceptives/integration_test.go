@@ -0,0 +1,49 @@
+package contraceptives
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestContraceptivesIntegration(t *testing.T) {
+	emtct := dbtest.OpenEmtct(t)
+	dbtest.Load(t, emtct.DB, "emtct_patients")
+	d := New(emtct)
+	ctx := context.Background()
+
+	c := ContraceptiveUsed{
+		Id:             "c1",
+		PatientId:      100,
+		MchEncounterId: 1000,
+		Contraceptive:  "Depo-Provera",
+		DateUsed:       time.Date(2020, 11, 20, 0, 0, 0, 0, time.UTC),
+		CreatedAt:      time.Now(),
+		CreatedBy:      "nurse@example.com",
+		Version:        1,
+	}
+	if err := d.Create(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	user := "doctor@example.com"
+	c.Contraceptive, c.UpdatedAt, c.UpdatedBy = "Condoms", &now, &user
+	if err := d.Edit(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := d.FindById(ctx, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || saved.Contraceptive != "Condoms" || saved.MchEncounterId != 1000 {
+		t.Errorf("want: the edited contraceptive got: %+v", saved)
+	}
+	all, _, err := d.FindByPatientId(ctx, 100, paging.Query{})
+	if err != nil || len(all) != 1 {
+		t.Errorf("want: 1 contraceptive of patient 100 got: %d, %v", len(all), err)
+	}
+}
@@ -1,16 +1,17 @@
 package contraceptives
 
 import (
-	"database/sql"
 	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type Contraceptives struct {
-	*sql.DB
+	*db.EmtctDb
 }
 
-func New(db *sql.DB) Contraceptives {
-	return Contraceptives{db}
+func New(emtctDb *db.EmtctDb) Contraceptives {
+	return Contraceptives{emtctDb}
 }
 
 type ContraceptiveUsed struct {
@@ -24,4 +25,5 @@ type ContraceptiveUsed struct {
 	UpdatedAt      *time.Time `json:"updatedAt"`
 	CreatedBy      string     `json:"createdBy"`
 	UpdatedBy      *string    `json:"updatedBy"`
+	Version        int        `json:"version"`
 }
@@ -0,0 +1,22 @@
+package contraceptives
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+)
+
+// Store is implemented by *Contraceptives and by Fake.
+type Store interface {
+	Create(ctx context.Context, c ContraceptiveUsed) error
+	Edit(ctx context.Context, c ContraceptiveUsed) error
+	FindById(ctx context.Context, id string) (*ContraceptiveUsed, error)
+	FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]ContraceptiveUsed, paging.Info, error)
+	Delete(ctx context.Context, id, user, reason string) (bool, error)
+	Restore(ctx context.Context, id, user string) (bool, error)
+}
+
+var (
+	_ Store = (*Contraceptives)(nil)
+	_ Store = (*Fake)(nil)
+)
@@ -0,0 +1,79 @@
+package etlRuns
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu     sync.Mutex
+	runs   map[string]Run
+	errors map[string][]RecordError
+}
+
+func NewFake(rs ...Run) *Fake {
+	f := &Fake{runs: make(map[string]Run), errors: make(map[string][]RecordError)}
+	for _, r := range rs {
+		f.runs[r.Id] = r
+	}
+	return f
+}
+
+func (f *Fake) Create(ctx context.Context, r Run) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runs[r.Id] = r
+	return nil
+}
+
+func (f *Fake) Finish(ctx context.Context, r Run) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runs[r.Id] = r
+	return nil
+}
+
+// FindLatest returns the most recent runs, newest first.
+func (f *Fake) FindLatest(ctx context.Context, limit int) ([]Run, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var runs []Run
+	for _, r := range f.runs {
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func (f *Fake) FindById(ctx context.Context, id string) (*Run, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.runs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (f *Fake) SaveErrors(ctx context.Context, runId string, errs []RecordError) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for _, e := range errs {
+		e.CreatedAt = now
+		f.errors[runId] = append(f.errors[runId], e)
+	}
+	return nil
+}
+
+func (f *Fake) FindErrors(ctx context.Context, runId string) ([]RecordError, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.errors[runId], nil
+}
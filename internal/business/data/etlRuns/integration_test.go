@@ -0,0 +1,54 @@
+package etlRuns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestEtlRunsIntegration(t *testing.T) {
+	d := New(dbtest.OpenEmtct(t))
+	ctx := context.Background()
+
+	started := time.Now().Add(-time.Minute)
+	r := Run{Id: "r1", Name: "pregnancies", Trigger: Scheduled, StartedAt: started}
+	if err := d.Create(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Create(ctx, Run{Id: "r0", Name: "pregnancies", Trigger: Manual, StartedAt: started.Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	finished := time.Now()
+	duration := finished.Sub(started).Milliseconds()
+	r.RowsRead, r.RowsInserted, r.RowsFailed, r.FinishedAt, r.DurationMs = 3, 2, 1, &finished, &duration
+	if err := d.Finish(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SaveErrors(ctx, "r1", []RecordError{{RecordId: 7, Error: "no patient"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := d.FindById(ctx, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || saved.RowsInserted != 2 || saved.RowsFailed != 1 || saved.FinishedAt == nil {
+		t.Errorf("want: the finished run got: %+v", saved)
+	}
+	runs, err := d.FindLatest(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Id != "r1" {
+		t.Errorf("want: the runs newest first got: %+v", runs)
+	}
+	errs, err := d.FindErrors(ctx, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].RecordId != 7 {
+		t.Errorf("want: the error of record 7 got: %+v", errs)
+	}
+}
@@ -0,0 +1,54 @@
+package etlRuns
+
+import (
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+type EtlRuns struct {
+	*db.EmtctDb
+}
+
+func New(emtctDb *db.EmtctDb) EtlRuns {
+	return EtlRuns{emtctDb}
+}
+
+// Trigger describes what started an etl run.
+type Trigger string
+
+const (
+	Manual    Trigger = "manual"
+	Scheduled Trigger = "scheduled"
+	// Cli runs are started from the etl command line tool.
+	Cli Trigger = "cli"
+)
+
+// Run is a single execution of an etl job. A run that has not finished yet has no
+// FinishedAt.
+type Run struct {
+	Id           string     `json:"id"`
+	Name         string     `json:"name"`
+	Trigger      Trigger    `json:"trigger"`
+	TriggeredBy  *string    `json:"triggeredBy"`
+	YearFrom     *int       `json:"yearFrom"`
+	YearTo       *int       `json:"yearTo"`
+	Since        *time.Time `json:"since"`
+	RowsRead     int        `json:"rowsRead"`
+	RowsInserted int        `json:"rowsInserted"`
+	RowsUpdated  int        `json:"rowsUpdated"`
+	RowsSkipped  int        `json:"rowsSkipped"`
+	RowsFailed   int        `json:"rowsFailed"`
+	StartedAt    time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt"`
+	DurationMs   *int64     `json:"durationMs"`
+	Error        *string    `json:"error"`
+}
+
+// RecordError is a source record that an etl run could not copy, e.g. an ACSIS pregnancy
+// without a patient. The run skips the record and carries on with the rest.
+type RecordError struct {
+	RecordId  int       `json:"recordId"`
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"createdAt"`
+}
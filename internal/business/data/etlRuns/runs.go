@@ -0,0 +1,146 @@
+package etlRuns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+func (d *EtlRuns) Create(ctx context.Context, r Run) error {
+	stmt := `
+	INSERT INTO etl_runs 
+	    (id, name, trigger_type, triggered_by, year_from, year_to, since, started_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8);
+`
+	_, err := d.ExecContext(ctx, stmt, r.Id, r.Name, r.Trigger, r.TriggeredBy, r.YearFrom, r.YearTo, r.Since, r.StartedAt)
+	if err != nil {
+		return fmt.Errorf("error inserting etl run into the database: %w", err)
+	}
+	return nil
+}
+
+// Finish saves the row counts, duration and error of a run once it has completed.
+func (d *EtlRuns) Finish(ctx context.Context, r Run) error {
+	stmt := `
+	UPDATE etl_runs 
+	SET since=$1, rows_read=$2, rows_inserted=$3, rows_updated=$4, rows_skipped=$5, rows_failed=$6, finished_at=$7,
+	    duration_ms=$8, error=$9
+	WHERE id=$10;
+`
+	_, err := d.ExecContext(ctx, stmt,
+		r.Since,
+		r.RowsRead,
+		r.RowsInserted,
+		r.RowsUpdated,
+		r.RowsSkipped,
+		r.RowsFailed,
+		r.FinishedAt,
+		r.DurationMs,
+		r.Error,
+		r.Id)
+	if err != nil {
+		return fmt.Errorf("error updating etl run in the database: %w", err)
+	}
+	return nil
+}
+
+const selectRun = `
+	SELECT id, name, trigger_type, triggered_by, year_from, year_to, since, rows_read, rows_inserted,
+	       rows_updated, rows_skipped, rows_failed, started_at, finished_at, duration_ms, error
+	FROM etl_runs`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRun(row scanner) (Run, error) {
+	var r Run
+	err := row.Scan(
+		&r.Id,
+		&r.Name,
+		&r.Trigger,
+		&r.TriggeredBy,
+		&r.YearFrom,
+		&r.YearTo,
+		&r.Since,
+		&r.RowsRead,
+		&r.RowsInserted,
+		&r.RowsUpdated,
+		&r.RowsSkipped,
+		&r.RowsFailed,
+		&r.StartedAt,
+		&r.FinishedAt,
+		&r.DurationMs,
+		&r.Error)
+	return r, err
+}
+
+// FindLatest returns the most recent runs, newest first.
+func (d *EtlRuns) FindLatest(ctx context.Context, limit int) ([]Run, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := selectRun + ` ORDER BY started_at DESC LIMIT $1;`
+	rows, err := d.QueryContext(ctx, stmt, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving etl runs from the database: %w", err)
+	}
+	defer rows.Close()
+	var runs []Run
+	for rows.Next() {
+		r, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning etl run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, nil
+}
+
+func (d *EtlRuns) FindById(ctx context.Context, id string) (*Run, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := selectRun + ` WHERE id=$1;`
+	r, err := scanRun(d.QueryRowContext(ctx, stmt, id))
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+		return &r, nil
+	default:
+		return nil, fmt.Errorf("error retrieving etl run from the database: %w", err)
+	}
+}
+
+// SaveErrors saves the records that a run skipped because they could not be copied.
+func (d *EtlRuns) SaveErrors(ctx context.Context, runId string, errs []RecordError) error {
+	stmt := `INSERT INTO etl_run_errors (etl_run_id, record_id, error, created_at) VALUES($1, $2, $3, $4)`
+	now := time.Now()
+	for _, e := range errs {
+		if _, err := d.ExecContext(ctx, stmt, runId, e.RecordId, e.Error, now); err != nil {
+			return fmt.Errorf("error saving the error of record %d of etl run %s: %w", e.RecordId, runId, err)
+		}
+	}
+	return nil
+}
+
+// FindErrors returns the records that a run skipped because they could not be copied.
+func (d *EtlRuns) FindErrors(ctx context.Context, runId string) ([]RecordError, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT record_id, error, created_at FROM etl_run_errors WHERE etl_run_id=$1 ORDER BY id`
+	rows, err := d.QueryContext(ctx, stmt, runId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving etl run errors from the database: %w", err)
+	}
+	defer rows.Close()
+	var errs []RecordError
+	for rows.Next() {
+		var e RecordError
+		if err := rows.Scan(&e.RecordId, &e.Error, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning etl run error: %w", err)
+		}
+		errs = append(errs, e)
+	}
+	return errs, nil
+}
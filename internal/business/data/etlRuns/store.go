@@ -0,0 +1,18 @@
+package etlRuns
+
+import "context"
+
+// Store is implemented by *EtlRuns and by Fake.
+type Store interface {
+	Create(ctx context.Context, r Run) error
+	Finish(ctx context.Context, r Run) error
+	FindLatest(ctx context.Context, limit int) ([]Run, error)
+	FindById(ctx context.Context, id string) (*Run, error)
+	SaveErrors(ctx context.Context, runId string, errs []RecordError) error
+	FindErrors(ctx context.Context, runId string) ([]RecordError, error)
+}
+
+var (
+	_ Store = (*EtlRuns)(nil)
+	_ Store = (*Fake)(nil)
+)
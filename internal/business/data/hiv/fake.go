@@ -0,0 +1,32 @@
+package hiv
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu        sync.Mutex
+	diagnoses []Diagnosis
+}
+
+func NewFake(ds ...Diagnosis) *Fake {
+	return &Fake{diagnoses: ds}
+}
+
+// FindHivDiagnoses returns the diagnoses of the patient, latest first.
+func (f *Fake) FindHivDiagnoses(ctx context.Context, patientId int) ([]Diagnosis, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ds []Diagnosis
+	for _, d := range f.diagnoses {
+		if d.PatientId == strconv.Itoa(patientId) {
+			ds = append(ds, d)
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i].Date.After(ds[j].Date) })
+	return ds, nil
+}
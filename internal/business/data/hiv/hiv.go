@@ -1,10 +1,13 @@
 package hiv
 
 import (
+	"context"
 	"fmt"
 )
 
-func (h *HIV) FindHivDiagnoses(patientId int) ([]Diagnosis, error) {
+func (h *HIV) FindHivDiagnoses(ctx context.Context, patientId int) ([]Diagnosis, error) {
+	ctx, cancel := h.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 		SELECT aaed.encounter_diagnosis_id,
        		e.patient_id,
@@ -19,16 +22,16 @@ func (h *HIV) FindHivDiagnoses(patientId int) ([]Diagnosis, error) {
 `
 
 	var diagnoses []Diagnosis
-	rows, err := h.AcsisDb.Query(stmt, patientId)
-	defer rows.Close()
+	rows, err := h.AcsisDb.QueryContext(ctx, stmt, patientId)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving the patient's hiv diagnoses from acsis: %+v", err)
+		return nil, fmt.Errorf("error retrieving the patient's hiv diagnoses from acsis: %w", err)
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var d Diagnosis
 		err := rows.Scan(&d.Id, &d.PatientId, &d.Name, &d.Date)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning patient's hiv diagnosis from acsis: %+v", err)
+			return nil, fmt.Errorf("error scanning patient's hiv diagnosis from acsis: %w", err)
 		}
 		diagnoses = append(diagnoses, d)
 	}
@@ -0,0 +1,30 @@
+package hiv
+
+import (
+	"context"
+	"testing"
+
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestFindHivDiagnosesIntegration(t *testing.T) {
+	acsis := dbtest.OpenAcsis(t)
+	dbtest.Load(t, acsis.DB, "mother_infant")
+	h := New(acsis)
+
+	diagnoses, err := h.FindHivDiagnoses(context.Background(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnoses) != 1 {
+		t.Fatalf("want: 1 hiv diagnosis got: %d", len(diagnoses))
+	}
+	if diagnoses[0].Name != "HIV disease" || diagnoses[0].PatientId != "100" {
+		t.Errorf("want: the HIV disease diagnosis of patient 100 got: %+v", diagnoses[0])
+	}
+
+	diagnoses, err = h.FindHivDiagnoses(context.Background(), 200)
+	if err != nil || len(diagnoses) != 0 {
+		t.Errorf("want: no hiv diagnoses for the infant got: %d, %v", len(diagnoses), err)
+	}
+}
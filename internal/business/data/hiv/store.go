@@ -0,0 +1,13 @@
+package hiv
+
+import "context"
+
+// Store is implemented by *HIV and by Fake.
+type Store interface {
+	FindHivDiagnoses(ctx context.Context, patientId int) ([]Diagnosis, error)
+}
+
+var (
+	_ Store = (*HIV)(nil)
+	_ Store = (*Fake)(nil)
+)
@@ -0,0 +1,169 @@
+package hivScreenings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// CopyResult counts the hiv screenings that were found in ACSIS and copied to emtct.
+type CopyResult struct {
+	InAcsis int
+	Copied  int
+	// AlreadyCopied are the screenings that were already in emtct, e.g. from an earlier copy.
+	AlreadyCopied int
+	// Conflicting are the ids of the screenings that are in emtct with other values than in
+	// ACSIS, e.g. because they were edited after an earlier copy. They are skipped, not copied.
+	Conflicting []string
+}
+
+// CopyFromAcsis copies the hiv screenings that older versions of the augmentor wrote to the
+// hiv_screening table of the ACSIS database into the emtct database. Screenings that are
+// already in emtct are left as they are, so the copy can be run more than once. The ones whose
+// copied columns differ from ACSIS are reported as Conflicting.
+//
+// The copy runs in one transaction, which is rolled back unless every screening in ACSIS
+// is in emtct afterwards. The rows in ACSIS are not touched.
+func CopyFromAcsis(ctx context.Context, acsis *db.AcsisDb, emtct *db.EmtctDb) (*CopyResult, error) {
+	var result CopyResult
+	var table *string
+	if err := acsis.QueryRowContext(ctx, `SELECT to_regclass('hiv_screening')::text`).Scan(&table); err != nil {
+		return nil, fmt.Errorf("error looking for the hiv_screening table in acsis: %w", err)
+	}
+	if table == nil {
+		return &result, nil
+	}
+
+	stmt := `
+	SELECT 
+		id, patient_id, mother_id, test_name, screening_date, date_sample_received_at_hq, sample_code,
+		date_sample_shipped, date_sample_taken, destination, date_result_received, result, date_result_shared, 
+		created_at, created_by, updated_at, updated_by, timely, due_date 
+	FROM hiv_screening`
+	rows, err := acsis.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("error querying the hiv screenings in acsis: %w", err)
+	}
+	defer rows.Close()
+	var screenings []HivScreening
+	for rows.Next() {
+		var s HivScreening
+		err := rows.Scan(
+			&s.Id,
+			&s.PatientId,
+			&s.MotherId,
+			&s.TestName,
+			&s.ScreeningDate,
+			&s.DateSampleReceivedAtHq,
+			&s.SampleCode,
+			&s.DateSampleShipped,
+			&s.DateSampleTaken,
+			&s.Destination,
+			&s.DateResultReceived,
+			&s.Result,
+			&s.DateResultShared,
+			&s.CreatedAt,
+			&s.CreatedBy,
+			&s.UpdatedAt,
+			&s.UpdatedBy,
+			&s.Timely,
+			&s.DueDate)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning hiv screening row from acsis: %w", err)
+		}
+		screenings = append(screenings, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading the hiv screenings in acsis: %w", err)
+	}
+	result.InAcsis = len(screenings)
+	if len(screenings) == 0 {
+		return &result, nil
+	}
+
+	tx, err := emtct.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the copy transaction: %w", err)
+	}
+	defer tx.Rollback()
+	insert := `
+	INSERT INTO hiv_screening 
+    	(id, patient_id, test_name, screening_date, date_sample_received_at_hq, sample_code,
+		date_sample_shipped, destination, date_result_received, result, date_result_shared, created_at, created_by, 
+		updated_at, updated_by, date_sample_taken, mother_id, timely, due_date)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	ON CONFLICT (id) DO NOTHING`
+	// same tells if the screening in emtct has the values of the screening in acsis.
+	same := `
+	SELECT count(*) FROM hiv_screening
+	WHERE id=$1 AND patient_id IS NOT DISTINCT FROM $2 AND test_name IS NOT DISTINCT FROM $3
+		AND screening_date IS NOT DISTINCT FROM $4 AND date_sample_received_at_hq IS NOT DISTINCT FROM $5
+		AND sample_code IS NOT DISTINCT FROM $6 AND date_sample_shipped IS NOT DISTINCT FROM $7
+		AND destination IS NOT DISTINCT FROM $8 AND date_result_received IS NOT DISTINCT FROM $9
+		AND result IS NOT DISTINCT FROM $10 AND date_result_shared IS NOT DISTINCT FROM $11
+		AND created_at IS NOT DISTINCT FROM $12 AND created_by IS NOT DISTINCT FROM $13
+		AND updated_at IS NOT DISTINCT FROM $14 AND updated_by IS NOT DISTINCT FROM $15
+		AND date_sample_taken IS NOT DISTINCT FROM $16 AND mother_id IS NOT DISTINCT FROM $17
+		AND timely IS NOT DISTINCT FROM $18 AND due_date IS NOT DISTINCT FROM $19`
+	ids := make([]string, len(screenings))
+	for i, s := range screenings {
+		ids[i] = s.Id
+		args := []interface{}{
+			s.Id,
+			s.PatientId,
+			s.TestName,
+			s.ScreeningDate,
+			s.DateSampleReceivedAtHq,
+			s.SampleCode,
+			s.DateSampleShipped,
+			s.Destination,
+			s.DateResultReceived,
+			s.Result,
+			s.DateResultShared,
+			s.CreatedAt,
+			s.CreatedBy,
+			s.UpdatedAt,
+			s.UpdatedBy,
+			s.DateSampleTaken,
+			s.MotherId,
+			s.Timely,
+			s.DueDate,
+		}
+		res, err := tx.ExecContext(ctx, insert, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error copying hiv screening %s: %w", s.Id, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("error copying hiv screening %s: %w", s.Id, err)
+		}
+		if n > 0 {
+			result.Copied++
+			continue
+		}
+		var matches int
+		if err := tx.QueryRowContext(ctx, same, args...).Scan(&matches); err != nil {
+			return nil, fmt.Errorf("error comparing hiv screening %s with emtct: %w", s.Id, err)
+		}
+		if matches == 0 {
+			result.Conflicting = append(result.Conflicting, s.Id)
+		} else {
+			result.AlreadyCopied++
+		}
+	}
+
+	var inEmtct int
+	if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM hiv_screening WHERE id = ANY($1)`, pq.Array(ids)).Scan(&inEmtct); err != nil {
+		return nil, fmt.Errorf("error counting the copied hiv screenings: %w", err)
+	}
+	if inEmtct != result.InAcsis {
+		return nil, fmt.Errorf("%d hiv screenings are in acsis but only %d of them are in emtct, the copy was rolled back", result.InAcsis, inEmtct)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing the copied hiv screenings: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,111 @@
+package hivScreenings
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu         sync.Mutex
+	screenings map[string]HivScreening
+	deleted    map[string]HivScreening
+	// Fake keeps the rules, which there are none of until they are set with SetRules.
+	screeningRules.Fake
+}
+
+func NewFake(ss ...HivScreening) *Fake {
+	f := &Fake{screenings: make(map[string]HivScreening), deleted: make(map[string]HivScreening)}
+	for _, s := range ss {
+		f.screenings[s.Id] = s
+	}
+	return f
+}
+
+func (f *Fake) Create(ctx context.Context, v HivScreening) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.screenings[v.Id] = v
+	return nil
+}
+
+func (f *Fake) Edit(ctx context.Context, v HivScreening) (*HivScreening, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	updatedAt := time.Now()
+	v.UpdatedAt = &updatedAt
+	old, ok := f.screenings[v.Id]
+	if !ok || old.Version != v.Version {
+		return nil, db.ErrVersionConflict
+	}
+	old.TestName = v.TestName
+	old.Result = v.Result
+	old.SampleCode = v.SampleCode
+	old.Destination = v.Destination
+	old.ScreeningDate = v.ScreeningDate
+	old.DateSampleReceivedAtHq = v.DateSampleReceivedAtHq
+	old.DateSampleShipped = v.DateSampleShipped
+	old.DateResultReceived = v.DateResultReceived
+	old.DateResultShared = v.DateResultShared
+	old.UpdatedAt = v.UpdatedAt
+	old.UpdatedBy = v.UpdatedBy
+	old.DateSampleTaken = v.DateSampleTaken
+	old.Timely = v.Timely
+	old.DueDate = v.DueDate
+	old.Version++
+	f.screenings[v.Id] = old
+	return &old, nil
+}
+
+func (f *Fake) FindById(ctx context.Context, id string) (*HivScreening, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.screenings[id]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (f *Fake) FindByPatientId(ctx context.Context, patientId int) ([]HivScreening, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var screenings []HivScreening
+	for _, s := range f.screenings {
+		if s.PatientId == patientId {
+			screenings = append(screenings, s)
+		}
+	}
+	sort.Slice(screenings, func(i, j int) bool { return screenings[i].ScreeningDate.Before(screenings[j].ScreeningDate) })
+	return screenings, nil
+}
+
+// Delete hides the hiv screening from the Find methods until it is restored.
+func (f *Fake) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.screenings[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.screenings, id)
+	f.deleted[id] = v
+	return true, nil
+}
+
+func (f *Fake) Restore(ctx context.Context, id, user string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.deleted[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.deleted, id)
+	f.screenings[id] = v
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package hivScreenings
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestHivScreeningsIntegration(t *testing.T) {
+	emtct := dbtest.OpenEmtct(t)
+	dbtest.Load(t, emtct.DB, "emtct_patients")
+	d := New(emtct)
+	ctx := context.Background()
+
+	birth := time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)
+	taken := birth.AddDate(0, 0, 1)
+	shipped := birth.AddDate(0, 0, 3)
+	rules, err := d.FindRules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := rules.DueDate("PCR 1", birth)
+	v := HivScreening{
+		Id:                "h1",
+		PatientId:         200,
+		MotherId:          100,
+		TestName:          "PCR 1",
+		ScreeningDate:     taken,
+		SampleCode:        "S-1",
+		DateSampleShipped: &shipped,
+		DateSampleTaken:   &taken,
+		DueDate:           due,
+		Destination:       "CML",
+		Timely:            rules.IsTimely(birth, "PCR 1", taken),
+		CreatedAt:         time.Now(),
+		CreatedBy:         "nurse@example.com",
+		Version:           1,
+	}
+	if err := d.Create(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	user := "doctor@example.com"
+	v.Result, v.UpdatedBy = "Negative", &user
+	if _, err := d.Edit(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := d.FindById(ctx, "h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || saved.Result != "Negative" || saved.MotherId != 100 || !saved.Timely {
+		t.Errorf("want: the edited timely screening got: %+v", saved)
+	}
+	all, err := d.FindByPatientId(ctx, 200)
+	if err != nil || len(all) != 1 {
+		t.Errorf("want: 1 screening of infant 200 got: %d, %v", len(all), err)
+	}
+
+	t.Run("retime", func(t *testing.T) {
+		// A PCR 2 that was saved with the due date and timeliness of a PCR 1.
+		late := birth.AddDate(0, 0, 30)
+		v2 := v
+		v2.Id, v2.TestName, v2.DateSampleTaken, v2.Timely = "h2", "PCR 2", &late, false
+		if err := d.Create(ctx, v2); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := emtct.ExecContext(ctx, `INSERT INTO infants (infant_id, mother_id, birth_date) VALUES (200, 100, $1)`, birth); err != nil {
+			t.Fatal(err)
+		}
+		result, err := d.Retime(ctx, rules, "retime-hiv-screenings")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Screenings != 2 || result.Changed != 1 {
+			t.Errorf("want: 1 of 2 screenings changed got: %+v", result)
+		}
+		h2, err := d.FindById(ctx, "h2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := birth.AddDate(0, 0, 41); h2 == nil || !h2.Timely || h2.DueDate == nil || !h2.DueDate.Equal(want) || h2.Version != 2 {
+			t.Errorf("want: a timely PCR 2 due %s at version 2 got: %+v", want, h2)
+		}
+		var audited int
+		err = emtct.QueryRowContext(ctx, `SELECT count(*) FROM audit_log WHERE entity_type='hiv_screening' AND entity_id='h2' AND action='update'`).
+			Scan(&audited)
+		if err != nil || audited != 1 {
+			t.Errorf("want: the retime in the audit log got: %d, %v", audited, err)
+		}
+	})
+}
+
+func TestRulesIntegration(t *testing.T) {
+	emtct := dbtest.OpenEmtct(t)
+	d := New(emtct)
+	ctx := context.Background()
+
+	rules, err := d.FindRules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 4 {
+		t.Errorf("want: the 4 rules of the migration got: %+v", rules)
+	}
+	r := screeningRules.Rule{
+		TestName:      "PCR 2",
+		EffectiveFrom: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		DueDays:       28,
+		CreatedAt:     time.Now(),
+		CreatedBy:     "admin@example.com",
+	}
+	created, err := d.CreateRule(ctx, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.CreateRule(ctx, r); !errors.Is(err, screeningRules.ErrExists) {
+		t.Errorf("want: ErrExists for a second rule on the same day got: %v", err)
+	}
+	rules, err = d.FindRules(ctx)
+	if err != nil || len(rules) != 5 || rules[0].Id != created.Id {
+		t.Errorf("want: the new rule first got: %+v, %v", rules, err)
+	}
+	if deleted, err := d.DeleteRule(ctx, created.Id); err != nil || !deleted {
+		t.Errorf("want: the rule deleted got: %t, %v", deleted, err)
+	}
+	if deleted, err := d.DeleteRule(ctx, created.Id); err != nil || deleted {
+		t.Errorf("want: no rule to delete the second time got: %t, %v", deleted, err)
+	}
+}
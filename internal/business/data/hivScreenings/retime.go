@@ -0,0 +1,97 @@
+package hivScreenings
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// RetimeResult counts the hiv screenings that Retime worked out again.
+type RetimeResult struct {
+	Screenings int
+	Changed    int
+	// NoBirth are the screenings of infants whose birth was not synced, which are left as they are.
+	NoBirth int
+}
+
+// Retime works out the due date and timeliness of every saved hiv screening again from rules and
+// the birth date of its infant in the emtct infants table, e.g. after the rules changed. A
+// screening whose due date or timeliness changes is saved as a new version by user, through the
+// audit log like any other edit.
+func (d *HivScreenings) Retime(ctx context.Context, rules screeningRules.Rules, user string) (*RetimeResult, error) {
+	type timing struct {
+		id, testName    string
+		dateSampleTaken *time.Time
+		timely          bool
+		dueDate         *time.Time
+		birthDate       *time.Time
+	}
+	var timings []timing
+	err := func() error {
+		ctx, cancel := d.WithTimeout(ctx)
+		defer cancel()
+		stmt := `
+		SELECT s.id, s.test_name, s.date_sample_taken, s.timely, s.due_date, i.birth_date
+		FROM hiv_screening s
+			LEFT JOIN infants i ON i.infant_id=s.patient_id
+		WHERE s.deleted_at IS NULL
+		ORDER BY s.id`
+		rows, err := d.QueryContext(ctx, stmt)
+		if err != nil {
+			return fmt.Errorf("error querying the hiv screenings to retime: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var t timing
+			if err := rows.Scan(&t.id, &t.testName, &t.dateSampleTaken, &t.timely, &t.dueDate, &t.birthDate); err != nil {
+				return fmt.Errorf("error scanning an hiv screening to retime: %w", err)
+			}
+			timings = append(timings, t)
+		}
+		return rows.Err()
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	result := RetimeResult{Screenings: len(timings)}
+	stmt := `
+	UPDATE hiv_screening
+	SET due_date=$1, timely=$2, updated_at=$3, updated_by=$4, version=version+1
+	WHERE id=$5 AND deleted_at IS NULL`
+	for _, t := range timings {
+		if t.birthDate == nil {
+			result.NoBirth++
+			continue
+		}
+		due := rules.DueDate(t.testName, *t.birthDate)
+		timely := t.dateSampleTaken != nil && rules.IsTimely(*t.birthDate, t.testName, *t.dateSampleTaken)
+		if timely == t.timely && sameDay(due, t.dueDate) {
+			continue
+		}
+		err := func() error {
+			ctx, cancel := d.WithTimeout(ctx)
+			defer cancel()
+			return d.Audited(ctx, "hiv_screening", t.id, db.AuditUpdate, user, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, stmt, due, timely, time.Now(), user, t.id)
+				return err
+			})
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("error retiming hiv screening %s: %w", t.id, err)
+		}
+		result.Changed++
+	}
+	return &result, nil
+}
+
+func sameDay(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return screeningRules.DayOf(*a).Equal(screeningRules.DayOf(*b))
+}
@@ -0,0 +1,27 @@
+package hivScreenings
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// FindRules returns every rule, the latest effective first.
+func (d *HivScreenings) FindRules(ctx context.Context) (screeningRules.Rules, error) {
+	return d.rules().FindRules(ctx)
+}
+
+// CreateRule inserts a rule and returns it with its id.
+func (d *HivScreenings) CreateRule(ctx context.Context, r screeningRules.Rule) (*screeningRules.Rule, error) {
+	return d.rules().CreateRule(ctx, r)
+}
+
+// DeleteRule removes a rule that was added by mistake. It returns false when the rule does not
+// exist.
+func (d *HivScreenings) DeleteRule(ctx context.Context, id int) (bool, error) {
+	return d.rules().DeleteRule(ctx, id)
+}
+
+func (d *HivScreenings) rules() screeningRules.Table {
+	return screeningRules.Table{EmtctDb: d.EmtctDb, Name: "hiv_screening_rules"}
+}
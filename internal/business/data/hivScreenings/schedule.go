@@ -0,0 +1,91 @@
+package hivScreenings
+
+import (
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// The status of a ScheduledTest.
+const (
+	StatusDoneOnTime = "doneOnTime"
+	StatusDoneLate   = "doneLate"
+	StatusPending    = "pending"
+	StatusOverdue    = "overdue"
+)
+
+// Window is when the sample of a scheduled test should be taken. It opens on the due date of
+// the test before it, or on the birth date for the first test, and closes on its due date.
+type Window struct {
+	Opens  time.Time `json:"opens"`
+	Closes time.Time `json:"closes"`
+}
+
+// ScheduledTest is a test of an infant's testing schedule, with the screening that was recorded
+// for it. Screening is nil when no screening of the test was recorded.
+type ScheduledTest struct {
+	TestName  string        `json:"testName"`
+	DueDate   time.Time     `json:"dueDate"`
+	Window    Window        `json:"window"`
+	Status    string        `json:"status"`
+	Screening *HivScreening `json:"screening"`
+}
+
+// Schedule returns the testing schedule of an infant born on birthDate, on the day today. It has
+// the tests whose rules were in force on the birth date, in the order they are due. It is worked
+// out from the birth date every time, so the due dates and the timeliness of the screenings
+// follow the birth date when it is corrected in ACSIS, instead of the due date and timely flag
+// that were saved with the screenings.
+//
+// A test is matched to the screening of that test whose sample was taken first. A test whose
+// sample was taken is done on time or late. Otherwise it is pending up to its due date and
+// overdue after it.
+func Schedule(rules screeningRules.Rules, birthDate time.Time, screenings []HivScreening, today time.Time) []ScheduledTest {
+	inForce := rules.InForce(birthDate)
+	schedule := make([]ScheduledTest, 0, len(inForce))
+	opens := birthDate
+	for _, rule := range inForce {
+		due := rule.DueDate(birthDate)
+		t := ScheduledTest{
+			TestName:  rule.TestName,
+			DueDate:   due,
+			Window:    Window{Opens: opens, Closes: due},
+			Screening: firstScreening(rule.TestName, screenings),
+		}
+		switch {
+		case t.Screening != nil && t.Screening.DateSampleTaken != nil:
+			if rule.IsTimely(birthDate, *t.Screening.DateSampleTaken) {
+				t.Status = StatusDoneOnTime
+			} else {
+				t.Status = StatusDoneLate
+			}
+		case screeningRules.DayOf(today).After(screeningRules.DayOf(due)):
+			t.Status = StatusOverdue
+		default:
+			t.Status = StatusPending
+		}
+		schedule = append(schedule, t)
+		opens = due
+	}
+	return schedule
+}
+
+// firstScreening returns the screening of testName whose sample was taken first, or a screening
+// of testName whose sample was not taken yet when there is none.
+func firstScreening(testName string, screenings []HivScreening) *HivScreening {
+	var first *HivScreening
+	for i := range screenings {
+		s := &screenings[i]
+		if s.TestName != testName {
+			continue
+		}
+		switch {
+		case first == nil:
+			first = s
+		case s.DateSampleTaken == nil:
+		case first.DateSampleTaken == nil || s.DateSampleTaken.Before(*first.DateSampleTaken):
+			first = s
+		}
+	}
+	return first
+}
@@ -0,0 +1,204 @@
+package hivScreenings
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Create inserts a new hiv screening of an infant.
+func (d *HivScreenings) Create(ctx context.Context, v HivScreening) error {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	INSERT INTO hiv_Screening 
+    	(id, patient_id, test_name, screening_date, date_sample_received_at_hq, sample_code,
+		date_sample_shipped, destination, date_result_received, result, date_result_shared, created_at, created_by, 
+		date_sample_taken, mother_id, timely, due_date)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+	err := d.Audited(ctx, "hiv_screening", v.Id, db.AuditCreate, v.CreatedBy, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt,
+			v.Id,
+			v.PatientId,
+			v.TestName,
+			v.ScreeningDate,
+			v.DateSampleReceivedAtHq,
+			v.SampleCode,
+			v.DateSampleShipped,
+			v.Destination,
+			v.DateResultReceived,
+			v.Result,
+			v.DateResultShared,
+			v.CreatedAt,
+			v.CreatedBy,
+			v.DateSampleTaken,
+			v.MotherId,
+			v.Timely,
+			v.DueDate)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting new hiv screening into database: %w", err)
+	}
+
+	return nil
+}
+
+// FindByPatientId returns the hiv screenings of an infant.
+func (d *HivScreenings) FindByPatientId(ctx context.Context, patientId int) ([]HivScreening, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT 
+		id, patient_id, mother_id, test_name, screening_date, date_sample_received_at_hq, sample_code,
+		date_sample_shipped, date_sample_taken, destination, date_result_received, result, date_result_shared, 
+		created_at, created_by, updated_at, updated_by, timely, due_date, version
+	FROM hiv_screening 
+	WHERE patient_id=$1 AND deleted_at IS NULL
+`
+
+	var screenings []HivScreening
+
+	rows, err := d.QueryContext(ctx, stmt, patientId)
+	if err != nil {
+		return screenings, fmt.Errorf("error querying hiv screenings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s HivScreening
+		err := rows.Scan(
+			&s.Id,
+			&s.PatientId,
+			&s.MotherId,
+			&s.TestName,
+			&s.ScreeningDate,
+			&s.DateSampleReceivedAtHq,
+			&s.SampleCode,
+			&s.DateSampleShipped,
+			&s.DateSampleTaken,
+			&s.Destination,
+			&s.DateResultReceived,
+			&s.Result,
+			&s.DateResultShared,
+			&s.CreatedAt,
+			&s.CreatedBy,
+			&s.UpdatedAt,
+			&s.UpdatedBy,
+			&s.Timely,
+			&s.DueDate,
+			&s.Version)
+		if err != nil {
+			return screenings, fmt.Errorf("error scanning hiv screening row: %w", err)
+		}
+		screenings = append(screenings, s)
+	}
+
+	return screenings, nil
+}
+
+// Edit saves v when it is still at v.Version and returns the saved screening at its new
+// version. It returns db.ErrVersionConflict when the screening was saved by someone else, or
+// deleted.
+func (d *HivScreenings) Edit(ctx context.Context, v HivScreening) (*HivScreening, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	UPDATE hiv_screening 
+	SET test_name=$1, result=$2, sample_code=$3, destination=$4, screening_date=$5, date_sample_received_at_hq=$6, 
+	    date_sample_shipped=$7, date_result_received=$8, date_result_shared=$9, updated_at=$10, updated_by=$11, 
+	    date_sample_taken=$12, timely=$13, due_date=$14, version=version+1
+	WHERE id=$15 AND version=$16 AND deleted_at IS NULL
+	RETURNING ` + screeningColumns
+	var saved HivScreening
+	err := d.Audited(ctx, "hiv_screening", v.Id, db.AuditUpdate, db.AuditUser(v.UpdatedBy), func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, stmt,
+			v.TestName,
+			v.Result,
+			v.SampleCode,
+			v.Destination,
+			v.ScreeningDate,
+			v.DateSampleReceivedAtHq,
+			v.DateSampleShipped,
+			v.DateResultReceived,
+			v.DateResultShared,
+			time.Now(),
+			v.UpdatedBy,
+			v.DateSampleTaken,
+			v.Timely,
+			v.DueDate,
+			v.Id,
+			v.Version)
+		var err error
+		saved, err = scanScreening(row)
+		if err == sql.ErrNoRows {
+			return db.ErrVersionConflict
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error updating hiv screening in database: %w", err)
+	}
+	return &saved, nil
+}
+
+// screeningColumns are the columns of a screening that are read by scanScreening.
+const screeningColumns = `
+	id, patient_id, mother_id, test_name, result, sample_code, destination, screening_date,
+	date_sample_received_at_hq, date_sample_shipped, date_sample_taken, date_result_received, date_result_shared, 
+	updated_at, updated_by, timely, due_date, version`
+
+func scanScreening(row *sql.Row) (HivScreening, error) {
+	var screening HivScreening
+	err := row.Scan(
+		&screening.Id,
+		&screening.PatientId,
+		&screening.MotherId,
+		&screening.TestName,
+		&screening.Result,
+		&screening.SampleCode,
+		&screening.Destination,
+		&screening.ScreeningDate,
+		&screening.DateSampleReceivedAtHq,
+		&screening.DateSampleShipped,
+		&screening.DateSampleTaken,
+		&screening.DateResultReceived,
+		&screening.DateResultShared,
+		&screening.UpdatedAt,
+		&screening.UpdatedBy,
+		&screening.Timely,
+		&screening.DueDate,
+		&screening.Version)
+	return screening, err
+}
+
+// FindById returns nil when the screening does not exist.
+func (d *HivScreenings) FindById(ctx context.Context, id string) (*HivScreening, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT ` + screeningColumns + ` FROM hiv_screening WHERE id=$1 AND deleted_at IS NULL`
+	screening, err := scanScreening(d.QueryRowContext(ctx, stmt, id))
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+		return &screening, nil
+	default:
+		return nil, fmt.Errorf("error retrieving hiv screening from database: %w", err)
+	}
+}
+
+// Delete soft-deletes an hiv screening that was recorded by mistake. It returns false when the
+// hiv screening does not exist or was already deleted.
+func (d *HivScreenings) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	return d.EmtctDb.SoftDelete(ctx, "hiv_screening", id, user, reason)
+}
+
+// Restore undoes the deletion of an hiv screening. It returns false when the hiv screening
+// does not exist or is not deleted.
+func (d *HivScreenings) Restore(ctx context.Context, id, user string) (bool, error) {
+	return d.EmtctDb.Restore(ctx, "hiv_screening", id, user)
+}
@@ -0,0 +1,64 @@
+package hivScreenings
+
+import (
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// guidelines are the rules that the migration adds.
+var guidelines = screeningRules.Rules{
+	{Id: 1, TestName: "PCR 1", DueDays: 3},
+	{Id: 2, TestName: "PCR 2", DueDays: 41},
+	{Id: 3, TestName: "PCR 3", DueDays: 90},
+	{Id: 4, TestName: "ELISA", DueMonths: 18},
+}
+
+func TestSchedule(t *testing.T) {
+	birth := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	after := func(days int) *time.Time {
+		d := birth.AddDate(0, 0, days)
+		return &d
+	}
+	screenings := []HivScreening{
+		{Id: "a", TestName: "PCR 1", DateSampleTaken: after(2)},
+		{Id: "b", TestName: "PCR 2", DateSampleTaken: after(50)},
+		{Id: "c", TestName: "PCR 2", DateSampleTaken: after(45)},
+		{Id: "d", TestName: "PCR 2"},
+		{Id: "e", TestName: "ELISA"},
+	}
+	schedule := Schedule(guidelines, birth, screenings, *after(100))
+	want := []struct {
+		testName  string
+		status    string
+		screening string
+	}{
+		{"PCR 1", StatusDoneOnTime, "a"},
+		{"PCR 2", StatusDoneLate, "c"},
+		{"PCR 3", StatusOverdue, ""},
+		{"ELISA", StatusPending, "e"},
+	}
+	if len(schedule) != len(want) {
+		t.Fatalf("want: %d tests got: %d", len(want), len(schedule))
+	}
+	for i, w := range want {
+		got := schedule[i]
+		var id string
+		if got.Screening != nil {
+			id = got.Screening.Id
+		}
+		if got.TestName != w.testName || got.Status != w.status || id != w.screening {
+			t.Errorf("want: %s %s matched to %q got: %s %s matched to %q", w.testName, w.status, w.screening, got.TestName, got.Status, id)
+		}
+	}
+	if !schedule[1].Window.Opens.Equal(*after(3)) || !schedule[1].Window.Closes.Equal(*after(41)) {
+		t.Errorf("want: PCR 2 window from day 3 to 41 got: %+v", schedule[1].Window)
+	}
+
+	// A later birth date in ACSIS makes the PCR 2 sample timely and PCR 3 pending.
+	schedule = Schedule(guidelines, birth.AddDate(0, 0, 10), screenings, *after(100))
+	if schedule[1].Status != StatusDoneOnTime || schedule[2].Status != StatusPending {
+		t.Errorf("want: PCR 2 on time and PCR 3 pending got: %s, %s", schedule[1].Status, schedule[2].Status)
+	}
+}
@@ -0,0 +1,23 @@
+package hivScreenings
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// Store is implemented by *HivScreenings and by Fake.
+type Store interface {
+	Create(ctx context.Context, v HivScreening) error
+	Edit(ctx context.Context, v HivScreening) (*HivScreening, error)
+	FindById(ctx context.Context, id string) (*HivScreening, error)
+	FindByPatientId(ctx context.Context, patientId int) ([]HivScreening, error)
+	Delete(ctx context.Context, id, user, reason string) (bool, error)
+	Restore(ctx context.Context, id, user string) (bool, error)
+	screeningRules.Store
+}
+
+var (
+	_ Store = (*HivScreenings)(nil)
+	_ Store = (*Fake)(nil)
+)
@@ -0,0 +1,108 @@
+package homeVisits
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu      sync.Mutex
+	visits  map[string]HomeVisit
+	deleted map[string]HomeVisit
+}
+
+func NewFake(vs ...HomeVisit) *Fake {
+	f := &Fake{visits: make(map[string]HomeVisit), deleted: make(map[string]HomeVisit)}
+	for _, v := range vs {
+		f.visits[v.Id] = v
+	}
+	return f
+}
+
+func (f *Fake) Create(ctx context.Context, v HomeVisit) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.visits[v.Id] = v
+	return nil
+}
+
+func (f *Fake) Edit(ctx context.Context, v HomeVisit) (*HomeVisit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	updatedAt := time.Now()
+	v.UpdatedAt = &updatedAt
+	old, ok := f.visits[v.Id]
+	if !ok || old.Version != v.Version {
+		return nil, db.ErrVersionConflict
+	}
+	old.Reason = v.Reason
+	old.Comments = v.Comments
+	old.DateOfVisit = v.DateOfVisit
+	old.UpdatedBy = v.UpdatedBy
+	old.UpdatedAt = v.UpdatedAt
+	old.Version++
+	f.visits[v.Id] = old
+	v.Version = old.Version
+	return &v, nil
+}
+
+func (f *Fake) FindById(ctx context.Context, id string) (*HomeVisit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.visits[id]
+	if !ok {
+		return nil, nil
+	}
+	return &v, nil
+}
+
+func (f *Fake) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]HomeVisit, paging.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var vs []HomeVisit
+	for _, v := range f.visits {
+		if v.PatientId == patientId {
+			vs = append(vs, v)
+		}
+	}
+	keys := make([]paging.Cursor, len(vs))
+	for i, v := range vs {
+		keys[i] = paging.Cursor{Date: v.DateOfVisit, Id: v.Id}
+	}
+	idx, info := q.Select(keys)
+	var page []HomeVisit
+	for _, i := range idx {
+		page = append(page, vs[i])
+	}
+	return page, info, nil
+}
+
+// Delete hides the home visit from the Find methods until it is restored.
+func (f *Fake) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.visits[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.visits, id)
+	f.deleted[id] = v
+	return true, nil
+}
+
+func (f *Fake) Restore(ctx context.Context, id, user string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.deleted[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.deleted, id)
+	f.visits[id] = v
+	return true, nil
+}
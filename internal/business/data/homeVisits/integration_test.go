@@ -0,0 +1,52 @@
+package homeVisits
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestHomeVisitsIntegration(t *testing.T) {
+	h := New(dbtest.OpenEmtct(t))
+	ctx := context.Background()
+
+	v := HomeVisit{
+		Id:             "v1",
+		PatientId:      100,
+		MchEncounterId: 1000,
+		Reason:         "Follow up",
+		DateOfVisit:    time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		CreatedAt:      time.Now(),
+		CreatedBy:      "nurse@example.com",
+		Version:        1,
+	}
+	if err := h.Create(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	user := "doctor@example.com"
+	v.Comments, v.UpdatedBy = "Taking her ARVs", &user
+	if _, err := h.Edit(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	// v is still at version 1, so the second edit is stale.
+	if _, err := h.Edit(ctx, v); !errors.Is(err, db.ErrVersionConflict) {
+		t.Errorf("want: a version conflict got: %v", err)
+	}
+
+	saved, err := h.FindById(ctx, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || saved.Comments != "Taking her ARVs" || saved.UpdatedBy == nil || saved.Version != 2 {
+		t.Errorf("want: the edited home visit got: %+v", saved)
+	}
+	all, page, err := h.FindByPatientId(ctx, 100, paging.Query{})
+	if err != nil || len(all) != 1 || page.Total != 1 || page.NextCursor != nil {
+		t.Errorf("want: 1 home visit of patient 100 got: %d, %+v, %v", len(all), page, err)
+	}
+}
@@ -1,16 +1,17 @@
 package homeVisits
 
 import (
-	"database/sql"
 	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type HomeVisits struct {
-	*sql.DB
+	*db.EmtctDb
 }
 
-func New(db *sql.DB) HomeVisits {
-	return HomeVisits{db}
+func New(emtctDb *db.EmtctDb) HomeVisits {
+	return HomeVisits{emtctDb}
 }
 
 type HomeVisit struct {
@@ -24,4 +25,5 @@ type HomeVisit struct {
 	UpdatedAt      *time.Time `json:"updatedAt"`
 	CreatedBy      string     `json:"createdBy"`
 	UpdatedBy      *string    `json:"updatedBy"`
+	Version        int        `json:"version"`
 }
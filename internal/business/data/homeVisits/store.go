@@ -0,0 +1,22 @@
+package homeVisits
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+)
+
+// Store is implemented by *HomeVisits and by Fake.
+type Store interface {
+	Create(ctx context.Context, v HomeVisit) error
+	Edit(ctx context.Context, v HomeVisit) (*HomeVisit, error)
+	FindById(ctx context.Context, id string) (*HomeVisit, error)
+	FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]HomeVisit, paging.Info, error)
+	Delete(ctx context.Context, id, user, reason string) (bool, error)
+	Restore(ctx context.Context, id, user string) (bool, error)
+}
+
+var (
+	_ Store = (*HomeVisits)(nil)
+	_ Store = (*Fake)(nil)
+)
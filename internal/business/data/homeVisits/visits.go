@@ -1,49 +1,72 @@
 package homeVisits
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
-func (h *HomeVisits) Create(v HomeVisit) error {
+func (h *HomeVisits) Create(ctx context.Context, v HomeVisit) error {
+	ctx, cancel := h.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	INSERT INTO home_visit 
 	    (id, patient_id, reason, comments, date_of_visit, created_at, created_by, mch_encounter_id) 
 	    VALUES($1, $2, $3, $4, $5, $6, $7, $8)`
-	_, err := h.Exec(stmt, v.Id, v.PatientId, v.Reason, v.Comments, v.DateOfVisit, v.CreatedAt, v.CreatedBy, v.MchEncounterId)
+	err := h.Audited(ctx, "home_visit", v.Id, db.AuditCreate, v.CreatedBy, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt, v.Id, v.PatientId, v.Reason, v.Comments, v.DateOfVisit, v.CreatedAt, v.CreatedBy, v.MchEncounterId)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error creating a home visit: %+v", err)
+		return fmt.Errorf("error creating a home visit: %w", err)
 	}
 	return nil
 }
 
-func (h *HomeVisits) Edit(v HomeVisit) (*HomeVisit, error) {
+// Edit saves v when it is still at v.Version and returns it at its new version. It returns
+// db.ErrVersionConflict when the home visit was saved by someone else, or deleted.
+func (h *HomeVisits) Edit(ctx context.Context, v HomeVisit) (*HomeVisit, error) {
+	ctx, cancel := h.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	UPDATE home_visit 
-	SET reason=$1, comments=$2, date_of_visit=$3, updated_by=$4, updated_at=$5
-	WHERE id=$6`
+	SET reason=$1, comments=$2, date_of_visit=$3, updated_by=$4, updated_at=$5, version=version+1
+	WHERE id=$6 AND version=$7 AND deleted_at IS NULL`
 	updateddAt := time.Now()
-	_, err := h.Exec(stmt,
-		v.Reason,
-		v.Comments,
-		v.DateOfVisit,
-		v.UpdatedBy,
-		updateddAt,
-		v.Id)
+	err := h.Audited(ctx, "home_visit", v.Id, db.AuditUpdate, db.AuditUser(v.UpdatedBy), func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, stmt,
+			v.Reason,
+			v.Comments,
+			v.DateOfVisit,
+			v.UpdatedBy,
+			updateddAt,
+			v.Id,
+			v.Version)
+		if err != nil {
+			return err
+		}
+		return db.CheckVersion(res)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error updating homve visit in database: %+v", err)
+		return nil, fmt.Errorf("error updating homve visit in database: %w", err)
 	}
 	v.UpdatedAt = &updateddAt
+	v.Version++
 	return &v, nil
 }
 
-func (h *HomeVisits) FindById(id string) (*HomeVisit, error) {
+func (h *HomeVisits) FindById(ctx context.Context, id string) (*HomeVisit, error) {
+	ctx, cancel := h.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
-	SELECT id, patient_id, reason, comments, date_of_visit, created_at, updated_at, created_by, updated_by, mch_encounter_id 
-	FROM home_visit WHERE id=$1`
+	SELECT id, patient_id, reason, comments, date_of_visit, created_at, updated_at, created_by, updated_by, mch_encounter_id, version
+	FROM home_visit WHERE id=$1 AND deleted_at IS NULL`
 	var homeVisit HomeVisit
-	row := h.QueryRow(stmt, id)
+	row := h.QueryRowContext(ctx, stmt, id)
 	err := row.Scan(
 		&homeVisit.Id,
 		&homeVisit.PatientId,
@@ -55,6 +78,7 @@ func (h *HomeVisits) FindById(id string) (*HomeVisit, error) {
 		&homeVisit.CreatedBy,
 		&homeVisit.UpdatedBy,
 		&homeVisit.MchEncounterId,
+		&homeVisit.Version,
 	)
 
 	switch err {
@@ -63,23 +87,33 @@ func (h *HomeVisits) FindById(id string) (*HomeVisit, error) {
 	case nil:
 		return &homeVisit, nil
 	default:
-		return nil, fmt.Errorf("error scanning home visit row: %+v", err)
+		return nil, fmt.Errorf("error scanning home visit row: %w", err)
 	}
 }
 
-func (h *HomeVisits) FindByPatientId(patientId int) ([]HomeVisit, error) {
+// FindByPatientId returns the page q of the home visits of a patient, by date of visit.
+func (h *HomeVisits) FindByPatientId(ctx context.Context, patientId int, q paging.Query) ([]HomeVisit, paging.Info, error) {
+	ctx, cancel := h.WithTimeout(ctx)
+	defer cancel()
+	filter, args := q.Filter("date_of_visit", 1)
+	var total int
+	count := `SELECT count(*) FROM home_visit WHERE patient_id=$1 AND deleted_at IS NULL` + filter
+	if err := h.QueryRowContext(ctx, count, append([]interface{}{patientId}, args...)...).Scan(&total); err != nil {
+		return nil, paging.Info{}, fmt.Errorf("error counting the home visits: %w", err)
+	}
+	seek, args := q.Seek("date_of_visit", "id", 1)
 	stmt := `
 	SELECT 
-	       id, patient_id, reason, comments, date_of_visit, created_at, updated_at, created_by, updated_by, mch_encounter_id 
+	       id, patient_id, reason, comments, date_of_visit, created_at, updated_at, created_by, updated_by, mch_encounter_id, version
 	FROM 
 	     home_visit 
-	WHERE patient_id=$1`
-	rows, err := h.Query(stmt, patientId)
-	defer rows.Close()
+	WHERE patient_id=$1 AND deleted_at IS NULL` + seek + q.OrderBy("date_of_visit", "id")
+	rows, err := h.QueryContext(ctx, stmt, append([]interface{}{patientId}, args...)...)
 
 	if err != nil {
-		return nil, fmt.Errorf("error executing query for retrieving home visits: %+v", err)
+		return nil, paging.Info{}, fmt.Errorf("error executing query for retrieving home visits: %w", err)
 	}
+	defer rows.Close()
 
 	var homeVisits []HomeVisit
 	for rows.Next() {
@@ -95,12 +129,28 @@ func (h *HomeVisits) FindByPatientId(patientId int) ([]HomeVisit, error) {
 			&homeVisit.CreatedBy,
 			&homeVisit.UpdatedBy,
 			&homeVisit.MchEncounterId,
+			&homeVisit.Version,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning a home visit row: %+v", err)
+			return nil, paging.Info{}, fmt.Errorf("error scanning a home visit row: %w", err)
 		}
 
 		homeVisits = append(homeVisits, homeVisit)
 	}
-	return homeVisits, nil
+	info, n := q.NewInfo(total, len(homeVisits), func(i int) paging.Cursor {
+		return paging.Cursor{Date: homeVisits[i].DateOfVisit, Id: homeVisits[i].Id}
+	})
+	return homeVisits[:n], info, nil
+}
+
+// Delete soft-deletes a home visit that was recorded by mistake. It returns false when the
+// home visit does not exist or was already deleted.
+func (h *HomeVisits) Delete(ctx context.Context, id, user, reason string) (bool, error) {
+	return h.EmtctDb.SoftDelete(ctx, "home_visit", id, user, reason)
+}
+
+// Restore undoes the deletion of a home visit. It returns false when the home visit does not
+// exist or is not deleted.
+func (h *HomeVisits) Restore(ctx context.Context, id, user string) (bool, error) {
+	return h.EmtctDb.Restore(ctx, "home_visit", id, user)
 }
@@ -0,0 +1,255 @@
+package infant
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// BirthsUpsertResult counts what happened when a batch of ACSIS births was upserted.
+type BirthsUpsertResult struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
+
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// Equal indicates if two births link the infant to the same mother and pregnancy, with the
+// same birth date and status.
+func (b Birth) Equal(o Birth) bool {
+	return b.InfantId == o.InfantId &&
+		b.MotherId == o.MotherId &&
+		sameInt(b.PregnancyId, o.PregnancyId) &&
+		sameDate(b.BirthDate, o.BirthDate) &&
+		sameString(b.BirthStatus, o.BirthStatus)
+}
+
+// FindBirthsInBhis returns the ACSIS births of the given mothers. The births have no
+// pregnancy, because ACSIS does not record which pregnancy an infant was born from.
+// When ACSIS has more than one birth for an infant, only the latest modified one is returned.
+func (d *Infants) FindBirthsInBhis(ctx context.Context, motherIds []int) ([]Birth, error) {
+	ctx, cancel := d.Acsis.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT DISTINCT ON (b.patient_id)
+	       b.patient_id,
+	       b.mother_id,
+	       pt.birth_date,
+	       bs.name
+	FROM acsis_hc_births b
+	INNER JOIN acsis_hc_patients pt ON pt.patient_id=b.patient_id
+	LEFT JOIN acsis_hc_birth_statuses bs ON b.birth_status_id=bs.birth_status_id
+	WHERE b.mother_id = ANY($1)
+	ORDER BY b.patient_id, b.last_modified_time DESC;
+`
+	rows, err := d.Acsis.QueryContext(ctx, stmt, pq.Array(motherIds))
+	if err != nil {
+		return nil, fmt.Errorf("error querying for births from acsis: %w", err)
+	}
+	defer rows.Close()
+	var births []Birth
+	for rows.Next() {
+		var b Birth
+		if err := rows.Scan(&b.InfantId, &b.MotherId, &b.BirthDate, &b.BirthStatus); err != nil {
+			return nil, fmt.Errorf("error scanning birth from acsis: %w", err)
+		}
+		births = append(births, b)
+	}
+	return births, nil
+}
+
+const birthColumns = `infant_id, mother_id, pregnancy_id, birth_date, birth_status, linked_manually, linked_by, linked_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBirth(row scanner) (Birth, error) {
+	var b Birth
+	err := row.Scan(
+		&b.InfantId,
+		&b.MotherId,
+		&b.PregnancyId,
+		&b.BirthDate,
+		&b.BirthStatus,
+		&b.LinkedManually,
+		&b.LinkedBy,
+		&b.LinkedAt)
+	return b, err
+}
+
+// FindBirthsByInfantIds returns the emtct births of the given infants, keyed by infant id.
+func (d *Infants) FindBirthsByInfantIds(ctx context.Context, infantIds []int) (map[int]Birth, error) {
+	ctx, cancel := d.Emtct.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT ` + birthColumns + ` FROM infants WHERE infant_id = ANY($1)`
+	rows, err := d.Emtct.QueryContext(ctx, stmt, pq.Array(infantIds))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving births from emtct db: %w", err)
+	}
+	defer rows.Close()
+	births := make(map[int]Birth)
+	for rows.Next() {
+		b, err := scanBirth(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning birth from emtct db: %w", err)
+		}
+		births[b.InfantId] = b
+	}
+	return births, nil
+}
+
+// FindBirth returns the link between an infant and its mother's pregnancy.
+// It returns nil if the infant has not been synced or linked yet.
+func (d *Infants) FindBirth(ctx context.Context, infantId int) (*Birth, error) {
+	ctx, cancel := d.Emtct.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT ` + birthColumns + ` FROM infants WHERE infant_id=$1`
+	b, err := scanBirth(d.Emtct.QueryRowContext(ctx, stmt, infantId))
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+		return &b, nil
+	default:
+		return nil, fmt.Errorf("error retrieving birth of infant %d from emtct db: %w", infantId, err)
+	}
+}
+
+// FindBirthsByPregnancy returns the infants that are linked to a pregnancy, the latest born
+// first. A pregnancy has more than one infant when it ended with twins.
+func (d *Infants) FindBirthsByPregnancy(ctx context.Context, pregnancyId int) ([]Birth, error) {
+	ctx, cancel := d.Emtct.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT ` + birthColumns + ` FROM infants WHERE pregnancy_id=$1 ORDER BY birth_date DESC, infant_id`
+	rows, err := d.Emtct.QueryContext(ctx, stmt, pregnancyId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving infants of pregnancy %d from emtct db: %w", pregnancyId, err)
+	}
+	defer rows.Close()
+	var births []Birth
+	for rows.Next() {
+		b, err := scanBirth(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning infant of pregnancy %d: %w", pregnancyId, err)
+		}
+		births = append(births, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading infants of pregnancy %d: %w", pregnancyId, err)
+	}
+	return births, nil
+}
+
+// UpsertBirths inserts new births and updates the births that already exist in the emtct
+// database. The pregnancy and mother of a birth that was linked by hand are never changed.
+func (d *Infants) UpsertBirths(ctx context.Context, births []Birth) (*BirthsUpsertResult, error) {
+	ids := make([]int, 0, len(births))
+	for _, b := range births {
+		ids = append(ids, b.InfantId)
+	}
+	existing, err := d.FindBirthsByInfantIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find existing births for the upsert: %w", err)
+	}
+
+	tx, err := d.Emtct.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction for upserting births: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt := `
+	INSERT INTO infants (infant_id, mother_id, pregnancy_id, birth_date, birth_status, synced_at)
+	VALUES($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (infant_id) DO UPDATE
+	SET birth_date=EXCLUDED.birth_date, birth_status=EXCLUDED.birth_status, synced_at=EXCLUDED.synced_at,
+	    mother_id=CASE WHEN infants.linked_manually THEN infants.mother_id ELSE EXCLUDED.mother_id END,
+	    pregnancy_id=CASE WHEN infants.linked_manually THEN infants.pregnancy_id ELSE EXCLUDED.pregnancy_id END;
+`
+	now := time.Now()
+	var result BirthsUpsertResult
+	for _, b := range births {
+		old, exists := existing[b.InfantId]
+		if exists && old.LinkedManually {
+			b.MotherId = old.MotherId
+			b.PregnancyId = old.PregnancyId
+		}
+		switch {
+		case !exists:
+			result.Inserted++
+		case old.Equal(b):
+			result.Unchanged++
+			continue
+		default:
+			result.Updated++
+		}
+		_, err := tx.ExecContext(ctx, stmt, b.InfantId, b.MotherId, b.PregnancyId, b.BirthDate, b.BirthStatus, now)
+		if err != nil {
+			return nil, fmt.Errorf("error upserting birth of infant %d: %w", b.InfantId, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit the transaction for upserting births: %w", err)
+	}
+	return &result, nil
+}
+
+// LinkBirth links an infant to a pregnancy by hand. The link is kept by later births etl runs,
+// and it is recorded in the audit log against the infant id.
+func (d *Infants) LinkBirth(ctx context.Context, b Birth, user string) (*Birth, error) {
+	ctx, cancel := d.Emtct.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	INSERT INTO infants (infant_id, mother_id, pregnancy_id, birth_date, linked_manually, linked_by, linked_at)
+	VALUES($1, $2, $3, $4, true, $5, $6)
+	ON CONFLICT (infant_id) DO UPDATE
+	SET mother_id=EXCLUDED.mother_id, pregnancy_id=EXCLUDED.pregnancy_id, linked_manually=true,
+	    linked_by=EXCLUDED.linked_by, linked_at=EXCLUDED.linked_at
+	RETURNING ` + birthColumns
+	old, err := d.FindBirth(ctx, b.InfantId)
+	if err != nil {
+		return nil, err
+	}
+	action := db.AuditUpdate
+	if old == nil {
+		action = db.AuditCreate
+	}
+	var saved Birth
+	err = d.Emtct.AuditedByKey(ctx, "infants", "infant_id", strconv.Itoa(b.InfantId), action, user, func(tx *sql.Tx) error {
+		var err error
+		saved, err = scanBirth(tx.QueryRowContext(ctx, stmt, b.InfantId, b.MotherId, b.PregnancyId, b.BirthDate, user, time.Now()))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error linking infant %d to pregnancy: %w", b.InfantId, err)
+	}
+	return &saved, nil
+}
@@ -0,0 +1,52 @@
+package infant
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/cache"
+)
+
+// Cached is a Store that keeps infants and their mothers in a cache, so that handlers
+// do not ask ACSIS for them on every request. The other methods are not cached.
+type Cached struct {
+	Store
+	cache *cache.Cache
+}
+
+func NewCached(s Store, c *cache.Cache) *Cached {
+	return &Cached{Store: s, cache: c}
+}
+
+// FindInfant only caches infants that exist.
+func (c *Cached) FindInfant(ctx context.Context, infantId int) (*Infant, error) {
+	if v, ok := c.cache.Get(infantId); ok {
+		i := v.(Infant)
+		return &i, nil
+	}
+	i, err := c.Store.FindInfant(ctx, infantId)
+	if err != nil || i == nil {
+		return i, err
+	}
+	c.cache.Set(infantId, *i)
+	return i, nil
+}
+
+// LinkBirth removes the infant from the cache, because the cached infant has the mother of the
+// old link.
+func (c *Cached) LinkBirth(ctx context.Context, b Birth, user string) (*Birth, error) {
+	saved, err := c.Store.LinkBirth(ctx, b, user)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Delete(b.InfantId)
+	return saved, nil
+}
+
+// DeleteMother removes the infants of a mother from c, because the cached infants have the
+// basic info of their mother, e.g. after her record was corrected in ACSIS.
+func DeleteMother(c *cache.Cache, motherId int) {
+	c.DeleteFunc(func(key int, value interface{}) bool {
+		i, ok := value.(Infant)
+		return ok && i.Mother.PatientId == motherId
+	})
+}
@@ -0,0 +1,46 @@
+package infant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/person"
+	"moh.gov.bz/mch/emtct/internal/cache"
+)
+
+func TestCachedLinkBirth(t *testing.T) {
+	f := NewFake()
+	f.Infants[200] = Infant{Infant: person.Person{PatientId: 200}, Mother: person.Person{PatientId: 100}}
+	c := NewCached(f, cache.New(time.Hour))
+	ctx := context.Background()
+
+	if _, err := c.FindInfant(ctx, 200); err != nil {
+		t.Fatal(err)
+	}
+	f.Infants[200] = Infant{Infant: person.Person{PatientId: 200}, Mother: person.Person{PatientId: 101}}
+	if _, err := c.LinkBirth(ctx, Birth{InfantId: 200, MotherId: 101}, "nurse@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	i, err := c.FindInfant(ctx, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Mother.PatientId != 101 {
+		t.Errorf("want: the mother of the new link got: %+v", i.Mother)
+	}
+}
+
+func TestDeleteMother(t *testing.T) {
+	c := cache.New(time.Hour)
+	c.Set(200, Infant{Infant: person.Person{PatientId: 200}, Mother: person.Person{PatientId: 100}})
+	c.Set(201, Infant{Infant: person.Person{PatientId: 201}, Mother: person.Person{PatientId: 101}})
+
+	DeleteMother(c, 100)
+	if _, ok := c.Get(200); ok {
+		t.Error("want: the infant of the mother removed")
+	}
+	if _, ok := c.Get(201); !ok {
+		t.Error("want: the infant of another mother kept")
+	}
+}
@@ -1,45 +1,75 @@
 package infant
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
 )
 
-func (d *Infants) FindInfantDiagnoses(infantId int) ([]Diagnoses, error) {
+// FindInfantDiagnoses returns the page q of the diagnoses of an infant, by diagnosis time. The
+// ids of the diagnoses are numbers, so a cursor with another id is a paging.ErrInvalidCursor.
+func (d *Infants) FindInfantDiagnoses(ctx context.Context, infantId int, q paging.Query) ([]Diagnoses, paging.Info, error) {
+	if q.After != nil {
+		if _, err := q.After.IntId(); err != nil {
+			return nil, paging.Info{}, err
+		}
+	}
+	ctx, cancel := d.Acsis.WithTimeout(ctx)
+	defer cancel()
+	from := `
+		FROM acsis_adt_encounters e
+			INNER JOIN acsis_adt_encounter_diagnoses aed ON e.encounter_id=aed.encounter_id
+			INNER JOIN acsis_adt_icd10_diseases aai10d on aed.disease_id = aai10d.disease_id
+			INNER JOIN acsis_hr_staff hs ON aed.doctor_id=hs.staff_id
+			INNER JOIN acsis_people ap on hs.person_id = ap.person_id
+		WHERE e.patient_id=$1`
+	filter, args := q.Filter("aed.diagnosis_time", 1)
+	var total int
+	count := `SELECT count(*)` + from + filter
+	if err := d.Acsis.QueryRowContext(ctx, count, append([]interface{}{infantId}, args...)...).Scan(&total); err != nil {
+		return nil, paging.Info{}, fmt.Errorf("error counting the infant diagnoses in acsis: %w", err)
+	}
+	seek, args := q.Seek("aed.diagnosis_time", "aed.encounter_diagnosis_id", 1)
 	stmt := `
 		SELECT
+			aed.encounter_diagnosis_id,
 			aed.disease_id,
 			e.patient_id,
 			aai10d.name as diagnosis,
 			aed.notes,
 			ap.first_name || ' ' || ap.last_name as doctor,
-			aed.diagnosis_time
-		FROM acsis_adt_encounters e
-			INNER JOIN acsis_adt_encounter_diagnoses aed ON e.encounter_id=aed.encounter_id
-			INNER JOIN acsis_adt_icd10_diseases aai10d on aed.disease_id = aai10d.disease_id
-			INNER JOIN acsis_hr_staff hs ON aed.doctor_id=hs.staff_id
-			INNER JOIN acsis_people ap on hs.person_id = ap.person_id
-		WHERE e.patient_id=$1 
-		ORDER BY aed.diagnosis_time DESC;
-`
-	rows, err := d.Acsis.Query(stmt, infantId)
+			aed.diagnosis_time` + from + seek + q.OrderBy("aed.diagnosis_time", "aed.encounter_diagnosis_id")
+	rows, err := d.Acsis.QueryContext(ctx, stmt, append([]interface{}{infantId}, args...)...)
 	if err != nil {
-		return nil, fmt.Errorf("error querying for infant diagnoses from acsis: %+v", err)
+		return nil, paging.Info{}, fmt.Errorf("error querying for infant diagnoses from acsis: %w", err)
 	}
 	defer rows.Close()
 	var diagnoses []Diagnoses
+	// The ids of the encounter diagnoses break the ties between diagnoses made at the same
+	// time, for the cursor.
+	var ids []int
 	for rows.Next() {
 		var d Diagnoses
-		err := rows.Scan(&d.DiagnosisId,
+		var id int
+		err := rows.Scan(
+			&id,
+			&d.DiagnosisId,
 			&d.PatientId,
 			&d.Diagnosis,
 			&d.Comments,
 			&d.Doctor,
 			&d.Date)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning infant diagnosis: %+v", err)
+			return nil, paging.Info{}, fmt.Errorf("error scanning infant diagnosis: %w", err)
 		}
 		diagnoses = append(diagnoses, d)
+		ids = append(ids, id)
 	}
 
-	return diagnoses, nil
+	info, n := q.NewInfo(total, len(diagnoses), func(i int) paging.Cursor {
+		return paging.Cursor{Date: diagnoses[i].Date, Id: strconv.Itoa(ids[i])}
+	})
+	return diagnoses[:n], info, nil
 }
@@ -0,0 +1,113 @@
+package infant
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+)
+
+// Fake is an in-memory Store for tests. The ACSIS fields are seeded by the test before it
+// is used; births are also written by the fake.
+type Fake struct {
+	mu sync.Mutex
+	// Infants, Births, Diagnoses and SyphilisTreatments are keyed by infant id.
+	Infants            map[int]Infant
+	Births             map[int]Birth
+	Diagnoses          map[int][]Diagnoses
+	SyphilisTreatments map[int][]prescription.Prescription
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		Infants:            make(map[int]Infant),
+		Births:             make(map[int]Birth),
+		Diagnoses:          make(map[int][]Diagnoses),
+		SyphilisTreatments: make(map[int][]prescription.Prescription),
+	}
+}
+
+func (f *Fake) FindInfant(ctx context.Context, infantId int) (*Infant, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.Infants[infantId]
+	if !ok {
+		return nil, nil
+	}
+	return &i, nil
+}
+
+// FindPregnancyInfants returns the infants whose births are linked to the pregnancy, by id.
+func (f *Fake) FindPregnancyInfants(ctx context.Context, pregnancy pregnancy.Pregnancy) ([]Infant, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var infants []Infant
+	for _, b := range f.Births {
+		if b.PregnancyId == nil || *b.PregnancyId != pregnancy.PregnancyId {
+			continue
+		}
+		if i, ok := f.Infants[b.InfantId]; ok {
+			infants = append(infants, i)
+		}
+	}
+	sort.Slice(infants, func(i, j int) bool { return infants[i].Infant.PatientId < infants[j].Infant.PatientId })
+	return infants, nil
+}
+
+func (f *Fake) FindInfantDiagnoses(ctx context.Context, infantId int, q paging.Query) ([]Diagnoses, paging.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if q.After != nil {
+		if _, err := q.After.IntId(); err != nil {
+			return nil, paging.Info{}, err
+		}
+	}
+	ds := f.Diagnoses[infantId]
+	keys := make([]paging.Cursor, len(ds))
+	for i, d := range ds {
+		keys[i] = paging.Cursor{Date: d.Date, Id: strconv.Itoa(i)}
+	}
+	idx, info := q.Select(keys)
+	var page []Diagnoses
+	for _, i := range idx {
+		page = append(page, ds[i])
+	}
+	return page, info, nil
+}
+
+func (f *Fake) FindInfantSyphilisTreatment(ctx context.Context, patientId int) ([]prescription.Prescription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.SyphilisTreatments[patientId], nil
+}
+
+func (f *Fake) FindBirth(ctx context.Context, infantId int) (*Birth, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.Births[infantId]
+	if !ok {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+func (f *Fake) LinkBirth(ctx context.Context, b Birth, user string) (*Birth, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if old, ok := f.Births[b.InfantId]; ok {
+		old.MotherId = b.MotherId
+		old.PregnancyId = b.PregnancyId
+		b = old
+	}
+	b.LinkedManually = true
+	b.LinkedBy = &user
+	b.LinkedAt = &now
+	f.Births[b.InfantId] = b
+	return &b, nil
+}
@@ -1,13 +1,19 @@
 package infant
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"time"
 
+	"moh.gov.bz/mch/emtct/internal/business/data/person"
 	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
 )
 
-func (d *Infants) FindInfant(infantId int) (*Infant, error) {
+// FindInfant returns an infant and its mother, or nil if the infant does not exist. When the infant was linked to a different
+// mother by hand, that mother is returned instead of the one recorded in acsis_hc_births.
+func (d *Infants) FindInfant(ctx context.Context, infantId int) (*Infant, error) {
+	ctx, cancel := d.Acsis.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	SELECT 
 	       b.patient_id,
@@ -30,7 +36,7 @@ func (d *Infants) FindInfant(infantId int) (*Infant, error) {
 	LIMIT 1;
 `
 	var infant Infant
-	row := d.Acsis.QueryRow(stmt, infantId)
+	row := d.Acsis.QueryRowContext(ctx, stmt, infantId)
 	err := row.Scan(
 		&infant.Infant.PatientId,
 		&infant.Infant.FirstName,
@@ -42,15 +48,81 @@ func (d *Infants) FindInfant(infantId int) (*Infant, error) {
 		&infant.Mother.LastName,
 		&infant.Mother.Dob,
 		&infant.Mother.PatientId)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+	default:
+		return nil, fmt.Errorf("error querying infant basic information from acsis: %w", err)
+	}
+
+	birth, err := d.FindBirth(ctx, infantId)
 	if err != nil {
-		return nil, fmt.Errorf("error querying infant basic information from acsis: %+v", err)
+		return nil, err
+	}
+	if birth != nil && birth.MotherId != infant.Mother.PatientId {
+		mother, err := d.findPerson(ctx, birth.MotherId)
+		if err != nil {
+			return nil, err
+		}
+		infant.Mother = *mother
 	}
 
 	return &infant, nil
 }
 
-// FindPregnancyInfant returns the infant that was born after the mother's current LMP.
-func (d *Infants) FindPregnancyInfant(pregnancy pregnancy.Pregnancy) (*Infant, error) {
+func (d *Infants) findPerson(ctx context.Context, patientId int) (*person.Person, error) {
+	ctx, cancel := d.Acsis.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT pt.patient_id, ppl.first_name, ppl.middle_name, ppl.last_name, pt.birth_date
+	FROM acsis_hc_patients pt
+	INNER JOIN acsis_people ppl ON pt.person_id = ppl.person_id
+	WHERE pt.patient_id=$1;
+`
+	var p person.Person
+	err := d.Acsis.QueryRowContext(ctx, stmt, patientId).Scan(&p.PatientId, &p.FirstName, &p.MiddleName, &p.LastName, &p.Dob)
+	if err != nil {
+		return nil, fmt.Errorf("error querying patient %d from acsis: %w", patientId, err)
+	}
+	return &p, nil
+}
+
+// FindPregnancyInfants returns the infants that are linked to the pregnancy by the births etl
+// or by hand, the latest born first. Pregnancies that have not been linked yet fall back to the
+// infant that was born in the Period of the pregnancy. It returns nil if no infant is found.
+func (d *Infants) FindPregnancyInfants(ctx context.Context, pregnancy pregnancy.Pregnancy) ([]Infant, error) {
+	births, err := d.FindBirthsByPregnancy(ctx, pregnancy.PregnancyId)
+	if err != nil {
+		return nil, err
+	}
+	if len(births) == 0 {
+		infant, err := d.findInfantBornAfterLmp(ctx, pregnancy)
+		if err != nil || infant == nil {
+			return nil, err
+		}
+		return []Infant{*infant}, nil
+	}
+	var infants []Infant
+	for _, b := range births {
+		infant, err := d.FindInfant(ctx, b.InfantId)
+		if err != nil {
+			return nil, err
+		}
+		if infant != nil {
+			infants = append(infants, *infant)
+		}
+	}
+	return infants, nil
+}
+
+func (d *Infants) findInfantBornAfterLmp(ctx context.Context, pregnancy pregnancy.Pregnancy) (*Infant, error) {
+	lmp, end, ok := pregnancy.Period()
+	if !ok {
+		return nil, nil
+	}
+	ctx, cancel := d.Acsis.WithTimeout(ctx)
+	defer cancel()
 	// Find pregnancy that corresponds to this id
 	stmt := `
 	SELECT 
@@ -73,11 +145,10 @@ func (d *Infants) FindPregnancyInfant(pregnancy pregnancy.Pregnancy) (*Infant, e
 	LIMIT 1;
 `
 	var infant Infant
-	rightYear := pregnancy.Lmp.Add(time.Hour * 24 * 7 * 54)
-	row := d.Acsis.QueryRow(stmt,
+	row := d.Acsis.QueryRowContext(ctx, stmt,
 		pregnancy.PatientId,
-		pregnancy.Lmp.Format("2006-01-02"),
-		rightYear.Format("2006-01-02"))
+		lmp.Format("2006-01-02"),
+		end.Format("2006-01-02"))
 	err := row.Scan(
 		&infant.Infant.PatientId,
 		&infant.Infant.FirstName,
@@ -88,8 +159,11 @@ func (d *Infants) FindPregnancyInfant(pregnancy pregnancy.Pregnancy) (*Infant, e
 		&infant.Mother.MiddleName,
 		&infant.Mother.LastName,
 		&infant.Mother.Dob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error querying infant basic information from acsis: %+v", err)
+		return nil, fmt.Errorf("error querying infant basic information from acsis: %w", err)
 	}
 	infant.Mother.PatientId = pregnancy.PatientId
 
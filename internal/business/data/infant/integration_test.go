@@ -0,0 +1,116 @@
+package infant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestInfantsIntegration(t *testing.T) {
+	acsis := dbtest.OpenAcsis(t)
+	dbtest.Load(t, acsis.DB, "mother_infant")
+	d := New(acsis, dbtest.OpenEmtct(t))
+	ctx := context.Background()
+
+	t.Run("infant", func(t *testing.T) {
+		infant, err := d.FindInfant(ctx, 200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if infant.Infant.FirstName != "Jose" || infant.Mother.FirstName != "Maria" || infant.Mother.PatientId != 100 {
+			t.Errorf("want: Jose and his mother Maria got: %+v", infant)
+		}
+		if infant, err := d.FindInfant(ctx, 999); err != nil || infant != nil {
+			t.Errorf("want: no infant 999 and no error got: %+v, %v", infant, err)
+		}
+	})
+
+	t.Run("pregnancy infant", func(t *testing.T) {
+		lmp := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+		infants, err := d.FindPregnancyInfants(ctx, pregnancy.Pregnancy{PatientId: 100, PregnancyId: 7, Lmp: &lmp})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(infants) != 1 || infants[0].Infant.PatientId != 200 {
+			t.Errorf("want: infant 200 born after the lmp got: %+v", infants)
+		}
+	})
+
+	t.Run("diagnoses", func(t *testing.T) {
+		diagnoses, _, err := d.FindInfantDiagnoses(ctx, 200, paging.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diagnoses) != 1 {
+			t.Fatalf("want: 1 diagnosis got: %d", len(diagnoses))
+		}
+		if diagnoses[0].Diagnosis != "Low birth weight" || diagnoses[0].Doctor != "Ana Chan" || diagnoses[0].Comments != "2.4 kg" {
+			t.Errorf("want: low birth weight diagnosed by Ana Chan got: %+v", diagnoses[0])
+		}
+	})
+
+	t.Run("syphilis treatment", func(t *testing.T) {
+		treatments, err := d.FindInfantSyphilisTreatment(ctx, 200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(treatments) != 1 || treatments[0].PatientId != 200 {
+			t.Errorf("want: the infant's penicillin got: %+v", treatments)
+		}
+	})
+
+	t.Run("births", func(t *testing.T) {
+		births, err := d.FindBirthsInBhis(ctx, []int{100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(births) != 1 || births[0].InfantId != 200 || *births[0].BirthStatus != "Live Birth" {
+			t.Fatalf("want: the live birth of infant 200 got: %+v", births)
+		}
+		pregnancyId := 7
+		births[0].PregnancyId = &pregnancyId
+		result, err := d.UpsertBirths(ctx, births)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Inserted != 1 {
+			t.Errorf("want: 1 inserted birth got: %+v", result)
+		}
+		result, err = d.UpsertBirths(ctx, births)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Unchanged != 1 {
+			t.Errorf("want: 1 unchanged birth got: %+v", result)
+		}
+
+		other := 8
+		linked, err := d.LinkBirth(ctx, Birth{InfantId: 200, MotherId: 100, PregnancyId: &other}, "nurse@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !linked.LinkedManually || *linked.PregnancyId != other {
+			t.Errorf("want: infant 200 linked to pregnancy 8 by hand got: %+v", linked)
+		}
+		var audited int
+		err = d.Emtct.QueryRowContext(ctx, `SELECT count(*) FROM audit_log WHERE entity_type='infants' AND entity_id='200' AND action='update'`).
+			Scan(&audited)
+		if err != nil || audited != 1 {
+			t.Errorf("want: the link in the audit log got: %d, %v", audited, err)
+		}
+		if _, err := d.UpsertBirths(ctx, births); err != nil {
+			t.Fatal(err)
+		}
+		b, err := d.FindBirth(ctx, 200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *b.PregnancyId != other {
+			t.Errorf("want: the link made by hand to be kept got: pregnancy %d", *b.PregnancyId)
+		}
+	})
+}
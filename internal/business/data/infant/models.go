@@ -1,18 +1,19 @@
 package infant
 
 import (
-	"database/sql"
 	"time"
 
 	"moh.gov.bz/mch/emtct/internal/business/data/person"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type Infants struct {
-	Acsis *sql.DB
+	Acsis *db.AcsisDb
+	Emtct *db.EmtctDb
 }
 
-func New(db *sql.DB) Infants {
-	return Infants{Acsis: db}
+func New(acsis *db.AcsisDb, emtct *db.EmtctDb) Infants {
+	return Infants{Acsis: acsis, Emtct: emtct}
 }
 
 type Diagnoses struct {
@@ -29,24 +30,16 @@ type Infant struct {
 	Mother person.Person `json:"mother"`
 }
 
-type HivScreening struct {
-	Id                     string     `json:"id"`
-	PatientId              int        `json:"patientId"`
-	MotherId               int        `json:"motherId"`
-	TestName               string     `json:"testName"`
-	ScreeningDate          time.Time  `json:"screeningDate"`
-	DateSampleReceivedAtHq *time.Time `json:"dateSampleReceivedAtHq,omitEmpty"`
-	SampleCode             string     `json:"sampleCode"`
-	DateSampleShipped      *time.Time `json:"dateSampleShipped"`
-	Destination            string     `json:"destination"`
-	DateResultReceived     *time.Time `json:"dateResultReceived,omitEmpty"`
-	DateSampleTaken        *time.Time `json:"dateSampleTaken,omitEmpty"`
-	DueDate                *time.Time `json:"dueDate,omitEmpty"`
-	Result                 string     `json:"result"`
-	DateResultShared       *time.Time `json:"dateResultShared,omitEmpty"`
-	Timely                 bool       `json:"timely"`
-	CreatedAt              time.Time  `json:"createdAt"`
-	UpdatedAt              *time.Time `json:"updatedAt"`
-	CreatedBy              string     `json:"createdBy"`
-	UpdatedBy              *string    `json:"updatedBy"`
+// Birth links an infant to its mother and to the pregnancy it was born from.
+// Births are copied from acsis_hc_births into the emtct infants table by the births etl.
+// A birth that was linked by hand keeps its pregnancy and mother when the etl runs again.
+type Birth struct {
+	InfantId       int        `json:"infantId"`
+	MotherId       int        `json:"motherId"`
+	PregnancyId    *int       `json:"pregnancyId"`
+	BirthDate      *time.Time `json:"birthDate"`
+	BirthStatus    *string    `json:"birthStatus"`
+	LinkedManually bool       `json:"linkedManually"`
+	LinkedBy       *string    `json:"linkedBy"`
+	LinkedAt       *time.Time `json:"linkedAt"`
 }
@@ -0,0 +1,25 @@
+package infant
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+)
+
+// Store is implemented by *Infants, by Cached and by Fake.
+type Store interface {
+	FindInfant(ctx context.Context, infantId int) (*Infant, error)
+	FindPregnancyInfants(ctx context.Context, pregnancy pregnancy.Pregnancy) ([]Infant, error)
+	FindInfantDiagnoses(ctx context.Context, infantId int, q paging.Query) ([]Diagnoses, paging.Info, error)
+	FindInfantSyphilisTreatment(ctx context.Context, patientId int) ([]prescription.Prescription, error)
+	FindBirth(ctx context.Context, infantId int) (*Birth, error)
+	LinkBirth(ctx context.Context, b Birth, user string) (*Birth, error)
+}
+
+var (
+	_ Store = (*Infants)(nil)
+	_ Store = (*Fake)(nil)
+	_ Store = (*Cached)(nil)
+)
@@ -1,13 +1,16 @@
 package infant
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
 )
 
-func (d *Infants) FindInfantSyphilisTreatment(patientId int) ([]prescription.Prescription, error) {
+func (d *Infants) FindInfantSyphilisTreatment(ctx context.Context, patientId int) ([]prescription.Prescription, error) {
+	ctx, cancel := d.Acsis.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 SELECT
 		    adep.encounter_pharmaceutical_id,
@@ -27,18 +30,18 @@ SELECT
 		  AND aap.pharmaceutical_id=510
 		ORDER BY adep.prescribed_time DESC;
 `
-	rows, err := d.Acsis.Query(stmt, patientId)
-	defer rows.Close()
+	rows, err := d.Acsis.QueryContext(ctx, stmt, patientId)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving syphilis treatment for infant from acsis: %+v", err)
+		return nil, fmt.Errorf("error retrieving syphilis treatment for infant from acsis: %w", err)
 	}
+	defer rows.Close()
 	var prescriptions []prescription.Prescription
 	for rows.Next() {
 		var p prescription.Prescription
 		var totalDoses sql.NullInt64
 		err := rows.Scan(&p.Id, &totalDoses, &p.Pharmaceutical, &p.Frequency, &p.Strength, &p.Comments, &p.PrescribedTime)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning syphilis prescriptions for infant from acsis: %+v", err)
+			return nil, fmt.Errorf("error scanning syphilis prescriptions for infant from acsis: %w", err)
 		}
 		p.PatientId = patientId
 		if totalDoses.Valid {
@@ -0,0 +1,111 @@
+package labs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+// labTests is the number of test request items of the patient of the benchmarks.
+const labTests = 30
+
+// seedLabTests gives patient 1 a pregnancy with labTests test request items, each with a
+// sample and a result.
+func seedLabTests(b *testing.B, acsis *db.AcsisDb) time.Time {
+	lmp := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	stmts := []string{
+		`INSERT INTO acsis_hc_patients (patient_id) VALUES (1)`,
+		`INSERT INTO acsis_adt_encounters (encounter_id, patient_id, encounter_type, begin_time) VALUES (1, 1, 'M', '2020-02-01')`,
+		`INSERT INTO acsis_lab_tests (test_id, name) VALUES (1, 'HIV')`,
+		`INSERT INTO acsis_lab_user_defined_list_items (user_defined_list_item_id, name) VALUES (1, 'Negative')`,
+		`INSERT INTO acsis_lab_test_requests (test_request_id, encounter_id, order_received_by_lab_time, last_modified_time)
+			SELECT i, 1, '2020-02-01'::timestamp + i * interval '1 day', now() FROM generate_series(1, $1) i`,
+		`INSERT INTO acsis_lab_test_request_items (test_request_item_id, test_request_id, test_id, released_time)
+			SELECT i, i, 1, '2020-02-03'::timestamp + i * interval '1 day' FROM generate_series(1, $1) i`,
+		`INSERT INTO acsis_lab_test_request_specimen_types (test_request_specimen_type_id, test_request_id)
+			SELECT i, i FROM generate_series(1, $1) i`,
+		`INSERT INTO acsis_lab_test_samples (test_sample_id, test_request_specimen_type_id, collected_time)
+			SELECT i, i, '2020-02-01'::timestamp + i * interval '1 day' FROM generate_series(1, $1) i`,
+		`INSERT INTO acsis_lab_test_results (test_result_id, label) SELECT i, 'Result' FROM generate_series(1, $1) i`,
+		`INSERT INTO acsis_lab_test_request_results_collected (test_request_item_id, test_result_id, user_defined_list_value)
+			SELECT i, i, 1 FROM generate_series(1, $1) i`,
+	}
+	for _, stmt := range stmts {
+		var args []interface{}
+		if strings.Contains(stmt, "$1") {
+			args = append(args, labTests)
+		}
+		if _, err := acsis.Exec(stmt, args...); err != nil {
+			b.Fatalf("error seeding the lab tests: %v", err)
+		}
+	}
+	return lmp
+}
+
+// BenchmarkFindTestSamples compares one sample query per test request item, as they used to
+// be looked up, with the single query for all of them.
+func BenchmarkFindTestSamples(b *testing.B) {
+	acsis := dbtest.OpenAcsis(b)
+	lmp := seedLabTests(b, acsis)
+	d := New(acsis, nil)
+	ctx := context.Background()
+	items, err := d.findCurrentTestRequestItems(ctx, 1, &lmp)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(items) != labTests {
+		b.Fatalf("want: %d test request items got: %d", labTests, len(items))
+	}
+
+	b.Run("per item", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, t := range items {
+				if _, err := d.findTestSamples(ctx, []testRequestItem{t}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			samples, err := d.findTestSamples(ctx, items)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(samples) != labTests {
+				b.Fatalf("want: %d samples got: %d", labTests, len(samples))
+			}
+		}
+	})
+}
+
+func BenchmarkFindLabTestsDuringPregnancy(b *testing.B) {
+	acsis := dbtest.OpenAcsis(b)
+	lmp := seedLabTests(b, acsis)
+	ctx := context.Background()
+	for _, bm := range []struct {
+		name     string
+		parallel int
+	}{
+		{"sequential", 1},
+		{"parallel", db.DefaultMaxParallelQueries},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			acsis.MaxParallelQueries = bm.parallel
+			d := New(acsis, nil)
+			for n := 0; n < b.N; n++ {
+				results, err := d.FindLabTestsDuringPregnancy(ctx, 1, &lmp)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(results) != labTests {
+					b.Fatalf("want: %d lab results got: %d", labTests, len(results))
+				}
+			}
+		})
+	}
+}
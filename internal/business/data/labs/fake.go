@@ -0,0 +1,49 @@
+package labs
+
+import (
+	"context"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// Fake is an in-memory Store for tests. Its fields are seeded by the test before it is used.
+type Fake struct {
+	// LabResults are the ACSIS lab results, keyed by patient id.
+	LabResults map[int][]LabResult
+	// SyncedLabResults are the lab results in the emtct database, keyed by pregnancy id.
+	// A pregnancy that is not in the map has never been synced.
+	SyncedLabResults map[int][]LabResult
+	// SyphilisScreenings are keyed by infant id.
+	SyphilisScreenings map[int][]SyphilisScreening
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		LabResults:         make(map[int][]LabResult),
+		SyncedLabResults:   make(map[int][]LabResult),
+		SyphilisScreenings: make(map[int][]SyphilisScreening),
+	}
+}
+
+// FindLabTestsDuringPregnancy returns the lab results of the patient that were ordered
+// after the LMP.
+func (f *Fake) FindLabTestsDuringPregnancy(ctx context.Context, patientId int, lmp *time.Time) ([]LabResult, error) {
+	var results []LabResult
+	for _, r := range f.LabResults[patientId] {
+		if lmp != nil && r.DateOrderReceivedByLab != nil && r.DateOrderReceivedByLab.Before(*lmp) {
+			continue
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (f *Fake) FindSyncedLabResults(ctx context.Context, pregnancyId int) ([]LabResult, bool, error) {
+	results, synced := f.SyncedLabResults[pregnancyId]
+	return results, synced, nil
+}
+
+func (f *Fake) FindInfantSyphilisScreenings(ctx context.Context, infantId int, birthDate time.Time, rules screeningRules.Rules) ([]SyphilisScreening, error) {
+	return f.SyphilisScreenings[infantId], nil
+}
@@ -0,0 +1,81 @@
+package labs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestLabsIntegration(t *testing.T) {
+	acsis := dbtest.OpenAcsis(t)
+	dbtest.Load(t, acsis.DB, "mother_infant")
+	d := New(acsis, dbtest.OpenEmtct(t))
+	ctx := context.Background()
+	lmp := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	results, err := d.FindLabTestsDuringPregnancy(ctx, 100, &lmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("want: 2 lab results got: %d", len(results))
+	}
+	hiv := results[0]
+	if hiv.TestName != "HIV - HIV 1/2 Ab" || hiv.TestResult != "Reactive" {
+		t.Errorf("want: the reactive HIV test first got: %+v", hiv)
+	}
+	if want := time.Date(2020, 2, 15, 8, 0, 0, 0, time.UTC); hiv.DateSampleTaken == nil || !hiv.DateSampleTaken.Equal(want) {
+		t.Errorf("want: sample taken at %s got: %v", want, hiv.DateSampleTaken)
+	}
+	if results[1].TestName != "RPR - Titre" || results[1].TestResult != "Negative" {
+		t.Errorf("want: the negative RPR test got: %+v", results[1])
+	}
+
+	t.Run("synced results", func(t *testing.T) {
+		_, synced, err := d.FindSyncedLabResults(ctx, 7)
+		if err != nil || synced {
+			t.Fatalf("want: pregnancy 7 not synced yet got: %v, %v", synced, err)
+		}
+		if err := d.SaveLabResults(ctx, "run-1", 7, results); err != nil {
+			t.Fatal(err)
+		}
+		saved, synced, err := d.FindSyncedLabResults(ctx, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !synced || len(saved) != len(results) {
+			t.Errorf("want: %d synced results got: %d (synced: %v)", len(results), len(saved), synced)
+		}
+	})
+
+	t.Run("infant syphilis screenings", func(t *testing.T) {
+		birth := time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)
+		// The rules that the migration adds.
+		rules, err := NewSyphilisScreeningRules(d.EmtctDb).FindRules(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rules) != 3 {
+			t.Fatalf("want: the 3 rules of the migration got: %+v", rules)
+		}
+		screenings, err := d.FindInfantSyphilisScreenings(ctx, 200, birth, rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(screenings) != 2 {
+			t.Fatalf("want: the 2 RPRs got: %+v", screenings)
+		}
+		rpr, reactive := screenings[0], screenings[1]
+		if rpr.TestName != "RPR" || rpr.Result != "Negative" || rpr.Timely != NotTimely {
+			t.Errorf("want: the negative RPR, taken after the due date at birth got: %+v", rpr)
+		}
+		if reactive.TestName != "RPR" || reactive.Result != "Reactive" || reactive.Titre != "1:4" || reactive.Timely != Timely {
+			t.Errorf("want: the timely reactive RPR with a titre of 1:4 got: %+v", reactive)
+		}
+		if want := time.Date(2021, 1, 10, 9, 30, 0, 0, time.UTC); reactive.DateSampleTaken == nil || !reactive.DateSampleTaken.Equal(want) {
+			t.Errorf("want: the reactive RPR sample taken at %s got: %v", want, reactive.DateSampleTaken)
+		}
+	})
+}
@@ -8,10 +8,11 @@ import (
 
 type Labs struct {
 	AcsisDb *db.AcsisDb
+	EmtctDb *db.EmtctDb
 }
 
-func New(acsisDb *db.AcsisDb) Labs {
-	return Labs{AcsisDb: acsisDb}
+func New(acsisDb *db.AcsisDb, emtctDb *db.EmtctDb) Labs {
+	return Labs{AcsisDb: acsisDb, EmtctDb: emtctDb}
 }
 
 type LabResult struct {
@@ -35,6 +36,8 @@ const (
 	NotAvailable SampleTimeliness = "N/A"
 )
 
+// SyphilisScreening is a syphilis test of an infant, e.g. an RPR. Titre is the dilution of a reactive
+// test, e.g. 1:8, and it is empty when there is none.
 type SyphilisScreening struct {
 	Id                 int              `json:"id"`
 	PatientId          int              `json:"patientId"`
@@ -44,6 +47,7 @@ type SyphilisScreening struct {
 	DateSampleTaken    *time.Time       `json:"dateSampleTaken,omitEmpty"`
 	DueDate            *time.Time       `json:"dueDate,omitEmpty"`
 	Result             string           `json:"result"`
+	Titre              string           `json:"titre"`
 	DateResultShared   *time.Time       `json:"dateResultShared,omitEmpty"`
 	Timely             SampleTimeliness `json:"timely"`
 }
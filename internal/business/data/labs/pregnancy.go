@@ -1,10 +1,12 @@
 package labs
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -25,7 +27,9 @@ type testRequestItem struct {
 
 // findCurrentTestRequestItems finds all test requests in a given encounter. This is used when
 // searching for a pregnant woman's test results during pregnancy.
-func (d *Labs) findCurrentTestRequestItems(patientId int, lmp *time.Time) ([]testRequestItem, error) {
+func (d *Labs) findCurrentTestRequestItems(ctx context.Context, patientId int, lmp *time.Time) ([]testRequestItem, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	if lmp == nil {
 		return []testRequestItem{}, nil
 	}
@@ -46,9 +50,9 @@ func (d *Labs) findCurrentTestRequestItems(patientId int, lmp *time.Time) ([]tes
              INNER JOIN acsis_lab_tests t ON tri.test_id=t.test_id
              WHERE p.patient_id=$1 AND tr.order_received_by_lab_time BETWEEN $2 AND $3`
 	var testRequests []testRequestItem
-	rows, err := d.AcsisDb.Query(stmt, patientId, lmp.Format(layoutISO), endDate.Format(layoutISO))
+	rows, err := d.AcsisDb.QueryContext(ctx, stmt, patientId, lmp.Format(layoutISO), endDate.Format(layoutISO))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving test request items from acsis: %+v", err)
+		return nil, fmt.Errorf("error retrieving test request items from acsis: %w", err)
 	}
 	defer rows.Close()
 
@@ -62,7 +66,7 @@ func (d *Labs) findCurrentTestRequestItems(patientId int, lmp *time.Time) ([]tes
 			&t.DateOrderReceivedByLab,
 			&t.TestName)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning test request item from acsis: %+v", err)
+			return nil, fmt.Errorf("error scanning test request item from acsis: %w", err)
 		}
 		testRequests = append(testRequests, t)
 	}
@@ -83,7 +87,9 @@ type testResult struct {
 	TestLabel              string
 }
 
-func (d *Labs) findTestResults(patientId int, ri []int) ([]testResult, error) {
+func (d *Labs) findTestResults(ctx context.Context, patientId int, ri []int) ([]testResult, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	SELECT 
 	    a.test_result_id,
@@ -105,14 +111,14 @@ func (d *Labs) findTestResults(patientId int, ri []int) ([]testResult, error) {
 		INNER JOIN acsis_lab_test_results a on altrrc.test_result_id = a.test_result_id
 		INNER JOIN acsis_lab_user_defined_list_items aludli on altrrc.user_defined_list_value = aludli.user_defined_list_item_id
 	WHERE p.patient_id=$1
-		AND altr.test_request_id=$2
+		AND altri.test_request_item_id = ANY($2)
 		AND e.active IS TRUE
 	ORDER BY altr.last_modified_time DESC;
 `
 	var results []testResult
-	rows, err := d.AcsisDb.Query(stmt, patientId, ri)
+	rows, err := d.AcsisDb.QueryContext(ctx, stmt, patientId, pq.Array(ri))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving test results for a test request items from acsis: %+v", err)
+		return nil, fmt.Errorf("error retrieving test results for a test request items from acsis: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -128,7 +134,7 @@ func (d *Labs) findTestResults(patientId int, ri []int) ([]testResult, error) {
 			&r.TestResult,
 			&r.TestLabel)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning test results when fetching test results from acsis: %+v", err)
+			return nil, fmt.Errorf("error scanning test results when fetching test results from acsis: %w", err)
 		}
 		results = append(results, r)
 	}
@@ -142,31 +148,56 @@ type testSample struct {
 	TestRequestId     int
 }
 
-func (d *Labs) findTestSamples(tr testRequestItem) (*testSample, error) {
+// findTestSamples returns the first sample that was collected for each test request item,
+// using a single query for all the items.
+func (d *Labs) findTestSamples(ctx context.Context, items []testRequestItem) ([]testSample, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	requestIds := make([]int, len(items))
+	for i, t := range items {
+		requestIds[i] = t.TestRequestId
+	}
+	// The samples belong to the test request, so every item of a request gets the same sample.
 	stmt := `
-	SELECT  
+	SELECT DISTINCT ON (altrst.test_request_id)
+		altrst.test_request_id,
 		alts.test_sample_id,
 		alts.collected_time
 	FROM acsis_lab_test_request_specimen_types altrst
-		INNER JOIN acsis_lab_test_request_items altri ON altri.test_request_item_id=$1
 		LEFT JOIN acsis_lab_test_samples alts ON alts.test_request_specimen_type_id=altrst.test_request_specimen_type_id
-	WHERE altrst.test_request_id=$2
-	ORDER BY alts.collected_time
-	LIMIT 1;
+	WHERE altrst.test_request_id = ANY($1)
+	ORDER BY altrst.test_request_id, alts.collected_time;
 `
-	row := d.AcsisDb.QueryRow(stmt, tr.TestRequestItemId, tr.TestRequestId)
-	var s testSample
-	err := row.Scan(&s.TestSampleId, &s.CollectedTime)
-	switch err {
-	case sql.ErrNoRows:
-		return nil, nil
-	case nil:
-		s.TestRequestId = tr.TestRequestId
-		s.TestRequestItemId = tr.TestRequestItemId
-		return &s, nil
-	default:
-		return nil, fmt.Errorf("error retrieving lab sample info from acsis: %+v", err)
+	rows, err := d.AcsisDb.QueryContext(ctx, stmt, pq.Array(requestIds))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving lab sample info from acsis: %w", err)
+	}
+	defer rows.Close()
+	byRequest := make(map[int]testSample)
+	for rows.Next() {
+		var s testSample
+		if err := rows.Scan(&s.TestRequestId, &s.TestSampleId, &s.CollectedTime); err != nil {
+			return nil, fmt.Errorf("error scanning lab sample info from acsis: %w", err)
+		}
+		byRequest[s.TestRequestId] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error retrieving lab sample info from acsis: %w", err)
+	}
+
+	var samples []testSample
+	for _, t := range items {
+		s, ok := byRequest[t.TestRequestId]
+		if !ok {
+			continue
+		}
+		s.TestRequestItemId = t.TestRequestItemId
+		samples = append(samples, s)
 	}
+	return samples, nil
 }
 
 func findLabResultIndex(rs []LabResult, id int) int {
@@ -180,9 +211,9 @@ func findLabResultIndex(rs []LabResult, id int) int {
 
 func assignSamplesToResults(results []LabResult, samples []testSample) []LabResult {
 	for _, s := range samples {
-		for _, r := range results {
-			if s.TestRequestItemId == r.TestRequestItemId {
-				r.DateSampleTaken = s.CollectedTime
+		for i := range results {
+			if s.TestRequestItemId == results[i].TestRequestItemId {
+				results[i].DateSampleTaken = s.CollectedTime
 			}
 		}
 	}
@@ -203,24 +234,46 @@ func assignSamplesToResults(results []LabResult, samples []testSample) []LabResu
 // 0. Find the latest anc encounter.
 // 1. Find all test request items.
 // 2. Find test results for each test request item
-// 3. Find the samples for each test request item
+// 3. Find the samples for each test request item, at the same time as the results
 // 4. Create the response that will merge the data from all these queries.
-func (d *Labs) FindLabTestsDuringPregnancy(patientId int, lmp *time.Time) ([]LabResult, error) {
+func (d *Labs) FindLabTestsDuringPregnancy(ctx context.Context, patientId int, lmp *time.Time) ([]LabResult, error) {
 	if lmp == nil {
 		return nil, fmt.Errorf("error while retrieving lab tests during pregnancy details from acsis")
 	}
 
-	testItems, err := d.findCurrentTestRequestItems(patientId, lmp)
+	testItems, err := d.findCurrentTestRequestItems(ctx, patientId, lmp)
 	if err != nil {
 		return nil, fmt.Errorf("error finding current test request items from acsis when retrieving lab tests during pregnancy: %w", err)
 	}
 	log.WithFields(log.Fields{"testItems": testItems}).Info("test request Items")
-	var labResults []LabResult
 	var testRequestItemIds []int
 	for _, ti := range testItems {
 		testRequestItemIds = append(testRequestItemIds, ti.TestRequestItemId)
 	}
-	testResults, err := d.findTestResults(patientId, testRequestItemIds)
+	// The results and the samples only depend on the test request items.
+	var testResults []testResult
+	var testSamples []testSample
+	err = d.AcsisDb.Parallel(ctx,
+		func(ctx context.Context) error {
+			rs, err := d.findTestResults(ctx, patientId, testRequestItemIds)
+			if err != nil {
+				return fmt.Errorf("error finding test results when retrieving lab tests during pregnancy: %w", err)
+			}
+			testResults = rs
+			return nil
+		},
+		func(ctx context.Context) error {
+			ss, err := d.findTestSamples(ctx, testItems)
+			if err != nil {
+				return fmt.Errorf("error finding test samples from when retrieving lab tests during prengnacy from acsis: %w", err)
+			}
+			testSamples = ss
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	var labResults []LabResult
 	for _, r := range testResults {
 
 		result := LabResult{
@@ -237,17 +290,6 @@ func (d *Labs) FindLabTestsDuringPregnancy(patientId int, lmp *time.Time) ([]Lab
 		}
 		labResults = append(labResults, result)
 
-	}
-	var testSamples []testSample
-	for _, t := range testItems {
-		sample, err := d.findTestSamples(t)
-		if err != nil {
-			return nil, fmt.Errorf("error finding test samples from when retrieving lab tests during prengnacy from acsis: %+v", err)
-		}
-		if sample != nil {
-			testSamples = append(testSamples, *sample)
-		}
-
 	}
 	results := assignSamplesToResults(labResults, testSamples)
 	return results, nil
@@ -0,0 +1,27 @@
+package labs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssignSamplesToResults(t *testing.T) {
+	collected := time.Date(2020, 5, 4, 9, 0, 0, 0, time.UTC)
+	results := []LabResult{
+		{Id: 1, TestRequestItemId: 10},
+		{Id: 1, TestRequestItemId: 10},
+		{Id: 2, TestRequestItemId: 20},
+	}
+	samples := []testSample{{TestRequestItemId: 10, CollectedTime: &collected}}
+
+	got := assignSamplesToResults(results, samples)
+	if len(got) != 2 {
+		t.Fatalf("want: 2 results got: %d (%+v)", len(got), got)
+	}
+	if got[0].DateSampleTaken == nil || !got[0].DateSampleTaken.Equal(collected) {
+		t.Errorf("want: sample date %v got: %v", collected, got[0].DateSampleTaken)
+	}
+	if got[1].DateSampleTaken != nil {
+		t.Errorf("want: no sample date for a result without a sample got: %v", got[1].DateSampleTaken)
+	}
+}
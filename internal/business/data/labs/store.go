@@ -0,0 +1,20 @@
+package labs
+
+import (
+	"context"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// Store is implemented by *Labs and by Fake.
+type Store interface {
+	FindLabTestsDuringPregnancy(ctx context.Context, patientId int, lmp *time.Time) ([]LabResult, error)
+	FindSyncedLabResults(ctx context.Context, pregnancyId int) (results []LabResult, synced bool, err error)
+	FindInfantSyphilisScreenings(ctx context.Context, infantId int, birthDate time.Time, rules screeningRules.Rules) ([]SyphilisScreening, error)
+}
+
+var (
+	_ Store = (*Labs)(nil)
+	_ Store = (*Fake)(nil)
+)
@@ -0,0 +1,131 @@
+package labs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+)
+
+// FindPregnanciesToSync returns the emtct pregnancies whose lab results have never been
+// synced, and the pregnancies with an LMP at or after since, whose lab results can still change.
+func (d *Labs) FindPregnanciesToSync(ctx context.Context, since time.Time) ([]pregnancy.Pregnancy, error) {
+	ctx, cancel := d.EmtctDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT p.pregnancy_id, p.patient_id, p.lmp
+	FROM pregnancies p
+	LEFT JOIN lab_result_syncs s ON s.pregnancy_id=p.pregnancy_id
+	WHERE p.lmp IS NOT NULL AND (s.pregnancy_id IS NULL OR p.lmp >= $1)
+	ORDER BY p.lmp;
+`
+	rows, err := d.EmtctDb.QueryContext(ctx, stmt, since)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pregnancies to sync lab results for from emtct db: %w", err)
+	}
+	defer rows.Close()
+	var ps []pregnancy.Pregnancy
+	for rows.Next() {
+		var p pregnancy.Pregnancy
+		if err := rows.Scan(&p.PregnancyId, &p.PatientId, &p.Lmp); err != nil {
+			return nil, fmt.Errorf("error scanning pregnancy to sync lab results for: %w", err)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+// FindSyncedLabResults returns the lab results of a pregnancy from the emtct database.
+// synced is false if the lab results of the pregnancy have never been synced, in which case
+// they have to be read from ACSIS.
+func (d *Labs) FindSyncedLabResults(ctx context.Context, pregnancyId int) (results []LabResult, synced bool, err error) {
+	ctx, cancel := d.EmtctDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT EXISTS(SELECT 1 FROM lab_result_syncs WHERE pregnancy_id=$1)`
+	if err := d.EmtctDb.QueryRowContext(ctx, stmt, pregnancyId).Scan(&synced); err != nil {
+		return nil, false, fmt.Errorf("error checking if the lab results of pregnancy %d were synced: %w", pregnancyId, err)
+	}
+	if !synced {
+		return nil, false, nil
+	}
+	stmt = `
+	SELECT id, patient_id, test_name, test_result, test_request_id, test_request_item_id, date_sample_taken,
+	       result_date, released_time, date_order_received_by_lab
+	FROM lab_results
+	WHERE pregnancy_id=$1
+	ORDER BY date_order_received_by_lab DESC, id;
+`
+	rows, err := d.EmtctDb.QueryContext(ctx, stmt, pregnancyId)
+	if err != nil {
+		return nil, true, fmt.Errorf("error retrieving lab results from emtct db: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r LabResult
+		err := rows.Scan(
+			&r.Id,
+			&r.PatientId,
+			&r.TestName,
+			&r.TestResult,
+			&r.TestRequestId,
+			&r.TestRequestItemId,
+			&r.DateSampleTaken,
+			&r.ResultDate,
+			&r.ReleasedTime,
+			&r.DateOrderReceivedByLab)
+		if err != nil {
+			return nil, true, fmt.Errorf("error scanning lab result from emtct db: %w", err)
+		}
+		results = append(results, r)
+	}
+	return results, true, nil
+}
+
+// SaveLabResults replaces the lab results of a pregnancy in the emtct database, and marks
+// the pregnancy as synced by the given etl run.
+func (d *Labs) SaveLabResults(ctx context.Context, runId string, pregnancyId int, results []LabResult) error {
+	tx, err := d.EmtctDb.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction for saving lab results: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM lab_results WHERE pregnancy_id=$1`, pregnancyId); err != nil {
+		return fmt.Errorf("error deleting the lab results of pregnancy %d: %w", pregnancyId, err)
+	}
+	stmt := `
+	INSERT INTO lab_results (id, pregnancy_id, patient_id, test_name, test_result, test_request_id,
+		test_request_item_id, date_sample_taken, result_date, released_time, date_order_received_by_lab)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+`
+	for _, r := range results {
+		_, err := tx.ExecContext(ctx, stmt,
+			r.Id,
+			pregnancyId,
+			r.PatientId,
+			r.TestName,
+			r.TestResult,
+			r.TestRequestId,
+			r.TestRequestItemId,
+			r.DateSampleTaken,
+			r.ResultDate,
+			r.ReleasedTime,
+			r.DateOrderReceivedByLab)
+		if err != nil {
+			return fmt.Errorf("error inserting lab result %d of pregnancy %d: %w", r.Id, pregnancyId, err)
+		}
+	}
+	syncStmt := `
+	INSERT INTO lab_result_syncs (pregnancy_id, synced_at, etl_run_id) VALUES($1, $2, $3)
+	ON CONFLICT (pregnancy_id) DO UPDATE SET synced_at=EXCLUDED.synced_at, etl_run_id=EXCLUDED.etl_run_id;
+`
+	if _, err := tx.ExecContext(ctx, syncStmt, pregnancyId, time.Now(), runId); err != nil {
+		return fmt.Errorf("error marking the lab results of pregnancy %d as synced: %w", pregnancyId, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit the transaction for saving lab results: %w", err)
+	}
+	return nil
+}
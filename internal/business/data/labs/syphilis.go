@@ -1,13 +1,72 @@
 package labs
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
-func (d *Labs) FindInfantSyphilisScreenings(infantId int, birthDate time.Time) ([]SyphilisScreening, error) {
+// SyphilisTestIds are the ids in acsis_lab_tests of the tests that screen infants for syphilis.
+// Test 1 is the RPR, the test that the syphilis screenings were always read from. The VDRL is not
+// listed, as its id in ACSIS is not known.
+var SyphilisTestIds = []int{1}
+
+// NewSyphilisScreeningRules returns the rules of the syphilis screening schedule of infants, which
+// are kept in syphilis_screening_rules.
+func NewSyphilisScreeningRules(emtctDb *db.EmtctDb) screeningRules.Table {
+	return screeningRules.Table{EmtctDb: emtctDb, Name: "syphilis_screening_rules"}
+}
+
+// FindInfantSyphilisScreenings returns the SyphilisTestIds tests of an infant in the 2 years after
+// birthDate, in the order their samples were taken, with their results, titres and timeliness
+// under rules. It queries the test request items first, then their results and samples, the same
+// way as FindLabTestsDuringPregnancy.
+func (d *Labs) FindInfantSyphilisScreenings(ctx context.Context, infantId int, birthDate time.Time, rules screeningRules.Rules) ([]SyphilisScreening, error) {
+	items, err := d.findInfantSyphilisTestItems(ctx, infantId, birthDate)
+	if err != nil {
+		return nil, err
+	}
+	itemIds := make([]int, len(items))
+	for i, t := range items {
+		itemIds[i] = t.TestRequestItemId
+	}
+	var testResults []testResult
+	var testSamples []testSample
+	err = d.AcsisDb.Parallel(ctx,
+		func(ctx context.Context) error {
+			rs, err := d.findTestResults(ctx, infantId, itemIds)
+			if err != nil {
+				return fmt.Errorf("error finding the results of the syphilis screenings of infant %d: %w", infantId, err)
+			}
+			testResults = rs
+			return nil
+		},
+		func(ctx context.Context) error {
+			ss, err := d.findTestSamples(ctx, items)
+			if err != nil {
+				return fmt.Errorf("error finding the samples of the syphilis screenings of infant %d: %w", infantId, err)
+			}
+			testSamples = ss
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return newSyphilisScreenings(infantId, birthDate, rules, items, testResults, testSamples), nil
+}
+
+func (d *Labs) findInfantSyphilisTestItems(ctx context.Context, infantId int, birthDate time.Time) ([]testRequestItem, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
-	SELECT 
+	SELECT
 		p.patient_id,
 		e.encounter_id,
 		tri.test_request_item_id,
@@ -16,77 +75,153 @@ func (d *Labs) FindInfantSyphilisScreenings(infantId int, birthDate time.Time) (
 		tr.order_received_by_lab_time,
 		t.name
 	FROM acsis_hc_patients p
-		INNER JOIN acsis_adt_encounters e ON p.patient_id=e.patient_id AND encounter_type='M'
+		INNER JOIN acsis_adt_encounters e ON p.patient_id=e.patient_id AND e.encounter_type='M'
 		INNER JOIN acsis_lab_test_requests tr ON tr.encounter_id=e.encounter_id
 		INNER JOIN acsis_lab_test_request_items tri ON tr.test_request_id=tri.test_request_id
 		INNER JOIN acsis_lab_tests t ON tri.test_id=t.test_id
-	WHERE 
-		t.test_id=1 AND p.patient_id=$1
+	WHERE
+		tri.test_id = ANY($3) AND p.patient_id=$1
+		AND tr.order_received_by_lab_time >= $2::date
 		AND tr.order_received_by_lab_time < ($2::date + '2 year'::interval);
 `
-	var testRequests []testRequestItem
 	dob := birthDate.Format(layoutISO)
-	rows, err := d.AcsisDb.Query(stmt, infantId, dob)
-	defer rows.Close()
+	rows, err := d.AcsisDb.QueryContext(ctx, stmt, infantId, dob, pq.Array(SyphilisTestIds))
 	if err != nil {
-		return nil, fmt.Errorf("")
+		return nil, fmt.Errorf("error retrieving the syphilis test request items of infant %d from acsis: %w", infantId, err)
 	}
+	defer rows.Close()
+	var items []testRequestItem
 	for rows.Next() {
 		var t testRequestItem
 		err := rows.Scan(&t.PatientId, &t.EncounterId, &t.TestRequestItemId, &t.TestRequestId, &t.ReleasedTime, &t.DateOrderReceivedByLab, &t.TestName)
 		if err != nil {
-			return nil, fmt.Errorf("")
+			return nil, fmt.Errorf("error scanning a syphilis test request item of infant %d: %w", infantId, err)
 		}
-		testRequests = append(testRequests, t)
+		items = append(items, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading the syphilis test request items of infant %d: %w", infantId, err)
 	}
-	var labResults []LabResult
-	//for t := range testRequests {
-	//	testResults, err := d.findTestResults(testRequests[t])
-	//	if err != nil {
-	//		return nil, fmt.Errorf("")
-	//	}
-	//	for _, i := range testResults {
-	//		result := models.LabResult{
-	//			Id:                     i.Id,
-	//			PatientId:              infantId,
-	//			TestResult:             i.TestResult,
-	//			TestName:               fmt.Sprintf("%s - %s", i.TestName, i.TestLabel),
-	//			TestRequestItemId:      i.TestRequestItemId,
-	//			DateSampleTaken:        nil,
-	//			ResultDate:             nil,
-	//			ReleasedTime:           testRequests[t].ReleasedTime,
-	//			DateOrderReceivedByLab: testRequests[t].DateOrderReceivedByLab,
-	//		}
-	//		labResults = append(labResults, result)
-	//	}
-	//}
+	return items, nil
+}
 
-	var testSamples []testSample
-	for _, t := range testRequests {
-		sample, err := d.findTestSamples(t)
-		if err != nil {
-			return nil, fmt.Errorf("error finding test samples from when retrieving lab tests during prengnacy from acsis: %+v", err)
-		}
-		if sample != nil {
-			testSamples = append(testSamples, *sample)
-		}
+// isTitre tells if a result of a test is its titre, e.g. "Titre" or "RPR Titre".
+func isTitre(label string) bool {
+	return strings.Contains(strings.ToLower(label), "titre")
+}
 
+// newSyphilisScreenings makes a screening of every test request item. The titre is the result
+// that is labelled as one when it is a dilution, e.g. 1:8. The result is the other result of the
+// item, or Reactive for a titre without one, or the titre result itself, e.g. Negative.
+func newSyphilisScreenings(infantId int, birthDate time.Time, rules screeningRules.Rules, items []testRequestItem, results []testResult, samples []testSample) []SyphilisScreening {
+	sampleTimes := make(map[int]*time.Time)
+	for _, s := range samples {
+		sampleTimes[s.TestRequestItemId] = s.CollectedTime
 	}
-
-	labResults = assignSamplesToResults(labResults, testSamples)
+	requestIds := make(map[int]int)
 	var screenings []SyphilisScreening
-	for _, l := range labResults {
+	for _, t := range items {
+		requestIds[t.TestRequestItemId] = t.TestRequestId
 		s := SyphilisScreening{
-			Id:                 l.Id,
-			PatientId:          l.PatientId,
-			TestName:           l.TestName,
-			ScreeningDate:      *l.DateOrderReceivedByLab,
-			DateResultReceived: l.ReleasedTime,
-			DateSampleTaken:    l.DateSampleTaken,
-			Result:             l.TestResult,
-			Timely:             NotAvailable,
+			Id:                 t.TestRequestItemId,
+			PatientId:          infantId,
+			TestName:           t.TestName,
+			DateResultReceived: t.ReleasedTime,
+			DateSampleTaken:    sampleTimes[t.TestRequestItemId],
+		}
+		if t.DateOrderReceivedByLab != nil {
+			s.ScreeningDate = *t.DateOrderReceivedByLab
+		}
+		var titreResult string
+		for _, r := range results {
+			if r.TestRequestItemId != t.TestRequestItemId {
+				continue
+			}
+			switch {
+			case !isTitre(r.TestLabel):
+				if s.Result == "" {
+					s.Result = r.TestResult
+				}
+			case titreResult == "":
+				titreResult = r.TestResult
+			}
+		}
+		if strings.Contains(titreResult, ":") {
+			s.Titre = titreResult
+		}
+		if s.Result == "" {
+			if s.Titre != "" {
+				s.Result = "Reactive"
+			} else {
+				s.Result = titreResult
+			}
 		}
 		screenings = append(screenings, s)
 	}
-	return screenings, nil
+	assignSyphilisTimeliness(screenings, requestIds, rules, birthDate)
+	return screenings
+}
+
+// assignSyphilisTimeliness sorts the screenings by the time their samples were taken, the ones
+// without a sample last. The screenings of one test request, e.g. an RPR and a VDRL of the same
+// sample, are one screening of the schedule: they are due on the due date of the rules in force
+// at birth that is nearest to the first sample of the request, so that a missed screening does
+// not push the later ones into the wrong window. A screening is timely when its sample was taken
+// on or before its due date. Screenings without a sample are NotAvailable, and so are all of them
+// when there is no rule in force.
+func assignSyphilisTimeliness(screenings []SyphilisScreening, requestIds map[int]int, rules screeningRules.Rules, birthDate time.Time) {
+	sort.SliceStable(screenings, func(i, j int) bool {
+		a, b := screenings[i].DateSampleTaken, screenings[j].DateSampleTaken
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Before(*b)
+	})
+	inForce := rules.InForce(birthDate)
+	byRequest := make(map[int]screeningRules.Rule)
+	for i := range screenings {
+		s := &screenings[i]
+		s.Timely = NotAvailable
+		if len(inForce) == 0 {
+			continue
+		}
+		requestId := requestIds[s.Id]
+		rule, ok := byRequest[requestId]
+		if !ok && s.DateSampleTaken != nil {
+			rule, ok = nearestRule(inForce, birthDate, *s.DateSampleTaken), true
+			byRequest[requestId] = rule
+		}
+		if !ok {
+			continue
+		}
+		due := rule.DueDate(birthDate)
+		s.DueDate = &due
+		if s.DateSampleTaken == nil {
+			continue
+		}
+		if rule.IsTimely(birthDate, *s.DateSampleTaken) {
+			s.Timely = Timely
+		} else {
+			s.Timely = NotTimely
+		}
+	}
+}
+
+// nearestRule returns the rule whose due date is nearest to the day a sample was taken, the one
+// due first when two are as near.
+func nearestRule(inForce []screeningRules.Rule, birthDate, dateSampleTaken time.Time) screeningRules.Rule {
+	distance := func(r screeningRules.Rule) int {
+		d := screeningRules.Days(r.DueDate(birthDate), dateSampleTaken)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	nearest := inForce[0]
+	for _, r := range inForce[1:] {
+		if distance(r) < distance(nearest) {
+			nearest = r
+		}
+	}
+	return nearest
 }
@@ -0,0 +1,83 @@
+package labs
+
+import (
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// schedule are the rules that the migration adds.
+var schedule = screeningRules.Rules{
+	{Id: 1, TestName: "Birth", DueDays: 3},
+	{Id: 2, TestName: "3 months", DueMonths: 3},
+	{Id: 3, TestName: "6 months", DueMonths: 6},
+}
+
+func TestNewSyphilisScreenings(t *testing.T) {
+	birth := time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)
+	after := func(days int) *time.Time {
+		d := birth.AddDate(0, 0, days).Add(9 * time.Hour)
+		return &d
+	}
+	items := []testRequestItem{
+		{TestRequestItemId: 30, TestRequestId: 3, TestName: "RPR", DateOrderReceivedByLab: after(95)},
+		{TestRequestItemId: 10, TestRequestId: 1, TestName: "RPR", DateOrderReceivedByLab: after(3)},
+		{TestRequestItemId: 20, TestRequestId: 2, TestName: "VDRL", DateOrderReceivedByLab: after(80)},
+		{TestRequestItemId: 40, TestRequestId: 4, TestName: "RPR", DateOrderReceivedByLab: after(200)},
+		{TestRequestItemId: 11, TestRequestId: 1, TestName: "VDRL", DateOrderReceivedByLab: after(3)},
+	}
+	results := []testResult{
+		{TestRequestItemId: 10, TestLabel: "Result", TestResult: "Reactive"},
+		{TestRequestItemId: 10, TestLabel: "Titre", TestResult: "1:8"},
+		{TestRequestItemId: 11, TestLabel: "Result", TestResult: "Reactive"},
+		{TestRequestItemId: 20, TestLabel: "Titre", TestResult: "1:2"},
+		{TestRequestItemId: 30, TestLabel: "Titre", TestResult: "Negative"},
+	}
+	samples := []testSample{
+		{TestRequestItemId: 10, CollectedTime: after(3)},
+		{TestRequestItemId: 11, CollectedTime: after(3)},
+		{TestRequestItemId: 20, CollectedTime: after(80)},
+		{TestRequestItemId: 30, CollectedTime: after(200)},
+	}
+
+	got := newSyphilisScreenings(200, birth, schedule, items, results, samples)
+	// The RPR and VDRL of the first request are both the screening at birth, and the screening
+	// at 3 months is missed, so the RPR taken on day 200 is a late screening at 6 months.
+	want := []struct {
+		id            int
+		result, titre string
+		timely        SampleTimeliness
+		due           *time.Time
+	}{
+		{10, "Reactive", "1:8", Timely, after(3)},
+		{11, "Reactive", "", Timely, after(3)},
+		{20, "Reactive", "1:2", Timely, nil},
+		{30, "Negative", "", NotTimely, nil},
+		{40, "", "", NotAvailable, nil},
+	}
+	threeMonths, sixMonths := birth.AddDate(0, 3, 0), birth.AddDate(0, 6, 0)
+	want[2].due, want[3].due = &threeMonths, &sixMonths
+	if len(got) != len(want) {
+		t.Fatalf("want: %d screenings got: %d (%+v)", len(want), len(got), got)
+	}
+	for i, w := range want {
+		s := got[i]
+		if s.Id != w.id || s.Result != w.result || s.Titre != w.titre || s.Timely != w.timely {
+			t.Errorf("want: screening %d %q %q %s got: %+v", w.id, w.result, w.titre, w.timely, s)
+		}
+		if (s.DueDate == nil) != (w.due == nil) || (w.due != nil && screeningRules.Days(*s.DueDate, *w.due) != 0) {
+			t.Errorf("want: screening %d due %v got: %v", w.id, w.due, s.DueDate)
+		}
+	}
+	if !got[0].ScreeningDate.Equal(*after(3)) || got[0].PatientId != 200 {
+		t.Errorf("want: screened 3 days after birth got: %+v", got[0])
+	}
+
+	// Without rules there are no due dates.
+	for _, s := range newSyphilisScreenings(200, birth, nil, items, results, samples) {
+		if s.DueDate != nil || s.Timely != NotAvailable {
+			t.Errorf("want: no due date without rules got: %+v", s)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package paging selects a page of the records of a patient. The records are ordered by their
+// date, and by their id when they have the same date, so a page can continue after the last
+// record of the previous page however many records were added in the meantime.
+package paging
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// ErrInvalidCursor is returned for a cursor that was not returned as the nextCursor of a page of
+// the same list.
+var ErrInvalidCursor = errors.New("the cursor is not valid")
+
+// Cursor is the position of a record in the order of its list.
+type Cursor struct {
+	Date time.Time
+	Id   string
+}
+
+// String encodes the cursor for the nextCursor of a page.
+func (c Cursor) String() string {
+	s := c.Date.UTC().Format(time.RFC3339Nano) + "|" + c.Id
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// IntId returns the id of the cursor of a list whose ids are numbers, or ErrInvalidCursor.
+func (c Cursor) IntId() (int, error) {
+	id, err := strconv.Atoi(c.Id)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// ParseCursor decodes a cursor that was returned as the nextCursor of a page.
+func ParseCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	date, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{Date: date, Id: parts[1]}, nil
+}
+
+// Query selects a page of records. The zero Query is the first page of DefaultLimit records,
+// newest first.
+type Query struct {
+	Limit int
+	// After is the cursor of the last record of the previous page, nil for the first page.
+	After *Cursor
+	// Ascending sorts the oldest records first.
+	Ascending bool
+	// From and To, when set, only select the records dated from From and before To.
+	From *time.Time
+	To   *time.Time
+}
+
+// Info describes a page. NextCursor is nil on the last page.
+type Info struct {
+	Total      int     `json:"total"`
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+	return q.Limit
+}
+
+func (q Query) direction() (string, string) {
+	if q.Ascending {
+		return "ASC", ">"
+	}
+	return "DESC", "<"
+}
+
+// Filter returns the SQL conditions of the From and To of q on dateCol, each starting with
+// AND, and their arguments. The parameters are numbered after the n arguments of the query.
+func (q Query) Filter(dateCol string, n int) (string, []interface{}) {
+	var cond string
+	var args []interface{}
+	if q.From != nil {
+		args = append(args, *q.From)
+		cond += fmt.Sprintf(" AND %s >= $%d", dateCol, n+len(args))
+	}
+	if q.To != nil {
+		args = append(args, *q.To)
+		cond += fmt.Sprintf(" AND %s < $%d", dateCol, n+len(args))
+	}
+	return cond, args
+}
+
+// Seek returns the SQL conditions of Filter and the condition on dateCol and idCol that skips
+// the records up to the cursor of q, and their arguments.
+func (q Query) Seek(dateCol, idCol string, n int) (string, []interface{}) {
+	cond, args := q.Filter(dateCol, n)
+	if q.After != nil {
+		_, op := q.direction()
+		args = append(args, q.After.Date, q.After.Id)
+		cond += fmt.Sprintf(" AND (%s, %s) %s ($%d, $%d)", dateCol, idCol, op, n+len(args)-1, n+len(args))
+	}
+	return cond, args
+}
+
+// OrderBy returns the ORDER BY and LIMIT of q on dateCol and idCol. One more record than the
+// limit is read to tell whether there is a next page.
+func (q Query) OrderBy(dateCol, idCol string) string {
+	dir, _ := q.direction()
+	return fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT %d", dateCol, dir, idCol, dir, q.limit()+1)
+}
+
+// NewInfo returns the Info of a page of n records that were read with OrderBy, out of total.
+// last returns the cursor of the record at an index. It returns the number of records that
+// are on the page.
+func (q Query) NewInfo(total, n int, last func(i int) Cursor) (Info, int) {
+	info := Info{Total: total, Limit: q.limit()}
+	if n <= q.limit() {
+		return info, n
+	}
+	next := last(q.limit() - 1).String()
+	info.NextCursor = &next
+	return info, q.limit()
+}
+
+// Select returns the indexes of the records on the page of q, in its order, and its Info. The
+// keys are the cursors of every record of the list. It is used by the fakes of the stores.
+func (q Query) Select(keys []Cursor) ([]int, Info) {
+	var idx []int
+	for i, k := range keys {
+		if q.From != nil && k.Date.Before(*q.From) {
+			continue
+		}
+		if q.To != nil && !k.Date.Before(*q.To) {
+			continue
+		}
+		idx = append(idx, i)
+	}
+	total := len(idx)
+	sort.Slice(idx, func(i, j int) bool {
+		return q.before(keys[idx[i]], keys[idx[j]])
+	})
+	if q.After != nil {
+		skip := sort.Search(len(idx), func(i int) bool { return q.before(*q.After, keys[idx[i]]) })
+		idx = idx[skip:]
+	}
+	if len(idx) > q.limit()+1 {
+		idx = idx[:q.limit()+1]
+	}
+	info, n := q.NewInfo(total, len(idx), func(i int) Cursor { return keys[idx[i]] })
+	return idx[:n], info
+}
+
+// before reports whether a comes before b in the order of q.
+func (q Query) before(a, b Cursor) bool {
+	if !a.Date.Equal(b.Date) {
+		return a.Date.Before(b.Date) == q.Ascending
+	}
+	if a.Id == b.Id {
+		return false
+	}
+	return (a.Id < b.Id) == q.Ascending
+}
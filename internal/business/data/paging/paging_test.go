@@ -0,0 +1,64 @@
+package paging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSelect(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 6, d, 0, 0, 0, 0, time.UTC) }
+	keys := []Cursor{{day(3), "c"}, {day(1), "a"}, {day(3), "b"}, {day(2), "d"}, {day(5), "e"}}
+	ids := func(idx []int) string {
+		var s string
+		for _, i := range idx {
+			s += keys[i].Id
+		}
+		return s
+	}
+
+	idx, info := Query{Limit: 2}.Select(keys)
+	if ids(idx) != "ec" || info.Total != 5 || info.NextCursor == nil {
+		t.Fatalf("want: the newest 2 records and a next cursor got: %s %+v", ids(idx), info)
+	}
+	after, err := ParseCursor(*info.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, info = Query{Limit: 2, After: after}.Select(keys)
+	if ids(idx) != "bd" || info.NextCursor == nil {
+		t.Errorf("want: the records after c got: %s %+v", ids(idx), info)
+	}
+
+	from, to := day(2), day(5)
+	idx, info = Query{Ascending: true, From: &from, To: &to}.Select(keys)
+	if ids(idx) != "dbc" || info.Total != 3 || info.NextCursor != nil {
+		t.Errorf("want: the records from the 2nd to the 4th, oldest first got: %s %+v", ids(idx), info)
+	}
+}
+
+func TestSeek(t *testing.T) {
+	from := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	q := Query{From: &from, After: &Cursor{Date: from, Id: "a"}}
+	cond, args := q.Seek("date", "id", 1)
+	if cond != " AND date >= $2 AND (date, id) < ($3, $4)" || len(args) != 3 {
+		t.Errorf("want: the from and the cursor conditions got: %q %v", cond, args)
+	}
+	if s := q.OrderBy("date", "id"); s != " ORDER BY date DESC, id DESC LIMIT 51" {
+		t.Errorf("want: newest first got: %q", s)
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	if _, err := ParseCursor("not a cursor"); err == nil {
+		t.Error("want: an error for a cursor that was not returned by a page")
+	}
+}
+
+func TestIntId(t *testing.T) {
+	if id, err := (Cursor{Id: "42"}).IntId(); err != nil || id != 42 {
+		t.Errorf("want: 42 got: %d, %v", id, err)
+	}
+	if _, err := (Cursor{Id: "a1"}).IntId(); err != ErrInvalidCursor {
+		t.Errorf("want: ErrInvalidCursor got: %v", err)
+	}
+}
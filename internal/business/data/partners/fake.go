@@ -0,0 +1,108 @@
+package partners
+
+import (
+	"context"
+	"sync"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Fake is an in-memory Store for tests.
+type Fake struct {
+	mu         sync.Mutex
+	treatments map[string]prescription.SyphilisTreatment
+	deleted    map[string]prescription.SyphilisTreatment
+}
+
+func NewFake(ts ...prescription.SyphilisTreatment) *Fake {
+	f := &Fake{treatments: make(map[string]prescription.SyphilisTreatment), deleted: make(map[string]prescription.SyphilisTreatment)}
+	for _, t := range ts {
+		f.treatments[t.Id] = t
+	}
+	return f
+}
+
+func (f *Fake) AddPartnerSyphilisTreatment(ctx context.Context, treatment prescription.SyphilisTreatment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.treatments[treatment.Id] = treatment
+	return nil
+}
+
+func (f *Fake) FindPartnerSyphilisTreatments(ctx context.Context, patientId int, q paging.Query) ([]prescription.SyphilisTreatment, paging.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ts []prescription.SyphilisTreatment
+	for _, t := range f.treatments {
+		if t.PatientId == patientId {
+			ts = append(ts, t)
+		}
+	}
+	keys := make([]paging.Cursor, len(ts))
+	for i, t := range ts {
+		keys[i] = paging.Cursor{Date: t.Date, Id: t.Id}
+	}
+	idx, info := q.Select(keys)
+	var page []prescription.SyphilisTreatment
+	for _, i := range idx {
+		page = append(page, ts[i])
+	}
+	return page, info, nil
+}
+
+func (f *Fake) FindPartnerSyphilisTreatment(ctx context.Context, id string) (*prescription.SyphilisTreatment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.treatments[id]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (f *Fake) UpdatePartnerSyphilisTreatment(ctx context.Context, treatment prescription.SyphilisTreatment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old, ok := f.treatments[treatment.Id]
+	if !ok || old.Version != treatment.Version {
+		return db.ErrVersionConflict
+	}
+	old.Medication = treatment.Medication
+	old.Dosage = treatment.Dosage
+	old.Comments = treatment.Comments
+	old.UpdatedBy = treatment.UpdatedBy
+	old.UpdatedAt = treatment.UpdatedAt
+	old.Date = treatment.Date
+	old.Version++
+	f.treatments[treatment.Id] = old
+	return nil
+}
+
+// DeletePartnerSyphilisTreatment hides the partner syphilis treatment from the Find methods
+// until it is restored.
+func (f *Fake) DeletePartnerSyphilisTreatment(ctx context.Context, id, user, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.treatments[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.treatments, id)
+	f.deleted[id] = v
+	return true, nil
+}
+
+func (f *Fake) RestorePartnerSyphilisTreatment(ctx context.Context, id, user string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.deleted[id]
+	if !ok {
+		return false, nil
+	}
+	delete(f.deleted, id)
+	f.treatments[id] = v
+	return true, nil
+}
@@ -0,0 +1,43 @@
+package partners
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestPartnersIntegration(t *testing.T) {
+	p := New(dbtest.OpenEmtct(t))
+	ctx := context.Background()
+
+	treatment := prescription.SyphilisTreatment{
+		Id:         "s1",
+		PatientId:  100,
+		Medication: "Benzathine Penicillin",
+		Dosage:     "2.4 MU",
+		Date:       time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		CreatedBy:  "nurse@example.com",
+		CreatedAt:  time.Now(),
+		Version:    1,
+	}
+	if err := p.AddPartnerSyphilisTreatment(ctx, treatment); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	treatment.Comments, treatment.UpdatedBy, treatment.UpdatedAt = "Second dose", "doctor@example.com", &now
+	if err := p.UpdatePartnerSyphilisTreatment(ctx, treatment); err != nil {
+		t.Fatal(err)
+	}
+
+	treatments, _, err := p.FindPartnerSyphilisTreatments(ctx, 100, paging.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(treatments) != 1 || treatments[0].Comments != "Second dose" || treatments[0].Dosage != "2.4 MU" {
+		t.Errorf("want: the edited treatment got: %+v", treatments)
+	}
+}
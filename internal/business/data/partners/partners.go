@@ -1,9 +1,11 @@
 package partners
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
 	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
 	"moh.gov.bz/mch/emtct/internal/db"
 )
@@ -16,40 +18,54 @@ func New(db *db.EmtctDb) Partners {
 	return Partners{emtctdb: db}
 }
 
-func (p *Partners) AddPartnerSyphilisTreatment(treatment prescription.SyphilisTreatment) error {
+func (p *Partners) AddPartnerSyphilisTreatment(ctx context.Context, treatment prescription.SyphilisTreatment) error {
+	ctx, cancel := p.emtctdb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	INSERT INTO syphilis_treatment_partner 
     	(id, patient_id, medication_name, dosage, comments, date, created_by, created_at)
 	VALUES($1, $2, $3, $4, $5, $6, $7, $8);
 `
-	_, err := p.emtctdb.Exec(stmt,
-		treatment.Id,
-		treatment.PatientId,
-		treatment.Medication,
-		treatment.Dosage,
-		treatment.Comments,
-		treatment.Date,
-		treatment.CreatedBy,
-		treatment.CreatedAt)
+	err := p.emtctdb.Audited(ctx, "syphilis_treatment_partner", treatment.Id, db.AuditCreate, treatment.CreatedBy, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt,
+			treatment.Id,
+			treatment.PatientId,
+			treatment.Medication,
+			treatment.Dosage,
+			treatment.Comments,
+			treatment.Date,
+			treatment.CreatedBy,
+			treatment.CreatedAt)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("error inserting syphilis treatment for partner into database: %w", err)
 	}
 	return nil
 }
 
-func (p *Partners) FindPartnerSyphilisTreatments(patientId int) ([]prescription.SyphilisTreatment, error) {
+// FindPartnerSyphilisTreatments returns the page q of the syphilis treatments of a patient's
+// partners, by date.
+func (p *Partners) FindPartnerSyphilisTreatments(ctx context.Context, patientId int, q paging.Query) ([]prescription.SyphilisTreatment, paging.Info, error) {
+	ctx, cancel := p.emtctdb.WithTimeout(ctx)
+	defer cancel()
+	filter, args := q.Filter("date", 1)
+	var total int
+	count := `SELECT count(*) FROM syphilis_treatment_partner WHERE patient_id=$1 AND deleted_at IS NULL` + filter
+	if err := p.emtctdb.QueryRowContext(ctx, count, append([]interface{}{patientId}, args...)...).Scan(&total); err != nil {
+		return nil, paging.Info{}, fmt.Errorf("error counting the partner syphilis treatments: %w", err)
+	}
+	seek, args := q.Seek("date", "id", 1)
 	stmt := `
 	SELECT 
-	       id, patient_id, medication_name, dosage, comments, date, created_by, created_at, updated_by, updated_at
+	       id, patient_id, medication_name, dosage, comments, date, created_by, created_at, updated_by, updated_at, version
 	FROM syphilis_treatment_partner
-	WHERE patient_id=$1
-	ORDER BY date DESC;
-`
-	rows, err := p.emtctdb.Query(stmt, patientId)
-	defer rows.Close()
+	WHERE patient_id=$1 AND deleted_at IS NULL` + seek + q.OrderBy("date", "id")
+	rows, err := p.emtctdb.QueryContext(ctx, stmt, append([]interface{}{patientId}, args...)...)
 	if err != nil {
-		return nil, fmt.Errorf("error querying partner syphilis treatment from database: %w", err)
+		return nil, paging.Info{}, fmt.Errorf("error querying partner syphilis treatment from database: %w", err)
 	}
+	defer rows.Close()
 	var treatments []prescription.SyphilisTreatment
 	for rows.Next() {
 		var t prescription.SyphilisTreatment
@@ -64,34 +80,97 @@ func (p *Partners) FindPartnerSyphilisTreatments(patientId int) ([]prescription.
 			&t.CreatedBy,
 			&t.CreatedAt,
 			&updatedBy,
-			&t.UpdatedAt)
+			&t.UpdatedAt,
+			&t.Version)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning syphilis treatment for partner query results: %+v", err)
+			return nil, paging.Info{}, fmt.Errorf("error scanning syphilis treatment for partner query results: %w", err)
 		}
 		if updatedBy.Valid {
 			t.UpdatedBy = updatedBy.String
 		}
 		treatments = append(treatments, t)
 	}
-	return treatments, nil
+	info, n := q.NewInfo(total, len(treatments), func(i int) paging.Cursor {
+		return paging.Cursor{Date: treatments[i].Date, Id: treatments[i].Id}
+	})
+	return treatments[:n], info, nil
 }
 
-func (p *Partners) UpdatePartnerSyphilisTreatment(treatment prescription.SyphilisTreatment) error {
+// FindPartnerSyphilisTreatment returns nil when the treatment does not exist.
+func (p *Partners) FindPartnerSyphilisTreatment(ctx context.Context, id string) (*prescription.SyphilisTreatment, error) {
+	ctx, cancel := p.emtctdb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT 
+	       id, patient_id, medication_name, dosage, comments, date, created_by, created_at, updated_by, updated_at, version
+	FROM syphilis_treatment_partner
+	WHERE id=$1 AND deleted_at IS NULL;
+`
+	var t prescription.SyphilisTreatment
+	var updatedBy sql.NullString
+	err := p.emtctdb.QueryRowContext(ctx, stmt, id).Scan(
+		&t.Id,
+		&t.PatientId,
+		&t.Medication,
+		&t.Dosage,
+		&t.Comments,
+		&t.Date,
+		&t.CreatedBy,
+		&t.CreatedAt,
+		&updatedBy,
+		&t.UpdatedAt,
+		&t.Version)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+		t.UpdatedBy = updatedBy.String
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("error retrieving a partner's syphilis treatment from the database: %w", err)
+	}
+}
+
+// UpdatePartnerSyphilisTreatment saves treatment when it is still at treatment.Version. It
+// returns db.ErrVersionConflict when the treatment was saved by someone else, or deleted.
+func (p *Partners) UpdatePartnerSyphilisTreatment(ctx context.Context, treatment prescription.SyphilisTreatment) error {
+	ctx, cancel := p.emtctdb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	UPDATE syphilis_treatment_partner 
-	SET medication_name=$1, dosage=$2, comments=$3, updated_by=$4, updated_at=$5, date=$6
-	WHERE id=$7;
+	SET medication_name=$1, dosage=$2, comments=$3, updated_by=$4, updated_at=$5, date=$6, version=version+1
+	WHERE id=$7 AND version=$8 AND deleted_at IS NULL;
 `
-	_, err := p.emtctdb.Exec(stmt,
-		treatment.Medication,
-		treatment.Dosage,
-		treatment.Comments,
-		treatment.UpdatedBy,
-		treatment.UpdatedAt,
-		treatment.Date,
-		treatment.Id)
+	err := p.emtctdb.Audited(ctx, "syphilis_treatment_partner", treatment.Id, db.AuditUpdate, treatment.UpdatedBy, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, stmt,
+			treatment.Medication,
+			treatment.Dosage,
+			treatment.Comments,
+			treatment.UpdatedBy,
+			treatment.UpdatedAt,
+			treatment.Date,
+			treatment.Id,
+			treatment.Version)
+		if err != nil {
+			return err
+		}
+		return db.CheckVersion(res)
+	})
 	if err != nil {
 		return fmt.Errorf("error updating a partner's syphilis treatment in the database: %w", err)
 	}
 	return nil
 }
+
+// DeletePartnerSyphilisTreatment soft-deletes a partner syphilis treatment that was recorded
+// by mistake. It returns false when the partner syphilis treatment does not exist or was
+// already deleted.
+func (p *Partners) DeletePartnerSyphilisTreatment(ctx context.Context, id, user, reason string) (bool, error) {
+	return p.emtctdb.SoftDelete(ctx, "syphilis_treatment_partner", id, user, reason)
+}
+
+// RestorePartnerSyphilisTreatment undoes the deletion of a partner syphilis treatment. It
+// returns false when the partner syphilis treatment does not exist or is not deleted.
+func (p *Partners) RestorePartnerSyphilisTreatment(ctx context.Context, id, user string) (bool, error) {
+	return p.emtctdb.Restore(ctx, "syphilis_treatment_partner", id, user)
+}
@@ -0,0 +1,23 @@
+package partners
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/paging"
+	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+)
+
+// Store is implemented by *Partners and by Fake.
+type Store interface {
+	AddPartnerSyphilisTreatment(ctx context.Context, treatment prescription.SyphilisTreatment) error
+	FindPartnerSyphilisTreatments(ctx context.Context, patientId int, q paging.Query) ([]prescription.SyphilisTreatment, paging.Info, error)
+	FindPartnerSyphilisTreatment(ctx context.Context, id string) (*prescription.SyphilisTreatment, error)
+	UpdatePartnerSyphilisTreatment(ctx context.Context, treatment prescription.SyphilisTreatment) error
+	DeletePartnerSyphilisTreatment(ctx context.Context, id, user, reason string) (bool, error)
+	RestorePartnerSyphilisTreatment(ctx context.Context, id, user string) (bool, error)
+}
+
+var (
+	_ Store = (*Partners)(nil)
+	_ Store = (*Fake)(nil)
+)
@@ -1,6 +1,7 @@
 package patient
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -12,7 +13,9 @@ const (
 	layoutISO = "2006-01-02"
 )
 
-func (p *Patients) FindArvsByPatient(patientId int, beginDate, endDate time.Time) ([]prescription.Prescription, error) {
+func (p *Patients) FindArvsByPatient(ctx context.Context, patientId int, beginDate, endDate time.Time) ([]prescription.Prescription, error) {
+	ctx, cancel := p.Acsis.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 		SELECT
 		    adep.encounter_pharmaceutical_id,
@@ -34,14 +37,14 @@ func (p *Patients) FindArvsByPatient(patientId int, beginDate, endDate time.Time
 			OR aap.name ILIKE '%Nevirapine%')
 		ORDER BY adep.prescribed_time DESC;
 `
-	rows, err := p.Acsis.Query(stmt,
+	rows, err := p.Acsis.QueryContext(ctx, stmt,
 		patientId,
 		beginDate.Format(layoutISO),
 		endDate.Format(layoutISO))
-	defer rows.Close()
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving arvs from acsis: %w", err)
 	}
+	defer rows.Close()
 	var arvs []prescription.Prescription
 	var totalDoses sql.NullInt64
 	for rows.Next() {
@@ -54,7 +57,7 @@ func (p *Patients) FindArvsByPatient(patientId int, beginDate, endDate time.Time
 			&arv.Comments,
 			&arv.PrescribedTime)
 		if err != nil {
-			return arvs, fmt.Errorf("error scanning arv prescription from acsis: %+v", err)
+			return arvs, fmt.Errorf("error scanning arv prescription from acsis: %w", err)
 		}
 		arv.PatientId = patientId
 		if totalDoses.Valid {
@@ -0,0 +1,32 @@
+package patient
+
+import (
+	"context"
+
+	"moh.gov.bz/mch/emtct/internal/cache"
+)
+
+// Cached is a Store that keeps the basic info of patients in a cache, so that handlers
+// do not ask ACSIS for it on every request. The other methods are not cached.
+type Cached struct {
+	Store
+	cache *cache.Cache
+}
+
+func NewCached(s Store, c *cache.Cache) *Cached {
+	return &Cached{Store: s, cache: c}
+}
+
+// FindBasicInfo only caches patients that exist.
+func (c *Cached) FindBasicInfo(ctx context.Context, patientId int) (*BasicInfo, error) {
+	if v, ok := c.cache.Get(patientId); ok {
+		info := v.(BasicInfo)
+		return &info, nil
+	}
+	info, err := c.Store.FindBasicInfo(ctx, patientId)
+	if err != nil || info == nil {
+		return info, err
+	}
+	c.cache.Set(patientId, *info)
+	return info, nil
+}
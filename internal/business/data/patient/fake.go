@@ -0,0 +1,77 @@
+package patient
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+)
+
+// Fake is an in-memory Store for tests. ACSIS is read only, so its fields are seeded by
+// the test before it is used.
+type Fake struct {
+	// Patients is keyed by patient id.
+	Patients map[int]Patient
+	// Arvs and SyphilisTreatments are keyed by patient id.
+	Arvs               map[int][]prescription.Prescription
+	SyphilisTreatments map[int][]prescription.Prescription
+}
+
+func NewFake(ps ...Patient) *Fake {
+	f := &Fake{
+		Patients:           make(map[int]Patient),
+		Arvs:               make(map[int][]prescription.Prescription),
+		SyphilisTreatments: make(map[int][]prescription.Prescription),
+	}
+	for _, p := range ps {
+		id, _ := strconv.Atoi(p.Id)
+		f.Patients[id] = p
+	}
+	return f
+}
+
+func (f *Fake) FindBasicInfo(ctx context.Context, patientId int) (*BasicInfo, error) {
+	p, ok := f.Patients[patientId]
+	if !ok {
+		return nil, nil
+	}
+	return &BasicInfo{
+		Id:         p.Id,
+		FirstName:  p.FirstName,
+		MiddleName: p.MiddleName,
+		LastName:   p.LastName,
+		Dob:        p.Dob,
+		Ssn:        p.Ssn,
+	}, nil
+}
+
+func (f *Fake) FindByPatientId(ctx context.Context, id int) (*Patient, error) {
+	p, ok := f.Patients[id]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (f *Fake) FindArvsByPatient(ctx context.Context, patientId int, beginDate, endDate time.Time) ([]prescription.Prescription, error) {
+	return prescribedBetween(f.Arvs[patientId], &beginDate, &endDate), nil
+}
+
+func (f *Fake) FindSyphilisTreatment(ctx context.Context, patientId int, beginDate *time.Time, endDate *time.Time) ([]prescription.Prescription, error) {
+	return prescribedBetween(f.SyphilisTreatments[patientId], beginDate, endDate), nil
+}
+
+func prescribedBetween(ps []prescription.Prescription, beginDate, endDate *time.Time) []prescription.Prescription {
+	if beginDate == nil || endDate == nil {
+		return ps
+	}
+	var matched []prescription.Prescription
+	for _, p := range ps {
+		if p.PrescribedTime.Before(*beginDate) || p.PrescribedTime.After(*endDate) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	return matched
+}
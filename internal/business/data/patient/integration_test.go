@@ -0,0 +1,77 @@
+package patient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestPatientsIntegration(t *testing.T) {
+	acsis := dbtest.OpenAcsis(t)
+	dbtest.Load(t, acsis.DB, "mother_infant")
+	p := New(acsis)
+	ctx := context.Background()
+
+	t.Run("basic info", func(t *testing.T) {
+		info, err := p.FindBasicInfo(ctx, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info == nil || info.FirstName != "Maria" || info.LastName != "Cal" || info.Ssn != "000123456" {
+			t.Errorf("want: Maria Cal got: %+v", info)
+		}
+		info, err = p.FindBasicInfo(ctx, 999)
+		if err != nil || info != nil {
+			t.Errorf("want: no patient got: %+v, %v", info, err)
+		}
+	})
+
+	t.Run("pregnant patient", func(t *testing.T) {
+		patient, err := p.FindByPatientId(ctx, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if patient == nil {
+			t.Fatal("want: patient 100 got: nil")
+		}
+		if patient.PregnancyId != "7" {
+			t.Errorf("want: pregnancy 7 got: %s", patient.PregnancyId)
+		}
+		if patient.NextOfKin != "Pedro Cal" || patient.Ethnicity != "Creole" || patient.Education != "Secondary" {
+			t.Errorf("want: the next of kin, ethnicity and education got: %+v", patient)
+		}
+		if want := "12 Albert Street Apt 2,Belize City,Belize"; patient.Address != want {
+			t.Errorf("want: %s got: %s", want, patient.Address)
+		}
+	})
+
+	t.Run("arvs", func(t *testing.T) {
+		begin := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+		arvs, err := p.FindArvsByPatient(ctx, 100, begin, begin.AddDate(1, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(arvs) != 1 {
+			t.Fatalf("want: 1 arv got: %d", len(arvs))
+		}
+		if arvs[0].Pharmaceutical != "Zidovudine" || arvs[0].Strength != "300 mg" || arvs[0].TotalDoses != 60 {
+			t.Errorf("want: 60 doses of Zidovudine 300 mg got: %+v", arvs[0])
+		}
+		arvs, err = p.FindArvsByPatient(ctx, 100, begin.AddDate(1, 0, 0), begin.AddDate(2, 0, 0))
+		if err != nil || len(arvs) != 0 {
+			t.Errorf("want: no arvs after the pregnancy got: %d, %v", len(arvs), err)
+		}
+	})
+
+	t.Run("syphilis treatment", func(t *testing.T) {
+		treatments, err := p.FindSyphilisTreatment(ctx, 100, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(treatments) != 1 || treatments[0].Pharmaceutical != "Benzathine Penicillin" {
+			t.Errorf("want: the mother's penicillin only got: %+v", treatments)
+		}
+	})
+}
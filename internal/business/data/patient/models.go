@@ -1,16 +1,17 @@
 package patient
 
 import (
-	"database/sql"
 	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
 )
 
 type Patients struct {
-	Acsis *sql.DB
+	Acsis *db.AcsisDb
 }
 
-func New(db *sql.DB) Patients {
-	return Patients{db}
+func New(acsisDb *db.AcsisDb) Patients {
+	return Patients{acsisDb}
 }
 
 type Patient struct {
@@ -1,11 +1,14 @@
 package patient
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
 
-func (p *Patients) FindBasicInfo(patientId int) (*BasicInfo, error) {
+func (p *Patients) FindBasicInfo(ctx context.Context, patientId int) (*BasicInfo, error) {
+	ctx, cancel := p.Acsis.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 		SELECT
 		    hp.patient_id,
@@ -18,7 +21,7 @@ func (p *Patients) FindBasicInfo(patientId int) (*BasicInfo, error) {
 			INNER JOIN acsis_hc_patients hp ON p.person_id=hp.person_id
 		WHERE hp.patient_id=$1;
 `
-	row := p.Acsis.QueryRow(stmt, patientId)
+	row := p.Acsis.QueryRowContext(ctx, stmt, patientId)
 	var info BasicInfo
 	err := row.Scan(&info.Id,
 		&info.FirstName,
@@ -33,14 +36,16 @@ func (p *Patients) FindBasicInfo(patientId int) (*BasicInfo, error) {
 	case nil:
 		return &info, nil
 	default:
-		return nil, fmt.Errorf("error retrieving patient basic info from acsis: %+v", err)
+		return nil, fmt.Errorf("error retrieving patient basic info from acsis: %w", err)
 
 	}
 }
 
 // FindByPatientId searches for a patient who is currently pregnant.
 // A patient is considered pregnant if she has a record in the acsis_hc_pregnancies
-func (p *Patients) FindByPatientId(id int) (*Patient, error) {
+func (p *Patients) FindByPatientId(ctx context.Context, id int) (*Patient, error) {
+	ctx, cancel := p.Acsis.WithTimeout(ctx)
+	defer cancel()
 
 	stmt := `SELECT p.patient_id, ahp.pregnancy_id,
 		l.first_name, l.last_name, l.middle_name,
@@ -67,7 +72,7 @@ func (p *Patients) FindByPatientId(id int) (*Patient, error) {
 		LIMIT 1;`
 
 	var patient Patient
-	row := p.Acsis.QueryRow(stmt, id)
+	row := p.Acsis.QueryRowContext(ctx, stmt, id)
 	var nok sql.NullString
 	var nokPhone sql.NullString
 	err := row.Scan(&patient.Id,
@@ -97,7 +102,7 @@ func (p *Patients) FindByPatientId(id int) (*Patient, error) {
 
 		return &patient, nil
 	default:
-		return nil, fmt.Errorf("error querying acsis db for patient: %+v", err)
+		return nil, fmt.Errorf("error querying acsis db for patient: %w", err)
 	}
 
 }
@@ -0,0 +1,22 @@
+package patient
+
+import (
+	"context"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
+)
+
+// Store is implemented by *Patients, by Cached and by Fake.
+type Store interface {
+	FindBasicInfo(ctx context.Context, patientId int) (*BasicInfo, error)
+	FindByPatientId(ctx context.Context, id int) (*Patient, error)
+	FindArvsByPatient(ctx context.Context, patientId int, beginDate, endDate time.Time) ([]prescription.Prescription, error)
+	FindSyphilisTreatment(ctx context.Context, patientId int, beginDate *time.Time, endDate *time.Time) ([]prescription.Prescription, error)
+}
+
+var (
+	_ Store = (*Patients)(nil)
+	_ Store = (*Fake)(nil)
+	_ Store = (*Cached)(nil)
+)
@@ -1,6 +1,7 @@
 package patient
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -8,7 +9,9 @@ import (
 	"moh.gov.bz/mch/emtct/internal/business/data/prescription"
 )
 
-func (p *Patients) FindSyphilisTreatment(patientId int, beginDate *time.Time, endDate *time.Time) ([]prescription.Prescription, error) {
+func (p *Patients) FindSyphilisTreatment(ctx context.Context, patientId int, beginDate *time.Time, endDate *time.Time) ([]prescription.Prescription, error) {
+	ctx, cancel := p.Acsis.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 SELECT
 		    adep.encounter_pharmaceutical_id,
@@ -34,12 +37,12 @@ SELECT
 		args = append(args, endDate.Format(layoutISO))
 	}
 	stmt = fmt.Sprintf("%s ORDER BY adep.prescribed_time DESC", stmt)
-	rows, err := p.Acsis.Query(stmt, args...)
-	defer rows.Close()
+	rows, err := p.Acsis.QueryContext(ctx, stmt, args...)
 
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving syphilis from acsis: %+v", err)
+		return nil, fmt.Errorf("error retrieving syphilis from acsis: %w", err)
 	}
+	defer rows.Close()
 
 	var prescriptions []prescription.Prescription
 	var totalDoses sql.NullInt64
@@ -53,7 +56,7 @@ SELECT
 			&prescription.Comments,
 			&prescription.PrescribedTime)
 		if err != nil {
-			return prescriptions, fmt.Errorf("error scanning syphillis prescription from acsis: %+v", err)
+			return prescriptions, fmt.Errorf("error scanning syphillis prescription from acsis: %w", err)
 		}
 		prescription.PatientId = patientId
 		if totalDoses.Valid {
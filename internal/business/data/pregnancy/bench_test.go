@@ -0,0 +1,59 @@
+package pregnancy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+// BenchmarkFindVitals compares the pregnancy queries run one after the other with the same
+// queries run in parallel. The gain grows with the round trip time to
+// ACSIS, so run it against a remote database or one behind an emulated delay.
+func BenchmarkFindVitals(b *testing.B) {
+	acsis := dbtest.OpenAcsis(b)
+	stmts := []string{
+		`INSERT INTO acsis_hc_obstetric_patient_details VALUES (1, 2, 0, true)`,
+		`INSERT INTO acsis_hc_patients (patient_id, obstetric_patient_details_id, birth_date) VALUES (1, 1, '1995-04-02')`,
+		`INSERT INTO acsis_hc_pregnancies VALUES (1, 1, '2020-01-10', '2020-10-16', '2020-02-01')`,
+		`INSERT INTO acsis_hc_facility_types VALUES (1)`,
+		`INSERT INTO acsis_hc_facilities VALUES (1, 'Matron Roberts', 1)`,
+		`INSERT INTO acsis_adt_mch_encounter_details VALUES (1, '2020-10-16', 3)`,
+		`INSERT INTO acsis_adt_encounters (encounter_id, patient_id, encounter_type, encounter_details_id, facility_id, begin_time)
+			VALUES (1, 1, 'M', 1, 1, '2020-02-01')`,
+		`INSERT INTO acsis_adt_icd10_diseases VALUES (32657, 'Z33', 'Pregnant state')`,
+		`INSERT INTO acsis_adt_encounter_diagnoses VALUES (1, 32657, '2020-02-01')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := acsis.Exec(stmt); err != nil {
+			b.Fatalf("error seeding the pregnancy: %v", err)
+		}
+	}
+
+	lmp := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	p := Pregnancy{PatientId: 1, PregnancyId: 1, Lmp: &lmp}
+	ctx := context.Background()
+	for _, bm := range []struct {
+		name     string
+		parallel int
+	}{
+		{"sequential", 1},
+		{"parallel", db.DefaultMaxParallelQueries},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			acsis.MaxParallelQueries = bm.parallel
+			d := New(nil, acsis)
+			for n := 0; n < b.N; n++ {
+				v, err := d.FindVitals(ctx, p)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if v == nil || v.Id != 1 || v.Para != 2 {
+					b.Fatalf("want: pregnancy 1 with para 2 got: %+v", v)
+				}
+			}
+		})
+	}
+}
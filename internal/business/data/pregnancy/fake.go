@@ -0,0 +1,127 @@
+package pregnancy
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Fake is an in-memory Store for tests. Its fields are seeded by the test before it is used.
+type Fake struct {
+	// Pregnancies are the emtct pregnancies, keyed by pregnancy id.
+	Pregnancies map[int]Pregnancy
+	// Changes are keyed by etl run id.
+	Changes map[string][]Change
+	// The ACSIS details of a pregnancy are keyed by pregnancy id.
+	Vitals              map[int]Vitals
+	AntenatalEncounters map[int]AntenatalEncounter
+	DiagnosesDuring     map[int][]Diagnosis
+	DiagnosesBefore     map[int][]Diagnosis
+	// ObstetricHistory is keyed by patient id.
+	ObstetricHistory map[int][]ObstetricHistory
+}
+
+func NewFake(ps ...Pregnancy) *Fake {
+	f := &Fake{
+		Pregnancies:         make(map[int]Pregnancy),
+		Changes:             make(map[string][]Change),
+		Vitals:              make(map[int]Vitals),
+		AntenatalEncounters: make(map[int]AntenatalEncounter),
+		DiagnosesDuring:     make(map[int][]Diagnosis),
+		DiagnosesBefore:     make(map[int][]Diagnosis),
+		ObstetricHistory:    make(map[int][]ObstetricHistory),
+	}
+	for _, p := range ps {
+		f.Pregnancies[p.PregnancyId] = p
+	}
+	return f
+}
+
+// FindLatest returns the pregnancy of the patient with the latest LMP.
+func (f *Fake) FindLatest(ctx context.Context, patientId int) (*Pregnancy, error) {
+	var latest *Pregnancy
+	for _, p := range f.Pregnancies {
+		if p.PatientId != patientId {
+			continue
+		}
+		if latest == nil || (p.Lmp != nil && (latest.Lmp == nil || p.Lmp.After(*latest.Lmp))) {
+			p := p
+			latest = &p
+		}
+	}
+	return latest, nil
+}
+
+// FindByPatientId returns the pregnancies of the patient, the latest LMP first.
+func (f *Fake) FindByPatientId(ctx context.Context, patientId int) ([]Pregnancy, error) {
+	var ps []Pregnancy
+	for _, p := range f.Pregnancies {
+		if p.PatientId == patientId {
+			ps = append(ps, p)
+		}
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].Lmp == nil || ps[j].Lmp == nil {
+			return ps[j].Lmp == nil && ps[i].Lmp != nil
+		}
+		return ps[i].Lmp.After(*ps[j].Lmp)
+	})
+	return ps, nil
+}
+
+func (f *Fake) FindByIds(ctx context.Context, ids []int) (map[int]Pregnancy, error) {
+	ps := make(map[int]Pregnancy)
+	for _, id := range ids {
+		if p, ok := f.Pregnancies[id]; ok {
+			ps[id] = p
+		}
+	}
+	return ps, nil
+}
+
+func (f *Fake) FindChangesByRun(ctx context.Context, runId string) ([]Change, error) {
+	return f.Changes[runId], nil
+}
+
+func (f *Fake) FindVitals(ctx context.Context, p Pregnancy) (*Vitals, error) {
+	v, ok := f.Vitals[p.PregnancyId]
+	if !ok {
+		return nil, nil
+	}
+	return &v, nil
+}
+
+func (f *Fake) FindAntenatalEncounter(ctx context.Context, p Pregnancy) (*AntenatalEncounter, error) {
+	e, ok := f.AntenatalEncounters[p.PregnancyId]
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// FindGestation returns the gestation of the pregnancy today, without a booking or an ultrasound.
+func (f *Fake) FindGestation(ctx context.Context, p Pregnancy) (*Gestation, error) {
+	g := NewGestation(p, nil, nil, time.Now())
+	return &g, nil
+}
+
+// FindGestations returns the gestations of ps today, without a booking or an ultrasound.
+func (f *Fake) FindGestations(ctx context.Context, ps []Pregnancy) ([]Gestation, error) {
+	gs := make([]Gestation, len(ps))
+	for i, p := range ps {
+		gs[i] = NewGestation(p, nil, nil, time.Now())
+	}
+	return gs, nil
+}
+
+func (f *Fake) FindDiagnosesDuringPregnancy(ctx context.Context, p Pregnancy) ([]Diagnosis, error) {
+	return f.DiagnosesDuring[p.PregnancyId], nil
+}
+
+func (f *Fake) FindDiagnosesBeforePregnancy(ctx context.Context, p Pregnancy) ([]Diagnosis, error) {
+	return f.DiagnosesBefore[p.PregnancyId], nil
+}
+
+func (f *Fake) FindObstetricHistory(ctx context.Context, patientId int) ([]ObstetricHistory, error) {
+	return f.ObstetricHistory[patientId], nil
+}
@@ -0,0 +1,190 @@
+package pregnancy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/screeningRules"
+)
+
+// term is the number of days from the LMP to the EDD.
+const term = 280
+
+// maxDays is the longest a pregnancy is followed without an end time. Past it the pregnancy
+// is assumed to have ended without being recorded, and it has no gestational age today.
+const maxDays = 44 * 7
+
+// How the EDD of a Gestation was dated.
+const (
+	DatedByUltrasound = "ultrasound"
+	DatedByLmp        = "lmp"
+	DatedByEdd        = "edd"
+)
+
+// GestationalAge is the age of a pregnancy in completed weeks and days.
+type GestationalAge struct {
+	Weeks int `json:"weeks"`
+	Days  int `json:"days"`
+}
+
+func newGestationalAge(days int) *GestationalAge {
+	if days < 0 {
+		return nil
+	}
+	return &GestationalAge{Weeks: days / 7, Days: days % 7}
+}
+
+// InDays returns the gestational age as a number of days.
+func (g GestationalAge) InDays() int {
+	return g.Weeks*7 + g.Days
+}
+
+// Trimester returns 1 up to 13 weeks and 6 days, 2 up to 27 weeks and 6 days and 3 after that.
+func (g GestationalAge) Trimester() int {
+	switch {
+	case g.Weeks < 14:
+		return 1
+	case g.Weeks < 28:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Gestation is how far along a pregnancy was at booking, is today and was at delivery. The
+// gestational ages are counted back from Edd, so they are nil when the pregnancy has no dates.
+type Gestation struct {
+	Edd *time.Time `json:"edd"`
+	// DatedBy tells where Edd comes from: an ultrasound, the LMP or the EDD of the pregnancy.
+	DatedBy    string          `json:"datedBy"`
+	Booking    *time.Time      `json:"booking"`
+	AtBooking  *GestationalAge `json:"atBooking"`
+	Today      *GestationalAge `json:"today"`
+	AtDelivery *GestationalAge `json:"atDelivery"`
+	// BookingTrimester is 0 when the pregnancy was not booked.
+	BookingTrimester int `json:"bookingTrimester"`
+}
+
+// NewGestation returns the gestation of a pregnancy that was booked on booking, and dated by
+// an ultrasound to ultrasoundEdd, on the day today. The ultrasound is preferred to the LMP, and
+// the LMP to the EDD of the pregnancy. booking and ultrasoundEdd may be nil.
+func NewGestation(p Pregnancy, booking, ultrasoundEdd *time.Time, today time.Time) Gestation {
+	g := Gestation{Booking: booking}
+	switch {
+	case ultrasoundEdd != nil:
+		g.Edd, g.DatedBy = ultrasoundEdd, DatedByUltrasound
+	case p.Lmp != nil:
+		edd := p.Lmp.AddDate(0, 0, term)
+		g.Edd, g.DatedBy = &edd, DatedByLmp
+	case p.Edd != nil:
+		g.Edd, g.DatedBy = p.Edd, DatedByEdd
+	default:
+		return g
+	}
+	ageAt := func(t time.Time) *GestationalAge {
+		return newGestationalAge(term - screeningRules.Days(t, *g.Edd))
+	}
+	if booking != nil {
+		g.AtBooking = ageAt(*booking)
+		if g.AtBooking != nil {
+			g.BookingTrimester = g.AtBooking.Trimester()
+		}
+	}
+	if p.EndTime != nil {
+		g.AtDelivery = ageAt(*p.EndTime)
+	} else if age := ageAt(today); age != nil && age.InDays() <= maxDays {
+		g.Today = age
+	}
+	return g
+}
+
+// FindGestation returns the gestation of a pregnancy today. It is booked on its first anc
+// encounter, and dated by the first ultrasound of its anc encounters, when there is one. ACSIS
+// records the gestational age in weeks that the ultrasound found, which dates the EDD.
+func (d *Pregnancies) FindGestation(ctx context.Context, p Pregnancy) (*Gestation, error) {
+	gs, err := d.FindGestations(ctx, []Pregnancy{p})
+	if err != nil {
+		return nil, err
+	}
+	return &gs[0], nil
+}
+
+// ancEncounter is an anc encounter with the gestational age in weeks that its ultrasound found.
+type ancEncounter struct {
+	patientId int
+	begin     time.Time
+	weeks     sql.NullFloat64
+}
+
+// FindGestations returns the gestations of ps today, in the same order, as FindGestation does. The
+// anc encounters of every pregnancy are read in a single query, so that listing the pregnancies
+// of a patient does not run a query per pregnancy.
+func (d *Pregnancies) FindGestations(ctx context.Context, ps []Pregnancy) ([]Gestation, error) {
+	var patientIds []int
+	var from, to time.Time
+	for _, p := range ps {
+		pFrom, pTo, ok := p.Period()
+		if !ok {
+			continue
+		}
+		patientIds = append(patientIds, p.PatientId)
+		if from.IsZero() || pFrom.Before(from) {
+			from = pFrom
+		}
+		if pTo.After(to) {
+			to = pTo
+		}
+	}
+	var encounters []ancEncounter
+	if len(patientIds) > 0 {
+		ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+		defer cancel()
+		stmt := `
+		SELECT e.patient_id, e.begin_time, amed.gestational_age_by_ultrasound
+		FROM acsis_adt_encounters e
+		INNER JOIN acsis_adt_mch_encounter_details amed ON e.encounter_details_id=amed.mch_encounter_details_id
+		WHERE e.patient_id = ANY($1) AND e.encounter_type='M' AND e.begin_time >= $2 AND e.begin_time < $3
+		ORDER BY e.begin_time`
+		rows, err := d.AcsisDb.QueryContext(ctx, stmt, pq.Array(patientIds), from, to)
+		if err != nil {
+			return nil, fmt.Errorf("error querying the anc encounters of patients %v from acsis: %w", patientIds, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e ancEncounter
+			if err := rows.Scan(&e.patientId, &e.begin, &e.weeks); err != nil {
+				return nil, fmt.Errorf("error scanning an anc encounter of patients %v: %w", patientIds, err)
+			}
+			encounters = append(encounters, e)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error reading the anc encounters of patients %v: %w", patientIds, err)
+		}
+	}
+	today := time.Now()
+	gs := make([]Gestation, len(ps))
+	for i, p := range ps {
+		var booking, ultrasoundEdd *time.Time
+		if pFrom, pTo, ok := p.Period(); ok {
+			for _, e := range encounters {
+				if e.patientId != p.PatientId || e.begin.Before(pFrom) || !e.begin.Before(pTo) {
+					continue
+				}
+				if booking == nil {
+					begin := e.begin
+					booking = &begin
+				}
+				if ultrasoundEdd == nil && e.weeks.Valid && e.weeks.Float64 > 0 {
+					edd := e.begin.AddDate(0, 0, term-int(e.weeks.Float64*7))
+					ultrasoundEdd = &edd
+				}
+			}
+		}
+		gs[i] = NewGestation(p, booking, ultrasoundEdd, today)
+	}
+	return gs, nil
+}
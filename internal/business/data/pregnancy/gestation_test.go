@@ -0,0 +1,89 @@
+package pregnancy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewGestation(t *testing.T) {
+	date := func(m time.Month, d int) *time.Time {
+		t := time.Date(2020, m, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	lmp := date(time.January, 10)
+	// 5 weeks after the lmp, at 09:00.
+	booking := time.Date(2020, time.February, 14, 9, 0, 0, 0, time.UTC)
+
+	g := NewGestation(Pregnancy{Lmp: lmp}, &booking, nil, *date(time.May, 1))
+	if g.DatedBy != DatedByLmp || !g.Edd.Equal(*date(time.October, 16)) {
+		t.Errorf("want: the edd 280 days after the lmp got: %s by %s", g.Edd, g.DatedBy)
+	}
+	if *g.AtBooking != (GestationalAge{Weeks: 5}) || g.BookingTrimester != 1 {
+		t.Errorf("want: booked at 5 weeks in the first trimester got: %+v, %d", g.AtBooking, g.BookingTrimester)
+	}
+	if *g.Today != (GestationalAge{Weeks: 16}) || g.AtDelivery != nil {
+		t.Errorf("want: 16 weeks today and no delivery got: %+v, %+v", g.Today, g.AtDelivery)
+	}
+
+	// The ultrasound dates the edd a week later than the lmp.
+	g = NewGestation(Pregnancy{Lmp: lmp, Edd: lmp, EndTime: date(time.October, 20)}, &booking, date(time.October, 23), *date(time.May, 1))
+	if g.DatedBy != DatedByUltrasound || *g.AtBooking != (GestationalAge{Weeks: 4}) {
+		t.Errorf("want: booked at 4 weeks by ultrasound got: %+v by %s", g.AtBooking, g.DatedBy)
+	}
+	if g.Today != nil || *g.AtDelivery != (GestationalAge{Weeks: 39, Days: 4}) {
+		t.Errorf("want: delivered at 39 weeks and 4 days got: %+v, %+v", g.Today, g.AtDelivery)
+	}
+
+	g = NewGestation(Pregnancy{Edd: date(time.October, 16)}, nil, nil, *date(time.December, 1))
+	if g.DatedBy != DatedByEdd || g.AtBooking != nil || g.BookingTrimester != 0 {
+		t.Errorf("want: dated by the edd and not booked got: %+v", g)
+	}
+	if g.Today != nil {
+		t.Errorf("want: no gestational age today 46 weeks after the lmp got: %+v", g.Today)
+	}
+
+	if g := NewGestation(Pregnancy{}, &booking, nil, booking); g.Edd != nil || g.AtBooking != nil {
+		t.Errorf("want: no gestation without dates got: %+v", g)
+	}
+}
+
+func TestTrimester(t *testing.T) {
+	for _, tt := range []struct {
+		age  GestationalAge
+		want int
+	}{
+		{GestationalAge{Weeks: 13, Days: 6}, 1},
+		{GestationalAge{Weeks: 14}, 2},
+		{GestationalAge{Weeks: 27, Days: 6}, 2},
+		{GestationalAge{Weeks: 28}, 3},
+	} {
+		if got := tt.age.Trimester(); got != tt.want {
+			t.Errorf("want: trimester %d at %+v got: %d", tt.want, tt.age, got)
+		}
+	}
+}
+
+func TestStillBirthOutcome(t *testing.T) {
+	atDelivery := func(weeks, days int) *Gestation {
+		return &Gestation{AtDelivery: &GestationalAge{Weeks: weeks, Days: days}}
+	}
+	tests := []struct {
+		name      string
+		gestation *Gestation
+		want      string
+	}{
+		{"no gestation", nil, ""},
+		{"not delivered", &Gestation{Today: &GestationalAge{Weeks: 30}}, ""},
+		{"before 22 weeks", atDelivery(21, 6), ""},
+		{"22 weeks", atDelivery(22, 0), "Still Birth 22"},
+		{"27 weeks and 6 days", atDelivery(27, 6), "Still Birth 22"},
+		{"28 weeks", atDelivery(28, 0), "Still Birth 28"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stillBirthOutcome(tt.gestation); got != tt.want {
+				t.Errorf("want: %q got: %q", tt.want, got)
+			}
+		})
+	}
+}
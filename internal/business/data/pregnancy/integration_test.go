@@ -0,0 +1,127 @@
+package pregnancy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/db/dbtest"
+)
+
+func TestPregnanciesIntegration(t *testing.T) {
+	acsis := dbtest.OpenAcsis(t)
+	dbtest.Load(t, acsis.DB, "mother_infant")
+	d := New(dbtest.OpenEmtct(t), acsis)
+	ctx := context.Background()
+	lmp := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	edd := time.Date(2020, 10, 16, 0, 0, 0, 0, time.UTC)
+	p := Pregnancy{PatientId: 100, PregnancyId: 7, Lmp: &lmp, Edd: &edd}
+	if err := d.Create(ctx, []Pregnancy{p}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pregnancies", func(t *testing.T) {
+		ps, err := d.FindByPatientId(ctx, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ps) != 1 || ps[0].PregnancyId != 7 {
+			t.Errorf("want: pregnancy 7 got: %+v", ps)
+		}
+		latest, err := d.FindLatest(ctx, 200)
+		if err != nil || latest != nil {
+			t.Errorf("want: no pregnancy for the infant got: %+v, %v", latest, err)
+		}
+	})
+
+	t.Run("gestations", func(t *testing.T) {
+		want, err := d.FindGestation(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		earlier := lmp.AddDate(-2, 0, 0)
+		gs, err := d.FindGestations(ctx, []Pregnancy{p, {PatientId: 100, PregnancyId: 5, Lmp: &earlier}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(gs) != 2 || !reflect.DeepEqual(gs[0], *want) {
+			t.Errorf("want: the gestation of pregnancy 7 first %+v got: %+v", *want, gs)
+		}
+		if len(gs) == 2 && gs[1].Booking != nil {
+			t.Errorf("want: no booking for the earlier pregnancy got: %s", gs[1].Booking)
+		}
+	})
+
+	t.Run("vitals", func(t *testing.T) {
+		v, err := d.FindVitals(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v == nil {
+			t.Fatal("want: the vitals of the pregnancy got: nil")
+		}
+		if v.Id != 7 || v.Lmp == nil || !v.Lmp.Equal(lmp) {
+			t.Errorf("want: pregnancy 7 with lmp %s got: %d %v", lmp, v.Id, v.Lmp)
+		}
+		// Booked on 2020-02-14, 35 days after the lmp.
+		if v.GestationalAge != 35 || v.AgeAtLmp != 24 || v.Para != 2 || !v.Planned {
+			t.Errorf("want: gestational age 35, age 24, para 2 and planned got: %+v", v)
+		}
+		if v.PrenatalCareProvider != "Public - KHMH" || v.BirthStatus != "Live Birth" {
+			t.Errorf("want: public care at the KHMH and a live birth got: %+v", v)
+		}
+		if v.ApgarFirstMinute != 8 || v.ApgarFifthMinute != 9 {
+			t.Errorf("want: apgar 8 and 9 got: %d and %d", v.ApgarFirstMinute, v.ApgarFifthMinute)
+		}
+		// The ultrasound at the booking found 6 weeks.
+		g := v.Gestation
+		if g == nil || g.DatedBy != DatedByUltrasound || *g.AtBooking != (GestationalAge{Weeks: 6}) || g.BookingTrimester != 1 {
+			t.Errorf("want: booked at 6 weeks by ultrasound got: %+v", g)
+		}
+
+		// The encounters of patient 100 are all before this pregnancy.
+		later := lmp.AddDate(3, 0, 0)
+		v, err = d.FindVitals(ctx, Pregnancy{PatientId: 100, PregnancyId: 8, Lmp: &later})
+		if err != nil || v != nil {
+			t.Errorf("want: no vitals without an anc encounter got: %+v, %v", v, err)
+		}
+	})
+
+	t.Run("diagnoses", func(t *testing.T) {
+		during, err := d.FindDiagnosesDuringPregnancy(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(during) != 2 || during[0].Name != "HIV disease" {
+			t.Errorf("want: the HIV and pregnancy diagnoses, newest first got: %+v", during)
+		}
+		before, err := d.FindDiagnosesBeforePregnancy(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(before) != 1 || before[0].Name != "Anaemia" {
+			t.Errorf("want: the anaemia diagnosed before the lmp got: %+v", before)
+		}
+	})
+
+	t.Run("obstetric history", func(t *testing.T) {
+		history, err := d.FindObstetricHistory(ctx, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 1 || history[0].ObstetricEvent != "Live Birth" {
+			t.Errorf("want: 1 live birth got: %+v", history)
+		}
+	})
+
+	t.Run("acsis pregnancies", func(t *testing.T) {
+		ps, err := d.FindPregnanciesInBhisByIds(ctx, []int{7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, ok := ps[7]; !ok || p.PatientId != 100 || !p.Active {
+			t.Errorf("want: the active pregnancy 7 of patient 100 got: %+v", ps)
+		}
+	})
+}
@@ -19,11 +19,27 @@ func New(emtctdb *db.EmtctDb, acsisdb *db.AcsisDb) Pregnancies {
 }
 
 type Pregnancy struct {
-	PatientId   int
-	PregnancyId int
-	Lmp         *time.Time
-	Edd         *time.Time
-	EndTime     *time.Time
+	PatientId        int        `json:"patientId"`
+	PregnancyId      int        `json:"pregnancyId"`
+	Lmp              *time.Time `json:"lmp"`
+	Edd              *time.Time `json:"edd"`
+	EndTime          *time.Time `json:"endTime"`
+	LastModifiedTime *time.Time `json:"lastModifiedTime"`
+	// Active is only read from ACSIS. Pregnancies in the emtct database are always active.
+	Active bool `json:"-"`
+}
+
+// Weeks is how long after its LMP the encounters, lab results and prescriptions of a pregnancy
+// are looked for.
+const Weeks = 54
+
+// Period returns the dates from the LMP of the pregnancy to Weeks after it. It returns false
+// when the pregnancy has no LMP.
+func (p Pregnancy) Period() (from, to time.Time, ok bool) {
+	if p.Lmp == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return *p.Lmp, p.Lmp.Add(time.Hour * 24 * 7 * Weeks), true
 }
 
 func (p *Pregnancy) Index(vs []Pregnancy) int {
@@ -40,8 +56,10 @@ func (p *Pregnancy) Include(vs []Pregnancy) bool {
 }
 
 type Vitals struct {
-	Id                   int        `json:"id"`
-	PatientId            int        `json:"patientId"`
+	Id        int `json:"id"`
+	PatientId int `json:"patientId"`
+	// GestationalAge is the number of days from the LMP to the anc encounter. Gestation has
+	// the gestational ages in weeks and days.
 	GestationalAge       int        `json:"gestationalAge"`
 	Para                 int        `json:"para"`
 	Cs                   int        `json:"cs"`
@@ -57,6 +75,7 @@ type Vitals struct {
 	ApgarFirstMinute     int        `json:"apgarFirstMinute"`
 	ApgarFifthMinute     int        `json:"apgarFifthMinute"`
 	BirthStatus          string     `json:"birthStatus"`
+	Gestation            *Gestation `json:"gestation"`
 }
 
 type AntenatalEncounter struct {
@@ -9,7 +9,11 @@ import (
 	"github.com/bearbin/go-age"
 )
 
-func (p Pregnancies) FindLatest(patientId int) (*Pregnancy, error) {
+// FindLatest returns the pregnancy of the patient with the latest LMP. This is the patient's
+// current pregnancy. It returns nil if the patient has no pregnancy.
+func (p Pregnancies) FindLatest(ctx context.Context, patientId int) (*Pregnancy, error) {
+	ctx, cancel := p.EmtctDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	SELECT 
 	       pregnancy_id, patient_id, lmp, edd, end_time
@@ -19,7 +23,7 @@ func (p Pregnancies) FindLatest(patientId int) (*Pregnancy, error) {
 	ORDER BY lmp DESC
 	LIMIT 1;
 `
-	row := p.EmtctDb.QueryRow(stmt, patientId)
+	row := p.EmtctDb.QueryRowContext(ctx, stmt, patientId)
 	var pregnancy Pregnancy
 	err := row.Scan(
 		&pregnancy.PregnancyId,
@@ -38,19 +42,55 @@ func (p Pregnancies) FindLatest(patientId int) (*Pregnancy, error) {
 	}
 }
 
-func (p Pregnancies) FindPregnanciesInBhisByYear(year int) ([]Pregnancy, error) {
+// FindByPatientId returns every pregnancy of the patient, the latest LMP first.
+func (p Pregnancies) FindByPatientId(ctx context.Context, patientId int) ([]Pregnancy, error) {
+	ctx, cancel := p.EmtctDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
-	SELECT patient_id, pregnancy_id, last_menstrual_period_date, estimated_delivery_date, end_time
+	SELECT pregnancy_id, patient_id, lmp, edd, end_time, last_modified_time
+	FROM pregnancies
+	WHERE patient_id = $1
+	ORDER BY lmp DESC NULLS LAST, pregnancy_id DESC
+`
+	rows, err := p.EmtctDb.QueryContext(ctx, stmt, patientId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the pregnancies of patient %d from emtctdb: %w", patientId, err)
+	}
+	defer rows.Close()
+	var ps []Pregnancy
+	for rows.Next() {
+		var pr Pregnancy
+		err := rows.Scan(
+			&pr.PregnancyId,
+			&pr.PatientId,
+			&pr.Lmp,
+			&pr.Edd,
+			&pr.EndTime,
+			&pr.LastModifiedTime)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pregnancy from emtct db: %w", err)
+		}
+		ps = append(ps, pr)
+	}
+	return ps, nil
+}
+
+func (p Pregnancies) FindPregnanciesInBhisByYear(ctx context.Context, year int) ([]Pregnancy, error) {
+	ctx, cancel := p.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT patient_id, pregnancy_id, last_menstrual_period_date, estimated_delivery_date, end_time, last_modified_time,
+	       active IS TRUE
 	FROM acsis_hc_pregnancies
 	WHERE last_menstrual_period_date BETWEEN $1 AND $2;
 `
 	leftYear := fmt.Sprintf("%d-01-01", year)
 	rightYear := fmt.Sprintf("%d-01-01", year+1)
-	rows, err := p.AcsisDb.Query(stmt, leftYear, rightYear)
-	defer rows.Close()
+	rows, err := p.AcsisDb.QueryContext(ctx, stmt, leftYear, rightYear)
 	if err != nil {
 		return nil, fmt.Errorf("error querying for pregnancies by year: %w", err)
 	}
+	defer rows.Close()
 	var ps []Pregnancy
 	for rows.Next() {
 		var pr Pregnancy
@@ -59,7 +99,9 @@ func (p Pregnancies) FindPregnanciesInBhisByYear(year int) ([]Pregnancy, error)
 			&pr.PregnancyId,
 			&pr.Lmp,
 			&pr.Edd,
-			&pr.EndTime)
+			&pr.EndTime,
+			&pr.LastModifiedTime,
+			&pr.Active)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning pregnancy from acsis: %w", err)
 		}
@@ -68,17 +110,19 @@ func (p Pregnancies) FindPregnanciesInBhisByYear(year int) ([]Pregnancy, error)
 	return ps, nil
 }
 
-func (p Pregnancies) FindExistingPregnanciesByYear(year int) ([]Pregnancy, error) {
+func (p Pregnancies) FindExistingPregnanciesByYear(ctx context.Context, year int) ([]Pregnancy, error) {
+	ctx, cancel := p.EmtctDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	SELECT pregnancy_id, patient_id, lmp, edd, end_time
 	FROM pregnancies
 	WHERE lmp BETWEEN $1 AND $2
 `
-	rows, err := p.EmtctDb.Query(stmt, fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-01-01", year+1))
-	defer rows.Close()
+	rows, err := p.EmtctDb.QueryContext(ctx, stmt, fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-01-01", year+1))
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving pregnancies from emtct db: %w", err)
 	}
+	defer rows.Close()
 	var ps []Pregnancy
 	for rows.Next() {
 		var pr Pregnancy
@@ -103,12 +147,12 @@ func (p Pregnancies) Create(ctx context.Context, ps []Pregnancy) error {
 		return fmt.Errorf("failed to start transaction for inserting pregnancies: %w", err)
 	}
 
-	stmt := `INSERT INTO pregnancies (pregnancy_id, patient_id, lmp, edd, end_time) VALUES($1, $2, $3, $4, $5)`
+	stmt := `INSERT INTO pregnancies (pregnancy_id, patient_id, lmp, edd, end_time, last_modified_time) VALUES($1, $2, $3, $4, $5, $6)`
 	for _, p := range ps {
-		_, err := tx.ExecContext(ctx, stmt, p.PregnancyId, p.PatientId, p.Lmp, p.Edd, p.EndTime)
+		_, err := tx.ExecContext(ctx, stmt, p.PregnancyId, p.PatientId, p.Lmp, p.Edd, p.EndTime, p.LastModifiedTime)
 		if err != nil {
 			tx.Rollback()
-			return fmt.Errorf("")
+			return fmt.Errorf("error inserting pregnancy %d: %w", p.PregnancyId, err)
 		}
 	}
 
@@ -121,24 +165,57 @@ func (p Pregnancies) Create(ctx context.Context, ps []Pregnancy) error {
 	return nil
 }
 
-// FindCurrentPregnancy returns details about the patient's current pregnancy.
+// FindVitals returns details about a pregnancy of the patient.
 // Under the hood it is 4 separate queries:
-// 1. Finds obstetric details. This is information in the acsis_hc_pregnancies table, and contains the LMP and EDD.
-// 2. Finds the latest anc encounter.
+// 1. Finds the Para/Cs/Planned data of the patient.
+// 2. Finds the latest anc encounter of the pregnancy.
 // 3. Retrieves apgar information
-// 4. Retrieves previous diagnoses
+// 4. Retrieves the pregnancy diagnosis
+// 5. Finds the Gestation of the pregnancy
 // These are all separate queries because the database is not designed in a way to make it possible to retrieve
 // all this information using joins. This is partly due to there not being any link between the pregnancies table and
-// the encounters table that allows us to retrieve the corresponding anc encounter for a pregnancy.
-func (d *Pregnancies) FindCurrentPregnancy(patientId int) (*Vitals, error) {
-	v, err := d.FindObstetricDetails(patientId)
+// the encounters table, so the anc encounter of a pregnancy is the latest one in the Period of the pregnancy.
+// The queries that only depend on the pregnancy run at the same time. It returns nil if the pregnancy has no
+// anc encounter.
+func (d *Pregnancies) FindVitals(ctx context.Context, pregnancy Pregnancy) (*Vitals, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	patientId := pregnancy.PatientId
+	var details *Vitals
+	var anc *AntenatalEncounter
+	var p *pregnancyDiagnosis
+	var gestation *Gestation
+	err := d.AcsisDb.Parallel(ctx,
+		func(ctx context.Context) (err error) {
+			details, err = d.findObstetricPatientDetails(ctx, patientId)
+			if err != nil {
+				return fmt.Errorf("error retrieving obstetric patient detials when consolidating the obstetric details: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			anc, err = d.FindAntenatalEncounter(ctx, pregnancy)
+			if err != nil {
+				return fmt.Errorf("could not find pregnancy details because no antenatal encounter was found: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			p, err = d.findPregnancyDiagnosis(ctx, pregnancy)
+			if err != nil {
+				return fmt.Errorf("error while retrieving pregnancy info from acsis: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			gestation, err = d.FindGestation(ctx, pregnancy)
+			return err
+		})
 	if err != nil {
-		return nil, fmt.Errorf("error while retrieving pregnancy details from acsis: %+v", err)
+		return nil, err
 	}
-	// Find the anc encounter. This is the most recent anc encounter in patient's docket.
-	anc, err := d.FindLatestAntenatalEncounter(patientId, v.Lmp)
-	if err != nil {
-		return nil, fmt.Errorf("could not find current pregnancy details because no antenatal encounter was found: %+v", err)
+	if anc == nil {
+		return nil, nil
 	}
 
 	stmt := `SELECT
@@ -170,7 +247,7 @@ LIMIT 1;
 	var dob *time.Time
 	var careProvider string
 	var facility string
-	row := d.AcsisDb.QueryRow(stmt, patientId, anc.Id)
+	row := d.AcsisDb.QueryRowContext(ctx, stmt, patientId, anc.Id)
 	var apgarFirst sql.NullInt32
 	var apgarFifth sql.NullInt32
 	err = row.Scan(
@@ -189,23 +266,21 @@ LIMIT 1;
 		return nil, nil
 	case nil:
 		ageAtLmp := 0
-		if v.Lmp != nil {
-			ageAtLmp = age.AgeAt(*dob, *v.Lmp)
+		if pregnancy.Lmp != nil {
+			ageAtLmp = age.AgeAt(*dob, *pregnancy.Lmp)
 		}
 		vitals.AgeAtLmp = ageAtLmp
-		vitals.Para = v.Para
-		vitals.Id = v.Id
-		vitals.Cs = v.Cs
-		vitals.Planned = v.Planned
-		vitals.Lmp = v.Lmp
-		vitals.Edd = v.Edd
-		vitals.PregnancyOutcome, err = d.abortiveOutcome(vitals)
-		if err != nil {
-			return nil, fmt.Errorf("error while calculating abortive outcome when retrieving pregnancy details from acsis: %+v", err)
+		vitals.withPatientDetails(details)
+		vitals.Id = pregnancy.PregnancyId
+		vitals.Lmp = pregnancy.Lmp
+		if pregnancy.Edd != nil {
+			vitals.Edd = *pregnancy.Edd
 		}
-		p, err := d.findPreviousPregnancyDiagnosis(patientId)
+		vitals.GestationalAge = anc.GestationalAge
+		vitals.Gestation = gestation
+		vitals.PregnancyOutcome, err = d.abortiveOutcome(ctx, vitals)
 		if err != nil {
-			return nil, fmt.Errorf("error while retrieving current pregnancy info from acsis: %+v", err)
+			return nil, fmt.Errorf("error while calculating abortive outcome when retrieving pregnancy details from acsis: %w", err)
 		}
 		if p != nil {
 			vitals.DiagnosisDate = &p.Date
@@ -216,52 +291,20 @@ LIMIT 1;
 		if apgarFifth.Valid {
 			vitals.ApgarFifthMinute = int(apgarFifth.Int32)
 		}
-		vitals.GestationalAge = anc.GestationalAge
 
 		return &vitals, nil
 	default:
-		return nil, fmt.Errorf("error while retrieving pregnancy details from acsis: %+v", err)
+		return nil, fmt.Errorf("error while retrieving pregnancy details from acsis: %w", err)
 	}
 
 }
 
-// FindObstetricDetails retrieves the patient's LMP, EDD from the acsis_hc_pregnancies
-// and the Para/Cs/Planned data as a second query from the acsis_hc_obstetric_patient_details
-// table because there is no guarantee that the patient will have data in the latter table.
-// When no data is available in that table, we get the wrong results.
-func (d *Pregnancies) FindObstetricDetails(patientId int) (*Vitals, error) {
-	stmt := `SELECT
-       hp.pregnancy_id,
-       hp.last_menstrual_period_date,
-       hp.estimated_delivery_date
-FROM acsis_hc_patients p
-INNER JOIN acsis_hc_pregnancies hp ON p.patient_id = hp.patient_id
-WHERE p.patient_id=$1
-ORDER BY hp.last_modified_time DESC
-LIMIT 1;`
-
-	var vitals Vitals
-	row := d.AcsisDb.QueryRow(stmt, patientId)
-	err := row.Scan(&vitals.Id,
-		&vitals.Lmp,
-		&vitals.Edd)
-	switch err {
-	case sql.ErrNoRows:
-		return nil, nil
-	case nil:
-		v, err := d.findObstetricPatientDetails(patientId)
-		if err != nil {
-			return nil, fmt.Errorf("error retrieving obstetric patient detials when consolidating the obstetric details: %+v", err)
-		}
-		if v != nil {
-			vitals.Para = v.Para
-			vitals.Cs = v.Cs
-			vitals.Planned = v.Planned
-		}
-
-		return &vitals, nil
-	default:
-		return nil, fmt.Errorf("error querying obstetric details from acsis: %+v", err)
+// withPatientDetails copies the Para/Cs/Planned data of the patient, when there is any.
+func (v *Vitals) withPatientDetails(details *Vitals) {
+	if details != nil {
+		v.Para = details.Para
+		v.Cs = details.Cs
+		v.Planned = details.Planned
 	}
 }
 
@@ -270,7 +313,9 @@ LIMIT 1;`
 // If we do a join of this table when trying to retrieve other obstetric information from the
 // pregnancies table, the results are skewed because we can not guarantee that the patient will
 // exist in the acsis_hc_obstetric_patient_details_table
-func (d *Pregnancies) findObstetricPatientDetails(patientId int) (*Vitals, error) {
+func (d *Pregnancies) findObstetricPatientDetails(ctx context.Context, patientId int) (*Vitals, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	SELECT
        ahopd.number_liveborn_pregnancies,
@@ -282,7 +327,7 @@ func (d *Pregnancies) findObstetricPatientDetails(patientId int) (*Vitals, error
 	LIMIT 1;
 `
 	var vitals Vitals
-	row := d.AcsisDb.QueryRow(stmt, patientId)
+	row := d.AcsisDb.QueryRowContext(ctx, stmt, patientId)
 	err := row.Scan(
 		&vitals.Para,
 		&vitals.Cs,
@@ -293,12 +338,21 @@ func (d *Pregnancies) findObstetricPatientDetails(patientId int) (*Vitals, error
 	case nil:
 		return &vitals, nil
 	default:
-		return nil, fmt.Errorf("error querying obstetric patient details from acsis: %+v", err)
+		return nil, fmt.Errorf("error querying obstetric patient details from acsis: %w", err)
 	}
 
 }
 
-func (d *Pregnancies) FindLatestAntenatalEncounter(patientId int, lmp *time.Time) (*AntenatalEncounter, error) {
+// FindAntenatalEncounter returns the latest anc encounter in the Period of the pregnancy, with
+// the gestational age at the encounter in days. It returns nil if the pregnancy has no LMP or
+// no anc encounter.
+func (d *Pregnancies) FindAntenatalEncounter(ctx context.Context, pregnancy Pregnancy) (*AntenatalEncounter, error) {
+	from, to, ok := pregnancy.Period()
+	if !ok {
+		return nil, nil
+	}
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `SELECT e.encounter_id,
            e.patient_id,
            amed.mch_encounter_details_id, 
@@ -308,12 +362,12 @@ func (d *Pregnancies) FindLatestAntenatalEncounter(patientId int, lmp *time.Time
         FROM acsis_hc_patients p
         INNER JOIN acsis_adt_encounters e ON p.patient_id=e.patient_id AND e.encounter_type='M'
         INNER JOIN acsis_adt_mch_encounter_details amed ON e.encounter_details_id=amed.mch_encounter_details_id
-        WHERE p.patient_id=$1
+        WHERE p.patient_id=$1 AND e.begin_time >= $2 AND e.begin_time < $3
         ORDER BY e.begin_time DESC
         LIMIT 1;`
 
 	var anc AntenatalEncounter
-	row := d.AcsisDb.QueryRow(stmt, patientId)
+	row := d.AcsisDb.QueryRowContext(ctx, stmt, pregnancy.PatientId, from, to)
 	err := row.Scan(&anc.Id,
 		&anc.PatientId,
 		&anc.MchEncounterDetailsId,
@@ -326,27 +380,39 @@ func (d *Pregnancies) FindLatestAntenatalEncounter(patientId int, lmp *time.Time
 		return nil, nil
 	case nil:
 		//Gestational Age at booking is the difference b/w LMP and begin time
-		if lmp == nil {
-			return &anc, nil
-		}
-		anc.GestationalAge = int(anc.BeginDate.Sub(*lmp).Hours() / 24)
+		anc.GestationalAge = int(anc.BeginDate.Sub(from).Hours() / 24)
 		return &anc, nil
 	default:
-		return nil, fmt.Errorf("error querying for mch details from acsis: %+v", err)
+		return nil, fmt.Errorf("error querying for mch details from acsis: %w", err)
 	}
 }
 
-func (d *Pregnancies) abortiveOutcome(v Vitals) (string, error) {
-	if v.ApgarFifthMinute > 0 && v.ApgarFirstMinute > 0 {
-		return "Live Birth", nil
+// stillBirthOutcome tells a still birth apart by the completed weeks of gestation at delivery:
+// "Still Birth 22" from 22 to 27 weeks and "Still Birth 28" after that. It returns "" when the
+// pregnancy ended before 22 weeks or has no gestational age at delivery.
+func stillBirthOutcome(g *Gestation) string {
+	if g == nil || g.AtDelivery == nil {
+		return ""
 	}
+	switch weeks := g.AtDelivery.Weeks; {
+	case weeks > 27:
+		return "Still Birth 28"
+	case weeks >= 22:
+		return "Still Birth 22"
+	default:
+		return ""
+	}
+}
 
-	if v.GestationalAge >= 22 && v.GestationalAge <= 27 {
-		return "Still Birth 22", nil
+func (d *Pregnancies) abortiveOutcome(ctx context.Context, v Vitals) (string, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	if v.ApgarFifthMinute > 0 && v.ApgarFirstMinute > 0 {
+		return "Live Birth", nil
 	}
 
-	if v.GestationalAge > 27 {
-		return "Still Birth 28", nil
+	if outcome := stillBirthOutcome(v.Gestation); outcome != "" {
+		return outcome, nil
 	}
 
 	// Otherwise it is an abortion.. and we need to do a query for this:
@@ -364,7 +430,7 @@ func (d *Pregnancies) abortiveOutcome(v Vitals) (string, error) {
 	LIMIT 1;
 `
 	var diagnosis string
-	row := d.AcsisDb.QueryRow(stmt, v.PatientId)
+	row := d.AcsisDb.QueryRowContext(ctx, stmt, v.PatientId)
 	err := row.Scan(&diagnosis)
 	switch err {
 	case sql.ErrNoRows:
@@ -372,7 +438,7 @@ func (d *Pregnancies) abortiveOutcome(v Vitals) (string, error) {
 	case nil:
 		return "Abortion", nil
 	default:
-		return "", fmt.Errorf("error querying acsis for abortion diagnosis when determining abortive outcome: %+v", err)
+		return "", fmt.Errorf("error querying acsis for abortion diagnosis when determining abortive outcome: %w", err)
 	}
 
 }
@@ -383,17 +449,26 @@ type pregnancyDiagnosis struct {
 	Date        time.Time
 }
 
-func (d *Pregnancies) findPreviousPregnancyDiagnosis(patientId int) (*pregnancyDiagnosis, error) {
+// findPregnancyDiagnosis returns the latest diagnosis of the pregnant state in the Period of the
+// pregnancy. It returns nil if the pregnancy has no LMP or was not diagnosed.
+func (d *Pregnancies) findPregnancyDiagnosis(ctx context.Context, p Pregnancy) (*pregnancyDiagnosis, error) {
+	from, to, ok := p.Period()
+	if !ok {
+		return nil, nil
+	}
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `
 	SELECT e.encounter_id, ed.diagnosis_time
     FROM acsis_adt_encounters e
     INNER JOIN acsis_adt_encounter_diagnoses ed ON e.encounter_id=ed.encounter_id
     WHERE ed.disease_id=32657
-    AND e.patient_id=$1
+    AND e.patient_id=$1 AND ed.diagnosis_time >= $2 AND ed.diagnosis_time < $3
     ORDER BY ed.diagnosis_time DESC
-	LIMIT 2;
+	LIMIT 1;
 `
-	row := d.AcsisDb.QueryRow(stmt, patientId)
+	patientId := p.PatientId
+	row := d.AcsisDb.QueryRowContext(ctx, stmt, patientId, from, to)
 	var pregnancy pregnancyDiagnosis
 	err := row.Scan(&pregnancy.EncounterId, &pregnancy.Date)
 	switch err {
@@ -403,24 +478,18 @@ func (d *Pregnancies) findPreviousPregnancyDiagnosis(patientId int) (*pregnancyD
 		pregnancy.PatientId = patientId
 		return &pregnancy, nil
 	default:
-		return nil, fmt.Errorf("error retrieving pregnancy diagnosis from acsis: %+v", err)
+		return nil, fmt.Errorf("error retrieving pregnancy diagnosis from acsis: %w", err)
 	}
 
 }
 
-// FindDiagnosesDuringPregnancy fetches the diagnoses for a patient between the LMP and EDD.
-// First it fetches the obstetric details so it can retrieve the pregnancy id. This id is used
-// in filtering out the result so that the proper LMP and EDD are used when comparing diagnoses date.
-func (d *Pregnancies) FindDiagnosesDuringPregnancy(patientId int) ([]Diagnosis, error) {
-	// Retrieve the obstetric details so we can use the current pregnancy's id.
-	obs, err := d.FindObstetricDetails(patientId)
-	if err != nil {
-		return nil, fmt.Errorf("error retrieving current pregnancy's diagnoses from acsis: %+v", err)
-	}
-
-	if obs == nil {
-		return nil, fmt.Errorf("error: no obstetric details found for current pregnancy in acsis")
-	}
+// FindDiagnosesDuringPregnancy fetches the diagnoses for a patient between the LMP and EDD of a pregnancy.
+// The pregnancy id is used in filtering out the result so that the proper LMP and EDD are used when
+// comparing diagnoses date.
+func (d *Pregnancies) FindDiagnosesDuringPregnancy(ctx context.Context, p Pregnancy) ([]Diagnosis, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	patientId := p.PatientId
 
 	stmt := `SELECT
 			aaed.encounter_diagnosis_id,
@@ -436,11 +505,11 @@ func (d *Pregnancies) FindDiagnosesDuringPregnancy(patientId int) ([]Diagnosis,
 		AND aaed.diagnosis_time > ahp.last_menstrual_period_date
 		ORDER BY aaed.diagnosis_time DESC`
 	var diagnoses []Diagnosis
-	rows, err := d.AcsisDb.Query(stmt, patientId, obs.Id)
-	defer rows.Close()
+	rows, err := d.AcsisDb.QueryContext(ctx, stmt, patientId, p.PregnancyId)
 	if err != nil {
-		return nil, fmt.Errorf("error querying diagnoses before pregnancy from acsis: %+v", err)
+		return nil, fmt.Errorf("error querying diagnoses before pregnancy from acsis: %w", err)
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var diagnosis Diagnosis
 		err := rows.Scan(
@@ -456,19 +525,12 @@ func (d *Pregnancies) FindDiagnosesDuringPregnancy(patientId int) ([]Diagnosis,
 	return diagnoses, nil
 }
 
-// FindDiagnosesBeforePregnancy returns all diagnoses before the current pregnancy.
-// It retrieves the obstetric details as a separate query so it can use the pregnancy id to filter diagnoses
-// where the diagnosis time is before the lmp.
-func (d *Pregnancies) FindDiagnosesBeforePregnancy(patientId int) ([]Diagnosis, error) {
-	// Retrieve the obstetric details so we can use the current pregnancy's id.
-	obs, err := d.FindObstetricDetails(patientId)
-	if err != nil {
-		return nil, fmt.Errorf("error retrieving diagnoses outside pregnancy from acsis: %+v", err)
-	}
-
-	if obs == nil {
-		return nil, fmt.Errorf("error: no obstetric details found for current pregnancy in acsis")
-	}
+// FindDiagnosesBeforePregnancy returns all diagnoses before a pregnancy.
+// It uses the pregnancy id to filter diagnoses where the diagnosis time is before the lmp.
+func (d *Pregnancies) FindDiagnosesBeforePregnancy(ctx context.Context, p Pregnancy) ([]Diagnosis, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	patientId := p.PatientId
 	stmt := `SELECT aaed.encounter_diagnosis_id,
        		e.patient_id,
 			aai10d.name, 
@@ -481,9 +543,9 @@ func (d *Pregnancies) FindDiagnosesBeforePregnancy(patientId int) ([]Diagnosis,
 		      FROM acsis_hc_pregnancies ahp WHERE ahp.pregnancy_id = $2 LIMIT 1)  
 		ORDER BY aaed.diagnosis_time DESC`
 	var diagnoses []Diagnosis
-	rows, err := d.AcsisDb.Query(stmt, patientId, obs.Id)
+	rows, err := d.AcsisDb.QueryContext(ctx, stmt, patientId, p.PregnancyId)
 	if err != nil {
-		return nil, fmt.Errorf("error querying diagnoses before pregnancy from acsis: %+v", err)
+		return nil, fmt.Errorf("error querying diagnoses before pregnancy from acsis: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -501,7 +563,9 @@ func (d *Pregnancies) FindDiagnosesBeforePregnancy(patientId int) ([]Diagnosis,
 	return diagnoses, nil
 }
 
-func (d *Pregnancies) FindObstetricHistory(patientId int) ([]ObstetricHistory, error) {
+func (d *Pregnancies) FindObstetricHistory(ctx context.Context, patientId int) ([]ObstetricHistory, error) {
+	ctx, cancel := d.AcsisDb.WithTimeout(ctx)
+	defer cancel()
 	stmt := `SELECT
 				b.birth_id,
 				b.mother_id,
@@ -511,9 +575,9 @@ func (d *Pregnancies) FindObstetricHistory(patientId int) ([]ObstetricHistory, e
 			INNER JOIN acsis_hc_birth_statuses ahbs on b.birth_status_id = ahbs.birth_status_id
 			WHERE mother_id=$1`
 	var obstetricHistory []ObstetricHistory
-	rows, err := d.AcsisDb.Query(stmt, patientId)
+	rows, err := d.AcsisDb.QueryContext(ctx, stmt, patientId)
 	if err != nil {
-		return nil, fmt.Errorf("error querying acsis for obstetric history: %+v", err)
+		return nil, fmt.Errorf("error querying acsis for obstetric history: %w", err)
 	}
 	defer rows.Close()
 
@@ -524,7 +588,7 @@ func (d *Pregnancies) FindObstetricHistory(patientId int) ([]ObstetricHistory, e
 			&history.ObstetricEvent,
 			&history.Date)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning patient's obstetric history: %+v", err)
+			return nil, fmt.Errorf("error scanning patient's obstetric history: %w", err)
 		}
 		obstetricHistory = append(obstetricHistory, history)
 	}
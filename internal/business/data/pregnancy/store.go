@@ -0,0 +1,26 @@
+package pregnancy
+
+import (
+	"context"
+)
+
+// Store is implemented by *Pregnancies, by Cached and by Fake. The ETL reads and writes pregnancies
+// through Pregnancies directly, so Store only has the methods that are used by the api.
+type Store interface {
+	FindLatest(ctx context.Context, patientId int) (*Pregnancy, error)
+	FindByPatientId(ctx context.Context, patientId int) ([]Pregnancy, error)
+	FindByIds(ctx context.Context, ids []int) (map[int]Pregnancy, error)
+	FindChangesByRun(ctx context.Context, runId string) ([]Change, error)
+	FindVitals(ctx context.Context, p Pregnancy) (*Vitals, error)
+	FindAntenatalEncounter(ctx context.Context, p Pregnancy) (*AntenatalEncounter, error)
+	FindGestation(ctx context.Context, p Pregnancy) (*Gestation, error)
+	FindGestations(ctx context.Context, ps []Pregnancy) ([]Gestation, error)
+	FindDiagnosesDuringPregnancy(ctx context.Context, p Pregnancy) ([]Diagnosis, error)
+	FindDiagnosesBeforePregnancy(ctx context.Context, p Pregnancy) ([]Diagnosis, error)
+	FindObstetricHistory(ctx context.Context, patientId int) ([]ObstetricHistory, error)
+}
+
+var (
+	_ Store = (*Pregnancies)(nil)
+	_ Store = (*Fake)(nil)
+)
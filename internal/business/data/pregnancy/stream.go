@@ -0,0 +1,203 @@
+package pregnancy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultBatchSize is the number of pregnancies that are fetched from ACSIS and inserted
+// into the emtct database at a time.
+const DefaultBatchSize = 500
+
+// RowError is an ACSIS pregnancy that could not be read or inserted. It is skipped so that
+// one bad row does not abort the sync of a whole year.
+type RowError struct {
+	PregnancyId int    `json:"pregnancyId"`
+	Error       string `json:"error"`
+}
+
+// acsisPregnancyRow is a row of acsis_hc_pregnancies. Every column is nullable so that a bad
+// row can still be reported by its pregnancy id.
+type acsisPregnancyRow struct {
+	PregnancyId      int
+	PatientId        sql.NullInt64
+	Lmp              *time.Time
+	Edd              *time.Time
+	EndTime          *time.Time
+	LastModifiedTime *time.Time
+	Active           bool
+}
+
+func (r acsisPregnancyRow) pregnancy() (*Pregnancy, error) {
+	if !r.PatientId.Valid {
+		return nil, fmt.Errorf("pregnancy has no patient")
+	}
+	return &Pregnancy{
+		PatientId:        int(r.PatientId.Int64),
+		PregnancyId:      r.PregnancyId,
+		Lmp:              r.Lmp,
+		Edd:              r.Edd,
+		EndTime:          r.EndTime,
+		LastModifiedTime: r.LastModifiedTime,
+		Active:           r.Active,
+	}, nil
+}
+
+// StreamPregnanciesInBhisByYear reads the ACSIS pregnancies with an LMP in the given year
+// through a server side cursor, so that a whole year is never held in memory. fn is called
+// with every batch of at most batchSize pregnancies, together with the rows of the batch
+// that could not be read. Reading stops at the first error returned by fn. The declaration of
+// the cursor and every fetch are bounded by the ACSIS query timeout, so that a stalled stream
+// fails instead of holding on to the sync.
+func (p Pregnancies) StreamPregnanciesInBhisByYear(ctx context.Context, year, batchSize int, fn func(ps []Pregnancy, bad []RowError) error) error {
+	// Cursors only live inside a transaction.
+	tx, err := p.AcsisDb.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to start transaction for streaming pregnancies from acsis: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt := `
+	DECLARE pregnancies_by_year NO SCROLL CURSOR FOR
+	SELECT pregnancy_id, patient_id, last_menstrual_period_date, estimated_delivery_date, end_time, last_modified_time,
+	       active IS TRUE
+	FROM acsis_hc_pregnancies
+	WHERE last_menstrual_period_date BETWEEN $1 AND $2
+	ORDER BY pregnancy_id;
+`
+	leftYear := fmt.Sprintf("%d-01-01", year)
+	rightYear := fmt.Sprintf("%d-01-01", year+1)
+	declareCtx, cancel := p.AcsisDb.WithTimeout(ctx)
+	_, err = tx.ExecContext(declareCtx, stmt, leftYear, rightYear)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("error declaring the cursor for pregnancies by year: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM pregnancies_by_year", batchSize)
+	for {
+		fetchCtx, cancel := p.AcsisDb.WithTimeout(ctx)
+		ps, bad, n, err := fetchPregnancies(fetchCtx, tx, fetch)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if err := fn(ps, bad); err != nil {
+			return err
+		}
+	}
+}
+
+// fetchPregnancies runs a FETCH on the pregnancies cursor. n is the number of rows fetched.
+func fetchPregnancies(ctx context.Context, tx *sql.Tx, fetch string) (ps []Pregnancy, bad []RowError, n int, err error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("error fetching pregnancies from acsis: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		n++
+		var r acsisPregnancyRow
+		err := rows.Scan(
+			&r.PregnancyId,
+			&r.PatientId,
+			&r.Lmp,
+			&r.Edd,
+			&r.EndTime,
+			&r.LastModifiedTime,
+			&r.Active)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("error scanning pregnancy from acsis: %w", err)
+		}
+		pr, err := r.pregnancy()
+		if err != nil {
+			bad = append(bad, RowError{PregnancyId: r.PregnancyId, Error: err.Error()})
+			continue
+		}
+		ps = append(ps, *pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, 0, fmt.Errorf("error fetching pregnancies from acsis: %w", err)
+	}
+	return ps, bad, n, nil
+}
+
+const insertPregnancyColumns = `INSERT INTO pregnancies (pregnancy_id, patient_id, lmp, edd, end_time, last_modified_time) VALUES `
+
+// CreateBatch inserts the pregnancies that do not exist yet in the emtct database with a
+// single multi row INSERT, and returns the pregnancies that were inserted. If the batch
+// fails, its pregnancies are inserted one at a time and the ones that fail are returned
+// in bad instead of failing the whole batch.
+func (p Pregnancies) CreateBatch(ctx context.Context, ps []Pregnancy) (inserted []Pregnancy, bad []RowError, err error) {
+	if len(ps) == 0 {
+		return nil, nil, nil
+	}
+	var sb strings.Builder
+	sb.WriteString(insertPregnancyColumns)
+	args := make([]interface{}, 0, len(ps)*6)
+	for i, pr := range ps {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := i * 6
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6)
+		args = append(args, pr.PregnancyId, pr.PatientId, pr.Lmp, pr.Edd, pr.EndTime, pr.LastModifiedTime)
+	}
+	sb.WriteString(" ON CONFLICT (pregnancy_id) DO NOTHING RETURNING pregnancy_id")
+
+	ids, err := p.insertReturningIds(ctx, sb.String(), args...)
+	if err == nil {
+		return pregnanciesWithIds(ps, ids), nil, nil
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	// Find the bad rows by inserting the batch one row at a time.
+	stmt := insertPregnancyColumns + `($1, $2, $3, $4, $5, $6) ON CONFLICT (pregnancy_id) DO NOTHING RETURNING pregnancy_id`
+	for _, pr := range ps {
+		rowIds, err := p.insertReturningIds(ctx, stmt, pr.PregnancyId, pr.PatientId, pr.Lmp, pr.Edd, pr.EndTime, pr.LastModifiedTime)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			bad = append(bad, RowError{PregnancyId: pr.PregnancyId, Error: err.Error()})
+			continue
+		}
+		inserted = append(inserted, pregnanciesWithIds([]Pregnancy{pr}, rowIds)...)
+	}
+	return inserted, bad, nil
+}
+
+func (p Pregnancies) insertReturningIds(ctx context.Context, stmt string, args ...interface{}) (map[int]bool, error) {
+	rows, err := p.EmtctDb.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+func pregnanciesWithIds(ps []Pregnancy, ids map[int]bool) []Pregnancy {
+	var matched []Pregnancy
+	for _, pr := range ps {
+		if ids[pr.PregnancyId] {
+			matched = append(matched, pr)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,32 @@
+package pregnancy
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestAcsisPregnancyRow(t *testing.T) {
+	lmp := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	r := acsisPregnancyRow{PregnancyId: 7, PatientId: sql.NullInt64{Int64: 10, Valid: true}, Lmp: &lmp, Active: true}
+	p, err := r.pregnancy()
+	if err != nil {
+		t.Fatalf("want: no error got: %v", err)
+	}
+	if p.PregnancyId != 7 || p.PatientId != 10 || !p.Lmp.Equal(lmp) {
+		t.Errorf("unexpected pregnancy: %+v", p)
+	}
+
+	r.PatientId = sql.NullInt64{}
+	if _, err := r.pregnancy(); err == nil {
+		t.Errorf("want: an error for a pregnancy without a patient")
+	}
+}
+
+func TestPregnanciesWithIds(t *testing.T) {
+	ps := []Pregnancy{{PregnancyId: 1}, {PregnancyId: 2}, {PregnancyId: 3}}
+	got := pregnanciesWithIds(ps, map[int]bool{1: true, 3: true})
+	if len(got) != 2 || got[0].PregnancyId != 1 || got[1].PregnancyId != 3 {
+		t.Errorf("want: pregnancies 1 and 3 got: %+v", got)
+	}
+}
@@ -0,0 +1,326 @@
+package pregnancy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	layoutISO = "2006-01-02"
+)
+
+// Change is a single field of an emtct pregnancy that was changed by a sync.
+type Change struct {
+	PregnancyId int       `json:"pregnancyId"`
+	Field       string    `json:"field"`
+	OldValue    *string   `json:"oldValue"`
+	NewValue    *string   `json:"newValue"`
+	ChangedAt   time.Time `json:"changedAt"`
+}
+
+// UpsertResult describes what happened when a batch of ACSIS pregnancies was upserted.
+type UpsertResult struct {
+	Inserted  []Pregnancy `json:"inserted"`
+	Updated   []Pregnancy `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Changes   []Change    `json:"changes"`
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(layoutISO)
+	return &s
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Diff returns the fields that differ between an existing emtct pregnancy and the
+// same pregnancy in ACSIS. Dates are compared by day because the emtct table stores
+// them as DATE.
+func (p Pregnancy) Diff(acsis Pregnancy) []Change {
+	var changes []Change
+	patientId, acsisPatientId := fmt.Sprint(p.PatientId), fmt.Sprint(acsis.PatientId)
+	fields := []struct {
+		name     string
+		old, new *string
+	}{
+		{"patient_id", &patientId, &acsisPatientId},
+		{"lmp", formatDate(p.Lmp), formatDate(acsis.Lmp)},
+		{"edd", formatDate(p.Edd), formatDate(acsis.Edd)},
+		{"end_time", formatDate(p.EndTime), formatDate(acsis.EndTime)},
+	}
+	for _, f := range fields {
+		if !sameValue(f.old, f.new) {
+			changes = append(changes, Change{
+				PregnancyId: p.PregnancyId,
+				Field:       f.name,
+				OldValue:    f.old,
+				NewValue:    f.new,
+			})
+		}
+	}
+	return changes
+}
+
+// FindPregnanciesInBhisModifiedSince returns the ACSIS pregnancies that were modified at or
+// after the given time, ordered by their modification time.
+func (p Pregnancies) FindPregnanciesInBhisModifiedSince(ctx context.Context, since time.Time) ([]Pregnancy, error) {
+	ctx, cancel := p.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT patient_id, pregnancy_id, last_menstrual_period_date, estimated_delivery_date, end_time, last_modified_time
+	FROM acsis_hc_pregnancies
+	WHERE last_modified_time >= $1
+	ORDER BY last_modified_time;
+`
+	rows, err := p.AcsisDb.QueryContext(ctx, stmt, since)
+	if err != nil {
+		return nil, fmt.Errorf("error querying for modified pregnancies from acsis: %w", err)
+	}
+	defer rows.Close()
+	var ps []Pregnancy
+	for rows.Next() {
+		var pr Pregnancy
+		err := rows.Scan(
+			&pr.PatientId,
+			&pr.PregnancyId,
+			&pr.Lmp,
+			&pr.Edd,
+			&pr.EndTime,
+			&pr.LastModifiedTime)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning modified pregnancy from acsis: %w", err)
+		}
+		ps = append(ps, pr)
+	}
+	return ps, nil
+}
+
+// FindPregnanciesInBhisByIds returns the ACSIS pregnancies with the given ids, keyed by pregnancy id.
+func (p Pregnancies) FindPregnanciesInBhisByIds(ctx context.Context, ids []int) (map[int]Pregnancy, error) {
+	ctx, cancel := p.AcsisDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT patient_id, pregnancy_id, last_menstrual_period_date, estimated_delivery_date, end_time, last_modified_time,
+	       active IS TRUE
+	FROM acsis_hc_pregnancies
+	WHERE pregnancy_id = ANY($1);
+`
+	rows, err := p.AcsisDb.QueryContext(ctx, stmt, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error querying for pregnancies by id from acsis: %w", err)
+	}
+	defer rows.Close()
+	ps := make(map[int]Pregnancy)
+	for rows.Next() {
+		var pr Pregnancy
+		err := rows.Scan(
+			&pr.PatientId,
+			&pr.PregnancyId,
+			&pr.Lmp,
+			&pr.Edd,
+			&pr.EndTime,
+			&pr.LastModifiedTime,
+			&pr.Active)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pregnancy from acsis: %w", err)
+		}
+		ps[pr.PregnancyId] = pr
+	}
+	return ps, nil
+}
+
+// FindByIds returns the emtct pregnancies with the given ids, keyed by pregnancy id.
+func (p Pregnancies) FindByIds(ctx context.Context, ids []int) (map[int]Pregnancy, error) {
+	ctx, cancel := p.EmtctDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT pregnancy_id, patient_id, lmp, edd, end_time, last_modified_time
+	FROM pregnancies
+	WHERE pregnancy_id = ANY($1);
+`
+	rows, err := p.EmtctDb.QueryContext(ctx, stmt, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pregnancies by id from emtct db: %w", err)
+	}
+	defer rows.Close()
+	ps := make(map[int]Pregnancy)
+	for rows.Next() {
+		var pr Pregnancy
+		err := rows.Scan(
+			&pr.PregnancyId,
+			&pr.PatientId,
+			&pr.Lmp,
+			&pr.Edd,
+			&pr.EndTime,
+			&pr.LastModifiedTime)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pregnancy from emtct db: %w", err)
+		}
+		ps[pr.PregnancyId] = pr
+	}
+	return ps, nil
+}
+
+// FindAll returns every pregnancy in the emtct database.
+func (p Pregnancies) FindAll(ctx context.Context) ([]Pregnancy, error) {
+	ctx, cancel := p.EmtctDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT pregnancy_id, patient_id, lmp, edd, end_time, last_modified_time FROM pregnancies`
+	rows, err := p.EmtctDb.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pregnancies from emtct db: %w", err)
+	}
+	defer rows.Close()
+	var ps []Pregnancy
+	for rows.Next() {
+		var pr Pregnancy
+		err := rows.Scan(
+			&pr.PregnancyId,
+			&pr.PatientId,
+			&pr.Lmp,
+			&pr.Edd,
+			&pr.EndTime,
+			&pr.LastModifiedTime)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pregnancy from emtct db: %w", err)
+		}
+		ps = append(ps, pr)
+	}
+	return ps, nil
+}
+
+// FindWatermark returns the ACSIS last_modified_time up to which the named sync has
+// already copied data. It returns nil if the sync has never run.
+func (p Pregnancies) FindWatermark(ctx context.Context, name string) (*time.Time, error) {
+	ctx, cancel := p.EmtctDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `SELECT last_modified_time FROM etl_watermarks WHERE name=$1`
+	var watermark time.Time
+	err := p.EmtctDb.QueryRowContext(ctx, stmt, name).Scan(&watermark)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+		return &watermark, nil
+	default:
+		return nil, fmt.Errorf("error retrieving the %s watermark from emtct db: %w", name, err)
+	}
+}
+
+// Upsert inserts new pregnancies and updates the LMP, EDD and end time of pregnancies
+// that already exist in the emtct database. Every changed field is recorded in the
+// pregnancy_changes table against the etl run that made it. When watermark is not empty,
+// the watermark is advanced to the latest last_modified_time in ps in the same transaction.
+func (p Pregnancies) Upsert(ctx context.Context, runId string, ps []Pregnancy, watermark string) (*UpsertResult, error) {
+	ids := make([]int, 0, len(ps))
+	for _, pr := range ps {
+		ids = append(ids, pr.PregnancyId)
+	}
+	existing, err := p.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find existing pregnancies for the upsert: %w", err)
+	}
+
+	tx, err := p.EmtctDb.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction for upserting pregnancies: %w", err)
+	}
+	defer tx.Rollback()
+
+	upsertStmt := `
+	INSERT INTO pregnancies (pregnancy_id, patient_id, lmp, edd, end_time, last_modified_time)
+	VALUES($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (pregnancy_id) DO UPDATE
+	SET patient_id=EXCLUDED.patient_id, lmp=EXCLUDED.lmp, edd=EXCLUDED.edd, end_time=EXCLUDED.end_time,
+	    last_modified_time=EXCLUDED.last_modified_time;
+`
+	changeStmt := `
+	INSERT INTO pregnancy_changes (pregnancy_id, field, old_value, new_value, changed_at, etl_run_id)
+	VALUES($1, $2, $3, $4, $5, $6);
+`
+	now := time.Now()
+	var result UpsertResult
+	var latest *time.Time
+	for _, pr := range ps {
+		if pr.LastModifiedTime != nil && (latest == nil || pr.LastModifiedTime.After(*latest)) {
+			latest = pr.LastModifiedTime
+		}
+		var changes []Change
+		old, exists := existing[pr.PregnancyId]
+		if exists {
+			changes = old.Diff(pr)
+		}
+		_, err := tx.ExecContext(ctx, upsertStmt, pr.PregnancyId, pr.PatientId, pr.Lmp, pr.Edd, pr.EndTime, pr.LastModifiedTime)
+		if err != nil {
+			return nil, fmt.Errorf("error upserting pregnancy %d: %w", pr.PregnancyId, err)
+		}
+		for _, c := range changes {
+			c.ChangedAt = now
+			if _, err := tx.ExecContext(ctx, changeStmt, c.PregnancyId, c.Field, c.OldValue, c.NewValue, c.ChangedAt, runId); err != nil {
+				return nil, fmt.Errorf("error recording change to %s of pregnancy %d: %w", c.Field, c.PregnancyId, err)
+			}
+			result.Changes = append(result.Changes, c)
+		}
+		switch {
+		case !exists:
+			result.Inserted = append(result.Inserted, pr)
+		case len(changes) > 0:
+			result.Updated = append(result.Updated, pr)
+		default:
+			result.Unchanged++
+		}
+	}
+
+	if len(watermark) > 0 && latest != nil {
+		stmt := `
+		INSERT INTO etl_watermarks (name, last_modified_time, updated_at) VALUES($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET last_modified_time=EXCLUDED.last_modified_time, updated_at=EXCLUDED.updated_at;
+`
+		if _, err := tx.ExecContext(ctx, stmt, watermark, latest, now); err != nil {
+			return nil, fmt.Errorf("error saving the %s watermark: %w", watermark, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit the transaction for upserting pregnancies: %w", err)
+	}
+	return &result, nil
+}
+
+// FindChangesByRun returns the pregnancy fields that were changed by an etl run.
+func (p Pregnancies) FindChangesByRun(ctx context.Context, runId string) ([]Change, error) {
+	ctx, cancel := p.EmtctDb.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT pregnancy_id, field, old_value, new_value, changed_at
+	FROM pregnancy_changes
+	WHERE etl_run_id=$1
+	ORDER BY pregnancy_id, field;
+`
+	rows, err := p.EmtctDb.QueryContext(ctx, stmt, runId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pregnancy changes from emtct db: %w", err)
+	}
+	defer rows.Close()
+	var changes []Change
+	for rows.Next() {
+		var c Change
+		if err := rows.Scan(&c.PregnancyId, &c.Field, &c.OldValue, &c.NewValue, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("error scanning pregnancy change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
@@ -0,0 +1,32 @@
+package pregnancy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPregnancyDiff(t *testing.T) {
+	lmp := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	// ACSIS stores timestamps, the emtct table only stores the date.
+	acsisLmp := time.Date(2020, 3, 1, 14, 30, 0, 0, time.UTC)
+	edd := time.Date(2020, 12, 6, 0, 0, 0, 0, time.UTC)
+	correctedEdd := time.Date(2020, 12, 10, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2020, 12, 9, 0, 0, 0, 0, time.UTC)
+
+	existing := Pregnancy{PregnancyId: 1, PatientId: 10, Lmp: &lmp, Edd: &edd}
+	acsis := Pregnancy{PregnancyId: 1, PatientId: 10, Lmp: &acsisLmp, Edd: &correctedEdd, EndTime: &endTime}
+
+	changes := existing.Diff(acsis)
+	if len(changes) != 2 {
+		t.Fatalf("want: 2 changes got: %d (%+v)", len(changes), changes)
+	}
+	if changes[0].Field != "edd" || *changes[0].OldValue != "2020-12-06" || *changes[0].NewValue != "2020-12-10" {
+		t.Errorf("unexpected edd change: %+v", changes[0])
+	}
+	if changes[1].Field != "end_time" || changes[1].OldValue != nil || *changes[1].NewValue != "2020-12-09" {
+		t.Errorf("unexpected end_time change: %+v", changes[1])
+	}
+	if len(existing.Diff(existing)) != 0 {
+		t.Errorf("want: no changes when comparing a pregnancy with itself")
+	}
+}
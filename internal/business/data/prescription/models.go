@@ -26,5 +26,6 @@ type SyphilisTreatment struct {
 	CreatedBy  string     `json:"createdBy"`
 	CreatedAt  time.Time  `json:"createdAt"`
 	UpdatedBy  string     `json:"updatedBy"`
+	Version    int        `json:"version"`
 	UpdatedAt  *time.Time `json:"updatedAt"`
 }
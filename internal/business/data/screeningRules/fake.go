@@ -0,0 +1,61 @@
+package screeningRules
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake is an in-memory Store for tests. Its zero value has no rules.
+type Fake struct {
+	mu    sync.Mutex
+	rules Rules
+}
+
+func NewFake(rs ...Rule) *Fake {
+	f := &Fake{}
+	f.SetRules(rs...)
+	return f
+}
+
+// SetRules replaces the rules of the Fake.
+func (f *Fake) SetRules(rs ...Rule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(Rules(nil), rs...)
+}
+
+func (f *Fake) FindRules(ctx context.Context) (Rules, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append(Rules(nil), f.rules...), nil
+}
+
+func (f *Fake) CreateRule(ctx context.Context, r Rule) (*Rule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.rules {
+		if existing.Id >= r.Id {
+			r.Id = existing.Id + 1
+		}
+		if existing.TestName == r.TestName && existing.EffectiveFrom.Equal(r.EffectiveFrom) {
+			return nil, ErrExists
+		}
+	}
+	if r.Id == 0 {
+		r.Id = 1
+	}
+	f.rules = append(f.rules, r)
+	return &r, nil
+}
+
+func (f *Fake) DeleteRule(ctx context.Context, id int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, r := range f.rules {
+		if r.Id == id {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
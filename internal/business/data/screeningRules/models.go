@@ -0,0 +1,106 @@
+// Package screeningRules keeps the testing schedules of infants: when the sample of each test is
+// due after birth. The hiv screenings and the syphilis screenings each have their own table of
+// rules, with the same columns.
+package screeningRules
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrExists is returned by CreateRule when the test already has a rule effective from the same
+// day.
+var ErrExists = errors.New("the test already has a rule effective from that day")
+
+// Rule is when the sample of a test is due under the guidelines in force from EffectiveFrom.
+// The sample is due DueMonths and DueDays after birth, and it is timely when it is taken on or
+// before that day. A guideline is changed by adding a rule with a later EffectiveFrom, so that
+// the infants born before it are still judged by the rule they were tested under.
+type Rule struct {
+	Id            int       `json:"id"`
+	TestName      string    `json:"testName"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	DueMonths     int       `json:"dueMonths"`
+	DueDays       int       `json:"dueDays"`
+	CreatedAt     time.Time `json:"createdAt"`
+	CreatedBy     string    `json:"createdBy"`
+}
+
+// DueDate returns the last day on which the sample of an infant born on birthDate is timely.
+func (r Rule) DueDate(birthDate time.Time) time.Time {
+	return birthDate.AddDate(0, r.DueMonths, r.DueDays)
+}
+
+// IsTimely tells if a sample taken on dateSampleTaken was taken by its due date.
+func (r Rule) IsTimely(birthDate, dateSampleTaken time.Time) bool {
+	return !DayOf(dateSampleTaken).After(DayOf(r.DueDate(birthDate)))
+}
+
+// Rules are the rules of every test, in any order.
+type Rules []Rule
+
+// InForce returns the rule of each test that was in force on birthDate, ordered by due date.
+// A test that has no rule in force on birthDate, e.g. one that was added later, is left out.
+func (rs Rules) InForce(birthDate time.Time) []Rule {
+	byTest := make(map[string]Rule)
+	for _, r := range rs {
+		if DayOf(r.EffectiveFrom).After(DayOf(birthDate)) {
+			continue
+		}
+		if current, ok := byTest[r.TestName]; !ok || r.EffectiveFrom.After(current.EffectiveFrom) {
+			byTest[r.TestName] = r
+		}
+	}
+	inForce := make([]Rule, 0, len(byTest))
+	for _, r := range byTest {
+		inForce = append(inForce, r)
+	}
+	sort.Slice(inForce, func(i, j int) bool {
+		di, dj := inForce[i].DueDate(birthDate), inForce[j].DueDate(birthDate)
+		if di.Equal(dj) {
+			return inForce[i].TestName < inForce[j].TestName
+		}
+		return di.Before(dj)
+	})
+	return inForce
+}
+
+// Find returns the rule of testName that was in force on birthDate.
+func (rs Rules) Find(testName string, birthDate time.Time) (Rule, bool) {
+	for _, r := range rs.InForce(birthDate) {
+		if r.TestName == testName {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// IsTimely indicates if the sample of a test was taken by its due date, under the rule of the
+// test in force when the infant was born. A test without a rule is never timely.
+func (rs Rules) IsTimely(birthDate time.Time, testName string, dateSampleTaken time.Time) bool {
+	r, ok := rs.Find(testName, birthDate)
+	return ok && r.IsTimely(birthDate, dateSampleTaken)
+}
+
+// DueDate returns the due date of the sample of a test, under the rule of the test in force when
+// the infant was born. It is nil for a test without a rule.
+func (rs Rules) DueDate(testName string, birthDate time.Time) *time.Time {
+	r, ok := rs.Find(testName, birthDate)
+	if !ok {
+		return nil
+	}
+	due := r.DueDate(birthDate)
+	return &due
+}
+
+// DayOf returns the calendar day of t, at midnight UTC, so that due dates are compared by day
+// whatever the time the sample was taken.
+func DayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Days returns the number of calendar days from a to b.
+func Days(a, b time.Time) int {
+	return int(DayOf(b).Sub(DayOf(a)).Hours() / 24)
+}
@@ -0,0 +1,79 @@
+package screeningRules
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// guidelines are the rules that the hiv screening migration adds.
+var guidelines = Rules{
+	{Id: 1, TestName: "PCR 1", DueDays: 3},
+	{Id: 2, TestName: "PCR 2", DueDays: 41},
+	{Id: 3, TestName: "PCR 3", DueDays: 90},
+	{Id: 4, TestName: "ELISA", DueMonths: 18},
+}
+
+func TestIsTimely(t *testing.T) {
+	birth := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		testName string
+		days     int
+		want     bool
+	}{
+		{"PCR 1", 3, true},
+		{"PCR 1", 4, false},
+		{"PCR 2", 41, true},
+		{"PCR 2", 42, false},
+		{"PCR 3", 90, true},
+		{"PCR 3", 91, false},
+		// 18 months after the 1st of March 2020 is the 1st of September 2021.
+		{"ELISA", 549, true},
+		{"ELISA", 550, false},
+		{"Other", 1, false},
+	}
+	for _, tt := range tests {
+		if got := guidelines.IsTimely(birth, tt.testName, birth.AddDate(0, 0, tt.days)); got != tt.want {
+			t.Errorf("%s taken %d days after birth: want: %t got: %t", tt.testName, tt.days, tt.want, got)
+		}
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	birth := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got := guidelines.DueDate("PCR 2", birth); got == nil || !got.Equal(birth.AddDate(0, 0, 41)) {
+		t.Errorf("want: PCR 2 due 41 days after birth got: %v", got)
+	}
+	if got := guidelines.DueDate("ELISA", birth); got == nil || !got.Equal(birth.AddDate(0, 18, 0)) {
+		t.Errorf("want: ELISA due 18 months after birth got: %v", got)
+	}
+	if got := guidelines.DueDate("Other", birth); got != nil {
+		t.Errorf("want: no due date for a test without a rule got: %v", got)
+	}
+}
+
+func TestRulesInForce(t *testing.T) {
+	change := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	rules := append(Rules{
+		{Id: 5, TestName: "PCR 2", EffectiveFrom: change, DueDays: 28},
+		{Id: 6, TestName: "PCR 4", EffectiveFrom: change, DueMonths: 9},
+	}, guidelines...)
+
+	before := change.AddDate(0, 0, -1)
+	if r, _ := rules.Find("PCR 2", before); r.Id != 2 {
+		t.Errorf("want: the old PCR 2 rule for an infant born before the change got: %+v", r)
+	}
+	if r, _ := rules.Find("PCR 2", change); r.Id != 5 {
+		t.Errorf("want: the new PCR 2 rule for an infant born on the change got: %+v", r)
+	}
+	var names []string
+	for _, r := range rules.InForce(change) {
+		names = append(names, r.TestName)
+	}
+	if want := "[PCR 1 PCR 2 PCR 3 PCR 4 ELISA]"; fmt.Sprint(names) != want {
+		t.Errorf("want: %s got: %v", want, names)
+	}
+	if len(rules.InForce(before)) != 4 {
+		t.Errorf("want: no PCR 4 before the change got: %+v", rules.InForce(before))
+	}
+}
@@ -0,0 +1,81 @@
+package screeningRules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// Table keeps the rules of a testing schedule in the table Name of the emtct database, e.g.
+// hiv_screening_rules or syphilis_screening_rules.
+type Table struct {
+	*db.EmtctDb
+	Name string
+}
+
+// FindRules returns every rule, the latest effective first.
+func (t Table) FindRules(ctx context.Context) (Rules, error) {
+	ctx, cancel := t.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	SELECT id, test_name, effective_from, due_months, due_days, created_at, created_by
+	FROM ` + t.Name + `
+	ORDER BY effective_from DESC, test_name`
+	rows, err := t.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("error querying the %s: %w", t.Name, err)
+	}
+	defer rows.Close()
+	var rules Rules
+	for rows.Next() {
+		var r Rule
+		if err := rows.Scan(&r.Id, &r.TestName, &r.EffectiveFrom, &r.DueMonths, &r.DueDays, &r.CreatedAt, &r.CreatedBy); err != nil {
+			return nil, fmt.Errorf("error scanning a rule of %s: %w", t.Name, err)
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading the %s: %w", t.Name, err)
+	}
+	return rules, nil
+}
+
+// CreateRule inserts a rule and returns it with its id.
+func (t Table) CreateRule(ctx context.Context, r Rule) (*Rule, error) {
+	ctx, cancel := t.WithTimeout(ctx)
+	defer cancel()
+	stmt := `
+	INSERT INTO ` + t.Name + ` (test_name, effective_from, due_months, due_days, created_at, created_by)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id`
+	err := t.QueryRowContext(ctx, stmt, r.TestName, r.EffectiveFrom, r.DueMonths, r.DueDays, r.CreatedAt, r.CreatedBy).
+		Scan(&r.Id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error inserting a rule in %s: %w", t.Name, err)
+	}
+	return &r, nil
+}
+
+// DeleteRule removes a rule that was added by mistake. It returns false when the rule does not
+// exist.
+func (t Table) DeleteRule(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := t.WithTimeout(ctx)
+	defer cancel()
+	res, err := t.ExecContext(ctx, `DELETE FROM `+t.Name+` WHERE id=$1`, id)
+	if err != nil {
+		return false, fmt.Errorf("error deleting rule %d of %s: %w", id, t.Name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error deleting rule %d of %s: %w", id, t.Name, err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,15 @@
+package screeningRules
+
+import "context"
+
+// Store is implemented by Table and by Fake.
+type Store interface {
+	FindRules(ctx context.Context) (Rules, error)
+	CreateRule(ctx context.Context, r Rule) (*Rule, error)
+	DeleteRule(ctx context.Context, id int) (bool, error)
+}
+
+var (
+	_ Store = Table{}
+	_ Store = (*Fake)(nil)
+)
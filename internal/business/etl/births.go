@@ -0,0 +1,104 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/business/data/infant"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+)
+
+// birthLockKey identifies the advisory lock that is held while births are synced.
+const birthLockKey int64 = 20200131
+
+// Births is the name of the births etl runs in the etl_runs table.
+const Births = "births"
+
+// pregnancyWindow is how long after the LMP an infant can be born and still belong to the
+// pregnancy.
+const pregnancyWindow = time.Hour * 24 * 7 * 54
+
+// BirthSync copies the births of the mothers in the emtct pregnancies table from
+// acsis_hc_births into the emtct infants table, and links every infant to the pregnancy
+// it was born from. Every sync is recorded in the etl_runs table.
+type BirthSync struct {
+	Pregnancies pregnancy.Pregnancies
+	Infants     infant.Infants
+	Runs        etlRuns.EtlRuns
+}
+
+func NewBirthSync(p pregnancy.Pregnancies, i infant.Infants, runs etlRuns.EtlRuns) BirthSync {
+	return BirthSync{Pregnancies: p, Infants: i, Runs: runs}
+}
+
+// LinkBirth returns the id of the pregnancy that an infant born on birthDate belongs to:
+// the pregnancy with the latest LMP that is no more than 54 weeks before the birth.
+// It returns nil if none of the mother's pregnancies match.
+func LinkBirth(birthDate time.Time, motherPregnancies []pregnancy.Pregnancy) *int {
+	var match *pregnancy.Pregnancy
+	for i, p := range motherPregnancies {
+		if p.Lmp == nil || birthDate.Before(*p.Lmp) || birthDate.After(p.Lmp.Add(pregnancyWindow)) {
+			continue
+		}
+		if match == nil || p.Lmp.After(*match.Lmp) {
+			match = &motherPregnancies[i]
+		}
+	}
+	if match == nil {
+		return nil
+	}
+	id := match.PregnancyId
+	return &id
+}
+
+// Sync upserts the ACSIS births of every mother with a pregnancy in the emtct database.
+// Births that were linked by hand keep their pregnancy and mother.
+func (s BirthSync) Sync(ctx context.Context, trigger etlRuns.Trigger, user string) (*infant.BirthsUpsertResult, error) {
+	var result *infant.BirthsUpsertResult
+	run := etlRuns.Run{
+		Name:        Births,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy(user),
+	}
+	_, err := record(ctx, s.Pregnancies.EmtctDb, birthLockKey, s.Runs, run, func(ctx context.Context, run *etlRuns.Run) error {
+		pregs, err := s.Pregnancies.FindAll(ctx)
+		if err != nil {
+			return fmt.Errorf("error retrieving pregnancies from emtct db: %w", err)
+		}
+		byMother := make(map[int][]pregnancy.Pregnancy)
+		var motherIds []int
+		for _, p := range pregs {
+			if _, ok := byMother[p.PatientId]; !ok {
+				motherIds = append(motherIds, p.PatientId)
+			}
+			byMother[p.PatientId] = append(byMother[p.PatientId], p)
+		}
+		births, err := s.Infants.FindBirthsInBhis(ctx, motherIds)
+		if err != nil {
+			return fmt.Errorf("error retrieving births from acsis: %w", err)
+		}
+		for i, b := range births {
+			if b.BirthDate != nil {
+				births[i].PregnancyId = LinkBirth(*b.BirthDate, byMother[b.MotherId])
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err = s.Infants.UpsertBirths(ctx, births)
+		if err != nil {
+			return fmt.Errorf("error upserting births into emtct db: %w", err)
+		}
+		run.RowsRead = len(births)
+		run.RowsInserted = result.Inserted
+		run.RowsUpdated = result.Updated
+		run.RowsSkipped = result.Unchanged
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
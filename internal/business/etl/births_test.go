@@ -0,0 +1,34 @@
+package etl
+
+import (
+	"testing"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+)
+
+func TestLinkBirth(t *testing.T) {
+	firstLmp := time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)
+	secondLmp := time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC)
+	pregs := []pregnancy.Pregnancy{
+		{PregnancyId: 1, PatientId: 10, Lmp: &firstLmp},
+		{PregnancyId: 2, PatientId: 10, Lmp: &secondLmp},
+		{PregnancyId: 3, PatientId: 10},
+	}
+
+	// Within 54 weeks of both LMPs, the latest pregnancy wins.
+	id := LinkBirth(time.Date(2019, 1, 20, 0, 0, 0, 0, time.UTC), pregs)
+	if id == nil || *id != 2 {
+		t.Errorf("want: pregnancy 2 got: %v", id)
+	}
+	id = LinkBirth(time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC), pregs)
+	if id == nil || *id != 1 {
+		t.Errorf("want: pregnancy 1 got: %v", id)
+	}
+	if id := LinkBirth(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), pregs); id != nil {
+		t.Errorf("want: no pregnancy for a birth long after the last LMP got: %d", *id)
+	}
+	if id := LinkBirth(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), pregs); id != nil {
+		t.Errorf("want: no pregnancy for a birth before the first LMP got: %d", *id)
+	}
+}
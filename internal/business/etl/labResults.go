@@ -0,0 +1,77 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/business/data/labs"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// labResultLockKey identifies the advisory lock that is held while lab results are synced.
+const labResultLockKey int64 = 20200132
+
+// LabResults is the name of the lab results etl runs in the etl_runs table.
+const LabResults = "lab_results"
+
+// labResultWindow is how long after the LMP the lab tests of a pregnancy are searched for.
+// Pregnancies with an older LMP can no longer get new lab results, so they are only synced once.
+const labResultWindow = time.Hour * 24 * 7 * 52
+
+// LabResultSync copies the ACSIS lab results of the emtct pregnancies into the emtct
+// lab_results table. Every sync is recorded in the etl_runs table.
+type LabResultSync struct {
+	EmtctDb *db.EmtctDb
+	Labs    labs.Labs
+	Runs    etlRuns.EtlRuns
+}
+
+func NewLabResultSync(emtctDb *db.EmtctDb, l labs.Labs, runs etlRuns.EtlRuns) LabResultSync {
+	return LabResultSync{EmtctDb: emtctDb, Labs: l, Runs: runs}
+}
+
+// LabResultSyncResult counts the pregnancies and lab results that were synced.
+type LabResultSyncResult struct {
+	Pregnancies int `json:"pregnancies"`
+	LabResults  int `json:"labResults"`
+}
+
+// Sync replaces the lab results of every pregnancy that was never synced, and of every
+// pregnancy that can still get new lab results.
+func (s LabResultSync) Sync(ctx context.Context, trigger etlRuns.Trigger, user string) (*LabResultSyncResult, error) {
+	var result LabResultSyncResult
+	run := etlRuns.Run{
+		Name:        LabResults,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy(user),
+	}
+	_, err := record(ctx, s.EmtctDb, labResultLockKey, s.Runs, run, func(ctx context.Context, run *etlRuns.Run) error {
+		pregs, err := s.Labs.FindPregnanciesToSync(ctx, time.Now().Add(-labResultWindow))
+		if err != nil {
+			return err
+		}
+		for _, p := range pregs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			results, err := s.Labs.FindLabTestsDuringPregnancy(ctx, p.PatientId, p.Lmp)
+			if err != nil {
+				return fmt.Errorf("error retrieving the lab results of pregnancy %d from acsis: %w", p.PregnancyId, err)
+			}
+			if err := s.Labs.SaveLabResults(ctx, run.Id, p.PregnancyId, results); err != nil {
+				return err
+			}
+			result.Pregnancies++
+			result.LabResults += len(results)
+			run.RowsRead += len(results)
+			run.RowsInserted += len(results)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
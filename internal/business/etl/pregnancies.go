@@ -0,0 +1,173 @@
+// Package etl copies the ACSIS data that the augmentor depends on into the emtct database.
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+)
+
+// pregnancyLockKey identifies the advisory lock that is held while pregnancies are synced.
+// It guarantees that only one server replica (or manual run) syncs pregnancies at a time.
+const pregnancyLockKey int64 = 20200130
+
+// pregnancyWatermark is the name of the watermark that records how far the incremental
+// pregnancy sync has read acsis_hc_pregnancies.last_modified_time.
+const pregnancyWatermark = "acsis_hc_pregnancies"
+
+// Names of the pregnancy etl runs in the etl_runs table.
+const (
+	PregnanciesByYear      = "pregnancies"
+	PregnanciesIncremental = "pregnancies_incremental"
+)
+
+// PregnancySync copies pregnancies from acsis_hc_pregnancies into the emtct pregnancies table.
+// Every sync is recorded in the etl_runs table.
+type PregnancySync struct {
+	Pregnancies pregnancy.Pregnancies
+	Runs        etlRuns.EtlRuns
+	// BatchSize is the number of pregnancies read and written at a time by SyncYear.
+	// It defaults to pregnancy.DefaultBatchSize.
+	BatchSize int
+}
+
+func NewPregnancySync(p pregnancy.Pregnancies, runs etlRuns.EtlRuns) PregnancySync {
+	return PregnancySync{Pregnancies: p, Runs: runs}
+}
+
+func (s PregnancySync) batchSize() int {
+	if s.BatchSize > 0 {
+		return s.BatchSize
+	}
+	return pregnancy.DefaultBatchSize
+}
+
+// record runs fn while holding the pregnancy advisory lock.
+func (s PregnancySync) record(ctx context.Context, run etlRuns.Run, fn func(ctx context.Context, run *etlRuns.Run) error) (*etlRuns.Run, error) {
+	return record(ctx, s.Pregnancies.EmtctDb, pregnancyLockKey, s.Runs, run, fn)
+}
+
+// YearResult is the outcome of a pregnancy sync of a year.
+type YearResult struct {
+	Year int
+	// Read is the number of ACSIS pregnancies with an LMP in the year.
+	Read     int
+	Inserted []pregnancy.Pregnancy
+	// Failed are the ACSIS pregnancies that could not be copied and were skipped.
+	Failed []pregnancy.RowError
+}
+
+// SyncYear inserts the pregnancies with an LMP in the given year that exist in ACSIS
+// but not in the emtct database. ACSIS is read and the emtct database is written in batches,
+// and a pregnancy that can not be copied is skipped and saved as an error of the run.
+func (s PregnancySync) SyncYear(ctx context.Context, trigger etlRuns.Trigger, user string, year int) (*YearResult, error) {
+	result := YearResult{Year: year}
+	run := etlRuns.Run{
+		Name:        PregnanciesByYear,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy(user),
+		YearFrom:    &year,
+		YearTo:      &year,
+	}
+	_, err := s.record(ctx, run, func(ctx context.Context, run *etlRuns.Run) error {
+		var failed []etlRuns.RecordError
+		err := s.Pregnancies.StreamPregnanciesInBhisByYear(ctx, year, s.batchSize(), func(ps []pregnancy.Pregnancy, bad []pregnancy.RowError) error {
+			inserted, badInserts, err := s.Pregnancies.CreateBatch(ctx, ps)
+			if err != nil {
+				return fmt.Errorf("error inserting pregnancies into emtct db: %w", err)
+			}
+			bad = append(bad, badInserts...)
+			for _, b := range bad {
+				log.WithFields(log.Fields{
+					"year":        year,
+					"pregnancyId": b.PregnancyId,
+					"error":       b.Error,
+				}).Warn("skipping pregnancy that could not be copied")
+				failed = append(failed, etlRuns.RecordError{RecordId: b.PregnancyId, Error: b.Error})
+			}
+			result.Read += len(ps) + len(bad) - len(badInserts)
+			result.Inserted = append(result.Inserted, inserted...)
+			result.Failed = append(result.Failed, bad...)
+			return nil
+		})
+		run.RowsRead = result.Read
+		run.RowsInserted = len(result.Inserted)
+		run.RowsFailed = len(result.Failed)
+		run.RowsSkipped = result.Read - len(result.Inserted) - len(result.Failed)
+		// Save the errors of the batches that were copied even when a later batch failed.
+		if saveErr := s.Runs.SaveErrors(context.Background(), run.Id, failed); saveErr != nil && err == nil {
+			err = saveErr
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SyncModified upserts every ACSIS pregnancy that was modified since the last incremental
+// sync, so that corrections to the LMP, EDD and end time reach the emtct database.
+// The first incremental sync reads pregnancies modified since the start of the previous year.
+func (s PregnancySync) SyncModified(ctx context.Context, trigger etlRuns.Trigger, user string) (*pregnancy.UpsertResult, error) {
+	return s.syncModified(ctx, trigger, user, nil)
+}
+
+// SyncSince upserts every ACSIS pregnancy that was modified at or after since. Unlike
+// SyncModified it neither reads nor advances the incremental sync watermark, so it can be
+// used to backfill older changes.
+func (s PregnancySync) SyncSince(ctx context.Context, trigger etlRuns.Trigger, user string, since time.Time) (*pregnancy.UpsertResult, error) {
+	return s.syncModified(ctx, trigger, user, &since)
+}
+
+// syncModified upserts the pregnancies modified since the given time, or since the
+// watermark when since is nil.
+func (s PregnancySync) syncModified(ctx context.Context, trigger etlRuns.Trigger, user string, since *time.Time) (*pregnancy.UpsertResult, error) {
+	var result *pregnancy.UpsertResult
+	run := etlRuns.Run{
+		Name:        PregnanciesIncremental,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy(user),
+	}
+	_, err := s.record(ctx, run, func(ctx context.Context, run *etlRuns.Run) error {
+		watermark := ""
+		if since == nil {
+			watermark = pregnancyWatermark
+			var err error
+			since, err = s.Pregnancies.FindWatermark(ctx, pregnancyWatermark)
+			if err != nil {
+				return err
+			}
+		}
+		if since == nil {
+			start := time.Date(time.Now().Year()-1, time.January, 1, 0, 0, 0, 0, time.Local)
+			since = &start
+		}
+		run.Since = since
+		modified, err := s.Pregnancies.FindPregnanciesInBhisModifiedSince(ctx, *since)
+		if err != nil {
+			return fmt.Errorf("error retrieving modified pregnancies from acsis: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err = s.Pregnancies.Upsert(ctx, run.Id, modified, watermark)
+		if err != nil {
+			return fmt.Errorf("error upserting modified pregnancies into emtct db: %w", err)
+		}
+		run.RowsRead = len(modified)
+		run.RowsInserted = len(result.Inserted)
+		run.RowsUpdated = len(result.Updated)
+		run.RowsSkipped = result.Unchanged
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+)
+
+// PregnancyDiff is a pregnancy whose LMP, EDD, end time or patient differ between
+// ACSIS and the emtct database.
+type PregnancyDiff struct {
+	Acsis   pregnancy.Pregnancy `json:"acsis"`
+	Emtct   pregnancy.Pregnancy `json:"emtct"`
+	Changes []pregnancy.Change  `json:"changes"`
+}
+
+// YearPreview is what a pregnancy sync of a year would do.
+type YearPreview struct {
+	Year int `json:"year"`
+	// Read is the number of ACSIS pregnancies with an LMP in the year.
+	Read int `json:"read"`
+	// Insert are the ACSIS pregnancies that are missing from the emtct database.
+	Insert []pregnancy.Pregnancy `json:"insert"`
+	// Changed are the pregnancies that exist in both databases but differ.
+	Changed []PregnancyDiff `json:"changed"`
+	// Removed are the emtct pregnancies that no longer exist, or are inactive, in ACSIS.
+	Removed []pregnancy.Pregnancy `json:"removed"`
+	// Unchanged is the number of ACSIS pregnancies of the year that are already up to date. The
+	// pregnancies whose LMP moved to another year are in Changed but were not Read, so it is not
+	// Read minus Insert and Changed.
+	Unchanged int `json:"unchanged"`
+}
+
+// PreviewYear compares the pregnancies with an LMP in the given year in ACSIS and in the
+// emtct database, without writing anything. It does not take the sync lock and is not
+// recorded as an etl run.
+func (s PregnancySync) PreviewYear(ctx context.Context, year int) (*YearPreview, error) {
+	existing, err := s.Pregnancies.FindExistingPregnanciesByYear(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching existing pregnancies: %w", err)
+	}
+	acsisPregnancies, err := s.Pregnancies.FindPregnanciesInBhisByYear(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pregnancies from acsis: %w", err)
+	}
+
+	preview := YearPreview{
+		Year:    year,
+		Read:    len(acsisPregnancies),
+		Insert:  []pregnancy.Pregnancy{},
+		Changed: []PregnancyDiff{},
+		Removed: []pregnancy.Pregnancy{},
+	}
+	acsisById := make(map[int]pregnancy.Pregnancy)
+	readIds := make(map[int]struct{})
+	for _, p := range acsisPregnancies {
+		acsisById[p.PregnancyId] = p
+		readIds[p.PregnancyId] = struct{}{}
+		if !p.Include(existing) {
+			preview.Insert = append(preview.Insert, p)
+		}
+	}
+
+	// An emtct pregnancy that is missing from this year in ACSIS may have had its LMP moved
+	// to another year, so look those up by id before calling them removed.
+	var missing []int
+	for _, e := range existing {
+		if _, ok := acsisById[e.PregnancyId]; !ok {
+			missing = append(missing, e.PregnancyId)
+		}
+	}
+	if len(missing) > 0 {
+		moved, err := s.Pregnancies.FindPregnanciesInBhisByIds(ctx, missing)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving pregnancies missing from the year from acsis: %w", err)
+		}
+		for id, p := range moved {
+			acsisById[id] = p
+		}
+	}
+
+	for _, e := range existing {
+		a, ok := acsisById[e.PregnancyId]
+		if !ok || !a.Active {
+			preview.Removed = append(preview.Removed, e)
+			continue
+		}
+		_, read := readIds[e.PregnancyId]
+		if changes := e.Diff(a); len(changes) > 0 {
+			preview.Changed = append(preview.Changed, PregnancyDiff{Acsis: a, Emtct: e, Changes: changes})
+		} else if read {
+			preview.Unchanged++
+		}
+	}
+	return &preview, nil
+}
+
+// ModifiedPreview is what a sync of the pregnancies modified since a given time would do.
+type ModifiedPreview struct {
+	Since time.Time `json:"since"`
+	// Insert are the modified ACSIS pregnancies that are missing from the emtct database.
+	Insert []pregnancy.Pregnancy `json:"insert"`
+	// Changed are the modified pregnancies that differ from the emtct database.
+	Changed []PregnancyDiff `json:"changed"`
+	// Unchanged is the number of modified pregnancies that are already up to date.
+	Unchanged int `json:"unchanged"`
+}
+
+// PreviewSince compares the ACSIS pregnancies modified at or after since with the emtct
+// database, without writing anything.
+func (s PregnancySync) PreviewSince(ctx context.Context, since time.Time) (*ModifiedPreview, error) {
+	modified, err := s.Pregnancies.FindPregnanciesInBhisModifiedSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving modified pregnancies from acsis: %w", err)
+	}
+	ids := make([]int, 0, len(modified))
+	for _, p := range modified {
+		ids = append(ids, p.PregnancyId)
+	}
+	existing, err := s.Pregnancies.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving existing pregnancies: %w", err)
+	}
+	preview := ModifiedPreview{
+		Since:   since,
+		Insert:  []pregnancy.Pregnancy{},
+		Changed: []PregnancyDiff{},
+	}
+	for _, a := range modified {
+		e, ok := existing[a.PregnancyId]
+		if !ok {
+			preview.Insert = append(preview.Insert, a)
+			continue
+		}
+		if changes := e.Diff(a); len(changes) > 0 {
+			preview.Changed = append(preview.Changed, PregnancyDiff{Acsis: a, Emtct: e, Changes: changes})
+			continue
+		}
+		preview.Unchanged++
+	}
+	return &preview, nil
+}
@@ -0,0 +1,60 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// ErrSyncInProgress is returned when another process is already running the same sync.
+var ErrSyncInProgress = errors.New("the sync is already in progress")
+
+// record runs fn while holding the advisory lock identified by lockKey, and saves the run
+// with the row counts that fn set on it. A run that could not acquire the lock is not recorded.
+func record(ctx context.Context, lockDb *db.EmtctDb, lockKey int64, runs etlRuns.EtlRuns, run etlRuns.Run, fn func(ctx context.Context, run *etlRuns.Run) error) (*etlRuns.Run, error) {
+	acquired, err := lockDb.WithAdvisoryLock(ctx, lockKey, func(ctx context.Context) error {
+		run.Id = uuid.New().String()
+		run.StartedAt = time.Now()
+		if err := runs.Create(ctx, run); err != nil {
+			return err
+		}
+		err := fn(ctx, &run)
+		finishedAt := time.Now()
+		duration := finishedAt.Sub(run.StartedAt).Milliseconds()
+		run.FinishedAt = &finishedAt
+		run.DurationMs = &duration
+		if err != nil {
+			msg := err.Error()
+			run.Error = &msg
+		}
+		// Save the outcome even when ctx was cancelled.
+		if finishErr := runs.Finish(context.Background(), run); finishErr != nil {
+			if err != nil {
+				log.WithFields(log.Fields{"run": run}).WithError(finishErr).Error("could not save the failed etl run")
+				return err
+			}
+			return finishErr
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrSyncInProgress
+	}
+	return &run, nil
+}
+
+func triggeredBy(user string) *string {
+	if len(user) == 0 {
+		return nil
+	}
+	return &user
+}
@@ -0,0 +1,120 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/config"
+)
+
+// Scheduler runs the incremental pregnancy sync in the background on a cron schedule,
+// followed by the births and lab results syncs of the synced pregnancies.
+type Scheduler struct {
+	cron       *cron.Cron
+	sync       PregnancySync
+	births     BirthSync
+	labResults LabResultSync
+
+	// ctx is passed to every sync, and is cancelled when the scheduler
+	// is stopped before a running sync could finish.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewScheduler(cnf config.EtlConf, sync PregnancySync, births BirthSync, labResults LabResultSync) (*Scheduler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		// Never start a sync while the previous one is still running.
+		cron:       cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		sync:       sync,
+		births:     births,
+		labResults: labResults,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	if _, err := s.cron.AddFunc(cnf.Schedule, s.run); err != nil {
+		cancel()
+		return nil, fmt.Errorf("invalid etl schedule %q: %w", cnf.Schedule, err)
+	}
+	return s, nil
+}
+
+// Start starts the scheduler in its own goroutine.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop prevents new syncs from being scheduled and waits for a running sync to finish.
+// If ctx is done before the sync finishes, the sync is cancelled.
+func (s *Scheduler) Stop(ctx context.Context) {
+	done := s.cron.Stop()
+	select {
+	case <-done.Done():
+	case <-ctx.Done():
+		log.Info("etl scheduler did not drain in time, cancelling the running sync")
+		s.cancel()
+		<-done.Done()
+	}
+	s.cancel()
+}
+
+func (s *Scheduler) run() {
+	s.syncPregnancies()
+	s.syncBirths()
+	s.syncLabResults()
+}
+
+func (s *Scheduler) syncPregnancies() {
+	result, err := s.sync.SyncModified(s.ctx, etlRuns.Scheduled, "")
+	if errors.Is(err, ErrSyncInProgress) {
+		log.Info("skipping scheduled pregnancy sync, another sync is in progress")
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("scheduled pregnancy sync failed")
+		return
+	}
+	log.WithFields(log.Fields{
+		"inserted":  len(result.Inserted),
+		"updated":   len(result.Updated),
+		"unchanged": result.Unchanged,
+	}).Info("scheduled pregnancy sync finished")
+}
+
+func (s *Scheduler) syncBirths() {
+	result, err := s.births.Sync(s.ctx, etlRuns.Scheduled, "")
+	if errors.Is(err, ErrSyncInProgress) {
+		log.Info("skipping scheduled births sync, another sync is in progress")
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("scheduled births sync failed")
+		return
+	}
+	log.WithFields(log.Fields{
+		"inserted":  result.Inserted,
+		"updated":   result.Updated,
+		"unchanged": result.Unchanged,
+	}).Info("scheduled births sync finished")
+}
+
+func (s *Scheduler) syncLabResults() {
+	result, err := s.labResults.Sync(s.ctx, etlRuns.Scheduled, "")
+	if errors.Is(err, ErrSyncInProgress) {
+		log.Info("skipping scheduled lab results sync, another sync is in progress")
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("scheduled lab results sync failed")
+		return
+	}
+	log.WithFields(log.Fields{
+		"pregnancies": result.Pregnancies,
+		"labResults":  result.LabResults,
+	}).Info("scheduled lab results sync finished")
+}
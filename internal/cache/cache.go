@@ -0,0 +1,125 @@
+// Package cache is a small in-memory cache with a time to live. It is used in front of the
+// ACSIS lookups that nearly every handler repeats, such as the basic info of a patient.
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaxEntries is the number of entries that a Cache holds at most.
+const MaxEntries = 10000
+
+// Cache holds values by patient id until their ttl expires. It is safe for concurrent use.
+// The expired entries are swept once every ttl when a value is set, and a full cache makes room
+// by dropping the entry that expires first.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+	max int
+
+	mu        sync.Mutex
+	entries   map[int]entry
+	lastSweep time.Time
+
+	hits   uint64
+	misses uint64
+}
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// Stats are the counters of a Cache since it was created.
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// Size is the number of entries, including entries that expired since the last sweep.
+	Size int `json:"size"`
+}
+
+func New(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, now: time.Now, max: MaxEntries, entries: make(map[int]entry)}
+}
+
+// Get returns the value of key, unless it is missing or expired.
+func (c *Cache) Get(key int) (interface{}, bool) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	return e.value, true
+}
+
+func (c *Cache) Set(key int, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	_, exists := c.entries[key]
+	if !now.Before(c.lastSweep.Add(c.ttl)) || (!exists && len(c.entries) >= c.max) {
+		c.sweep(now)
+	}
+	if !exists && len(c.entries) >= c.max {
+		c.evict()
+	}
+	c.entries[key] = entry{value: value, expires: now.Add(c.ttl)}
+}
+
+// sweep removes the expired entries.
+func (c *Cache) sweep(now time.Time) {
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.lastSweep = now
+}
+
+// evict removes the entry that expires first.
+func (c *Cache) evict() {
+	first, found := 0, false
+	for k, e := range c.entries {
+		if !found || e.expires.Before(c.entries[first].expires) {
+			first, found = k, true
+		}
+	}
+	delete(c.entries, first)
+}
+
+func (c *Cache) Delete(key int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// DeleteFunc removes the entries for which match returns true.
+func (c *Cache) DeleteFunc(match func(key int, value interface{}) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if match(k, e.value) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	size := len(c.entries)
+	c.mu.Unlock()
+	return Stats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+		Size:   size,
+	}
+}
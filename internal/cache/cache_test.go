@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	c := New(time.Minute)
+	c.now = func() time.Time { return now }
+
+	if _, ok := c.Get(1); ok {
+		t.Error("want: a miss for a key that was never set")
+	}
+	c.Set(1, "Maria")
+	if v, ok := c.Get(1); !ok || v != "Maria" {
+		t.Errorf("want: Maria got: %v", v)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get(1); ok {
+		t.Error("want: a miss once the ttl expired")
+	}
+
+	c.Set(2, "Ana")
+	c.Delete(2)
+	if _, ok := c.Get(2); ok {
+		t.Error("want: a miss for a deleted key")
+	}
+
+	want := Stats{Hits: 1, Misses: 3, Size: 0}
+	if got := c.Stats(); got != want {
+		t.Errorf("want: %+v got: %+v", want, got)
+	}
+}
+
+func TestCacheSize(t *testing.T) {
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	c := New(time.Minute)
+	c.now = func() time.Time { return now }
+	c.max = 2
+
+	c.Set(1, "Maria")
+	now = now.Add(time.Second)
+	c.Set(2, "Ana")
+	c.Set(3, "Jose")
+	if _, ok := c.Get(1); ok {
+		t.Error("want: the entry that expires first dropped from a full cache")
+	}
+	if c.Stats().Size != 2 {
+		t.Errorf("want: 2 entries got: %d", c.Stats().Size)
+	}
+
+	// Entries that are never read again are swept once they expired.
+	now = now.Add(time.Minute)
+	c.Set(4, "Pedro")
+	if size := c.Stats().Size; size != 1 {
+		t.Errorf("want: the expired entries swept got: %d entries", size)
+	}
+
+	c.DeleteFunc(func(key int, value interface{}) bool { return value == "Pedro" })
+	if _, ok := c.Get(4); ok {
+		t.Error("want: a miss for an entry removed by DeleteFunc")
+	}
+}
@@ -1,25 +1,69 @@
 package config
 
-import "github.com/spf13/viper"
-import log "github.com/sirupsen/logrus"
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
 
 type DbConf struct {
 	Username string
 	Password string
 	Database string
 	Host     string
+	// Port defaults to 5432.
+	Port int
+	// SslMode is one of the postgres sslmodes: disable, require, verify-ca or verify-full.
+	// Defaults to disable.
+	SslMode string
+	// SslRootCert is the path of the CA certificate used by the verify-ca and verify-full modes.
+	SslRootCert string
+	// MaxOpenConns and MaxIdleConns size the connection pool. Zero keeps the database/sql defaults.
+	MaxOpenConns int
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections that have been open for longer, e.g. "30m".
+	ConnMaxLifetime time.Duration
+	// ConnectAttempts is how many times the database is pinged at startup before giving up.
+	// Defaults to db.DefaultConnectAttempts.
+	ConnectAttempts int
+	// QueryTimeout bounds every query to the database, e.g. "30s". Queries that take
+	// longer are cancelled. Defaults to db.DefaultQueryTimeout.
+	QueryTimeout time.Duration
+	// MaxParallelQueries bounds the ACSIS queries that a single request runs at the same
+	// time. Defaults to db.DefaultMaxParallelQueries.
+	MaxParallelQueries int
 }
 
 type AuthConf struct {
 	JwkUrl   string
 	Issuer   string
 	Audience string
+	// Admins are the emails of the users that can use the admin endpoints.
+	Admins []string
+}
+
+// CacheConf sets how long the ACSIS lookups of each entity are cached, e.g. "10m".
+// Zero disables the cache of that entity.
+type CacheConf struct {
+	Patients time.Duration
+	Infants  time.Duration
+}
+
+// EtlConf configures the background job that copies pregnancies from ACSIS
+// into the emtct database. Schedule is a standard 5 field cron expression,
+// e.g. "0 2 * * *" runs the sync every night at 2am.
+type EtlConf struct {
+	Enabled  bool
+	Schedule string
 }
 
 type AppConf struct {
 	EmtctDb DbConf
 	Auth    AuthConf
 	AcsisDb DbConf
+	Etl     EtlConf
+	Cache   CacheConf
 }
 
 // ReadConf reads a yaml file and unmarshalls its content.
@@ -54,10 +98,28 @@ func ReadConf(fileName string) (*AppConf, error) {
 		return nil, err
 	}
 
+	// The etl section is optional. When it is missing the scheduled sync is disabled.
+	var etlConf EtlConf
+	if sub := viper.Sub("etl"); sub != nil {
+		if err := sub.Unmarshal(&etlConf); err != nil {
+			return nil, err
+		}
+	}
+
+	// The cache section is optional. When it is missing nothing is cached.
+	var cacheConf CacheConf
+	if sub := viper.Sub("cache"); sub != nil {
+		if err := sub.Unmarshal(&cacheConf); err != nil {
+			return nil, err
+		}
+	}
+
 	appConf := AppConf{
 		EmtctDb: c,
 		Auth:    a,
 		AcsisDb: acsisConf,
+		Etl:     etlConf,
+		Cache:   cacheConf,
 	}
 
 	return &appConf, nil
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestReadConf(t *testing.T) {
@@ -12,4 +13,16 @@ func TestReadConf(t *testing.T) {
 	if conf.EmtctDb.Username != "postgres" {
 		t.Errorf("want: %s got: %s", "postgres", conf.EmtctDb.Username)
 	}
+	if conf.EmtctDb.QueryTimeout != 10*time.Second {
+		t.Errorf("want: %s got: %s", 10*time.Second, conf.EmtctDb.QueryTimeout)
+	}
+	if len(conf.Auth.Admins) != 1 || conf.Auth.Admins[0] != "admin@example.com" {
+		t.Errorf("want: %v got: %v", []string{"admin@example.com"}, conf.Auth.Admins)
+	}
+	if conf.Cache.Infants != 10*time.Minute {
+		t.Errorf("want: %s got: %s", 10*time.Minute, conf.Cache.Infants)
+	}
+	if conf.Etl.Schedule != "0 2 * * *" {
+		t.Errorf("want: %s got: %s", "0 2 * * *", conf.Etl.Schedule)
+	}
 }
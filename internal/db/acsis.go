@@ -2,20 +2,24 @@ package db
 
 import (
 	"database/sql"
-	"fmt"
+	"time"
 
 	"moh.gov.bz/mch/emtct/internal/config"
 )
 
 type AcsisDb struct {
 	*sql.DB
+	QueryTimeout time.Duration
+	// MaxParallelQueries bounds the queries that Parallel runs at the same time.
+	MaxParallelQueries int
 }
 
+// NewAcsisConnection opens a pool of connections to ACSIS, the national EHR. Every
+// transaction on these connections is read only, so the augmentor can never write to ACSIS.
 func NewAcsisConnection(cnf *config.DbConf) (*AcsisDb, error) {
-	connstr := fmt.Sprintf("user=%s dbname=%s password=%s host=%s sslmode=disable", cnf.Username, cnf.Database, cnf.Password, cnf.Host)
-	db, err := sql.Open("postgres", connstr)
+	db, err := open(cnf, "default_transaction_read_only=on")
 	if err != nil {
 		return nil, err
 	}
-	return &AcsisDb{db}, nil
+	return &AcsisDb{DB: db, QueryTimeout: cnf.QueryTimeout, MaxParallelQueries: cnf.MaxParallelQueries}, nil
 }
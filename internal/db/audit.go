@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// The actions that are recorded in the audit_log.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// Audited runs write in a transaction and records it in the audit_log against the row of
+// table with the given id. The row is saved as JSON before and after the write, so every
+// column that the write changed can be seen in the history of the row. Nothing is recorded
+// when the write did not change the row. The caller sets the timeout of ctx.
+func (d *EmtctDb) Audited(ctx context.Context, table, id, action, user string, write func(tx *sql.Tx) error) error {
+	return d.AuditedByKey(ctx, table, "id", id, action, user, write)
+}
+
+// AuditedByKey is Audited for a table whose rows are identified by the key column instead of
+// id. The row is locked until the write is committed, so that a concurrent write can not change
+// it between the snapshot before the write and the write itself.
+func (d *EmtctDb) AuditedByKey(ctx context.Context, table, key, id, action, user string, write func(tx *sql.Tx) error) error {
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting the transaction to write %s %s: %w", table, id, err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, table, key, id, true)
+	if err != nil {
+		return err
+	}
+	if err := write(tx); err != nil {
+		return err
+	}
+	after, err := snapshot(ctx, tx, table, key, id, false)
+	if err != nil {
+		return err
+	}
+	if before.String != after.String || before.Valid != after.Valid {
+		stmt := `
+		INSERT INTO audit_log (entity_type, entity_id, action, user_email, created_at, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		_, err := tx.ExecContext(ctx, stmt, table, id, action, user, time.Now(), before, after)
+		if err != nil {
+			return fmt.Errorf("error recording the %s of %s %s in the audit log: %w", action, table, id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing the %s of %s %s: %w", action, table, id, err)
+	}
+	return nil
+}
+
+// snapshot returns the row of table whose key column is id as JSON, or a null string when there
+// is no such row. The row is locked FOR UPDATE when lock is set.
+func snapshot(ctx context.Context, tx *sql.Tx, table, key, id string, lock bool) (sql.NullString, error) {
+	var row sql.NullString
+	stmt := fmt.Sprintf(`SELECT to_jsonb(t)::text FROM %s t WHERE %s=$1`, table, key)
+	if lock {
+		stmt += ` FOR UPDATE`
+	}
+	err := tx.QueryRowContext(ctx, stmt, id).Scan(&row)
+	if err != nil && err != sql.ErrNoRows {
+		return row, fmt.Errorf("error reading %s %s for the audit log: %w", table, id, err)
+	}
+	return row, nil
+}
+
+// AuditUser returns the user of an optional updated by, or an empty string when it is not set.
+func AuditUser(updatedBy *string) string {
+	if updatedBy == nil {
+		return ""
+	}
+	return *updatedBy
+}
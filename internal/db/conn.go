@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"moh.gov.bz/mch/emtct/internal/config"
+)
+
+const (
+	// DefaultConnectAttempts is used when the configuration does not set the number of connect attempts.
+	DefaultConnectAttempts = 5
+	// pingTimeout bounds a single ping at startup.
+	pingTimeout = 5 * time.Second
+	// maxBackoff is the longest wait between two pings.
+	maxBackoff = 30 * time.Second
+)
+
+// connString builds the lib/pq connection string of cnf. Extra parameters, e.g. runtime
+// settings for the session, are appended as they are.
+func connString(cnf *config.DbConf, extra ...string) string {
+	port := cnf.Port
+	if port == 0 {
+		port = 5432
+	}
+	sslMode := cnf.SslMode
+	if len(sslMode) == 0 {
+		sslMode = "disable"
+	}
+	params := []string{
+		"user=" + quote(cnf.Username),
+		"password=" + quote(cnf.Password),
+		"dbname=" + quote(cnf.Database),
+		"host=" + quote(cnf.Host),
+		fmt.Sprintf("port=%d", port),
+		"sslmode=" + quote(sslMode),
+	}
+	if len(cnf.SslRootCert) > 0 {
+		params = append(params, "sslrootcert="+quote(cnf.SslRootCert))
+	}
+	params = append(params, extra...)
+	return strings.Join(params, " ")
+}
+
+// quote quotes a connection string value, so that values with spaces or quotes, e.g.
+// passwords, are passed to postgres unchanged.
+func quote(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+// open opens a connection pool sized by cnf and waits until the database answers.
+func open(cnf *config.DbConf, extra ...string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", connString(cnf, extra...))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cnf.MaxOpenConns)
+	if cnf.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cnf.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cnf.ConnMaxLifetime)
+
+	if err := ping(db, cnf); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// ping pings the database until it answers, waiting twice as long after every failed attempt.
+func ping(db *sql.DB, cnf *config.DbConf) error {
+	attempts := cnf.ConnectAttempts
+	if attempts <= 0 {
+		attempts = DefaultConnectAttempts
+	}
+	backoff := time.Second
+	var err error
+	for i := 1; i <= attempts; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if i == attempts {
+			break
+		}
+		log.WithFields(log.Fields{
+			"host":     cnf.Host,
+			"database": cnf.Database,
+			"attempt":  i,
+			"retryIn":  backoff.String(),
+		}).WithError(err).Warn("could not reach the database")
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+	return fmt.Errorf("could not reach database %s on %s after %d attempts: %w", cnf.Database, cnf.Host, attempts, err)
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+
+	"moh.gov.bz/mch/emtct/internal/config"
+)
+
+func TestConnString(t *testing.T) {
+	tests := []struct {
+		name  string
+		cnf   config.DbConf
+		extra []string
+		want  string
+	}{
+		{
+			name: "defaults",
+			cnf:  config.DbConf{Username: "postgres", Password: "password", Database: "emtct", Host: "localhost"},
+			want: "user='postgres' password='password' dbname='emtct' host='localhost' port=5432 sslmode='disable'",
+		},
+		{
+			name: "tls with a ca certificate",
+			cnf: config.DbConf{
+				Username:    "consultant",
+				Password:    `it's a \ secret`,
+				Database:    "acsis",
+				Host:        "acsis.example.com",
+				Port:        6432,
+				SslMode:     "verify-full",
+				SslRootCert: "/etc/emtct/acsis-ca.pem",
+			},
+			extra: []string{"default_transaction_read_only=on"},
+			want: `user='consultant' password='it\'s a \\ secret' dbname='acsis' host='acsis.example.com' port=6432 ` +
+				`sslmode='verify-full' sslrootcert='/etc/emtct/acsis-ca.pem' default_transaction_read_only=on`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := connString(&tt.cnf, tt.extra...); got != tt.want {
+				t.Errorf("want: %s got: %s", tt.want, got)
+			}
+		})
+	}
+}
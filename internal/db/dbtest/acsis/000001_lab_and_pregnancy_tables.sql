@@ -0,0 +1,124 @@
+-- The subset of the ACSIS schema that is read by the lab and pregnancy queries. Only the
+-- columns that the queries use are created, with the types that the queries expect.
+
+CREATE TABLE acsis_hc_obstetric_patient_details (
+    obstetric_patient_details_id int PRIMARY KEY,
+    number_liveborn_pregnancies  int,
+    number_caesarean_sections    int,
+    previous_pregnancy_planned   boolean
+);
+
+CREATE TABLE acsis_hc_patients (
+    patient_id                   int PRIMARY KEY,
+    obstetric_patient_details_id int REFERENCES acsis_hc_obstetric_patient_details,
+    birth_date                   timestamp
+);
+
+CREATE TABLE acsis_hc_pregnancies (
+    pregnancy_id               int PRIMARY KEY,
+    patient_id                 int NOT NULL REFERENCES acsis_hc_patients,
+    last_menstrual_period_date timestamp,
+    estimated_delivery_date    timestamp,
+    last_modified_time         timestamp NOT NULL
+);
+
+CREATE TABLE acsis_hc_facility_types (
+    facility_type_id int PRIMARY KEY
+);
+
+CREATE TABLE acsis_hc_facilities (
+    facility_id      int PRIMARY KEY,
+    name             text NOT NULL,
+    facility_type_id int NOT NULL REFERENCES acsis_hc_facility_types
+);
+
+CREATE TABLE acsis_adt_mch_encounter_details (
+    mch_encounter_details_id   int PRIMARY KEY,
+    estimated_delivery_date    timestamp,
+    number_of_antenatal_visits int
+);
+
+CREATE TABLE acsis_adt_encounters (
+    encounter_id         int PRIMARY KEY,
+    patient_id           int NOT NULL REFERENCES acsis_hc_patients,
+    encounter_type       char(1) NOT NULL,
+    encounter_details_id int,
+    facility_id          int REFERENCES acsis_hc_facilities,
+    begin_time           timestamp NOT NULL,
+    active               boolean NOT NULL DEFAULT true
+);
+
+CREATE TABLE acsis_hc_birth_statuses (
+    birth_status_id int PRIMARY KEY,
+    name            text
+);
+
+CREATE TABLE acsis_adt_labour_encounter_details (
+    mch_encounter_id int PRIMARY KEY,
+    birth_status_id  int REFERENCES acsis_hc_birth_statuses
+);
+
+CREATE TABLE acsis_hc_infant_patient_details (
+    patient_id         int PRIMARY KEY,
+    mothers_patient_id int,
+    apgar_first_minute int,
+    apgar_fifth_minute int
+);
+
+CREATE TABLE acsis_adt_icd10_diseases (
+    disease_id int PRIMARY KEY,
+    code       text NOT NULL,
+    name       text NOT NULL
+);
+
+CREATE TABLE acsis_adt_encounter_diagnoses (
+    encounter_id   int NOT NULL REFERENCES acsis_adt_encounters,
+    disease_id     int NOT NULL REFERENCES acsis_adt_icd10_diseases,
+    diagnosis_time timestamp NOT NULL
+);
+
+CREATE TABLE acsis_lab_tests (
+    test_id int PRIMARY KEY,
+    name    text NOT NULL
+);
+
+CREATE TABLE acsis_lab_test_requests (
+    test_request_id            int PRIMARY KEY,
+    encounter_id               int NOT NULL REFERENCES acsis_adt_encounters,
+    order_received_by_lab_time timestamp,
+    last_modified_time         timestamp NOT NULL
+);
+
+CREATE TABLE acsis_lab_test_request_items (
+    test_request_item_id int PRIMARY KEY,
+    test_request_id      int NOT NULL REFERENCES acsis_lab_test_requests,
+    test_id              int NOT NULL REFERENCES acsis_lab_tests,
+    released_time        timestamp
+);
+
+CREATE TABLE acsis_lab_test_request_specimen_types (
+    test_request_specimen_type_id int PRIMARY KEY,
+    test_request_id               int NOT NULL REFERENCES acsis_lab_test_requests
+);
+
+CREATE TABLE acsis_lab_test_samples (
+    test_sample_id                int PRIMARY KEY,
+    test_request_specimen_type_id int NOT NULL REFERENCES acsis_lab_test_request_specimen_types,
+    collected_time                timestamp
+);
+
+CREATE TABLE acsis_lab_test_results (
+    test_result_id int PRIMARY KEY,
+    label          text NOT NULL
+);
+
+CREATE TABLE acsis_lab_user_defined_list_items (
+    user_defined_list_item_id int PRIMARY KEY,
+    name                      text NOT NULL
+);
+
+CREATE TABLE acsis_lab_test_request_results_collected (
+    test_request_item_id    int NOT NULL REFERENCES acsis_lab_test_request_items,
+    test_result_id          int NOT NULL REFERENCES acsis_lab_test_results,
+    user_defined_list_value int REFERENCES acsis_lab_user_defined_list_items
+);
@@ -0,0 +1,102 @@
+-- The people, births, diagnoses and prescriptions that are read by the patient, infant and
+-- hiv queries.
+
+CREATE TABLE acsis_territories (
+    territory_id int PRIMARY KEY,
+    name         text NOT NULL
+);
+
+CREATE TABLE acsis_municipalities (
+    municipality_id int PRIMARY KEY,
+    name            text NOT NULL
+);
+
+CREATE TABLE acsis_contacts (
+    contact_id      int PRIMARY KEY,
+    address1        text,
+    address2        text,
+    phone1          text,
+    municipality_id int REFERENCES acsis_municipalities,
+    territory_id    int REFERENCES acsis_territories
+);
+
+CREATE TABLE acsis_people (
+    person_id   int PRIMARY KEY,
+    first_name  text NOT NULL,
+    middle_name text NOT NULL DEFAULT '',
+    last_name   text NOT NULL,
+    contact_id  int REFERENCES acsis_contacts
+);
+
+CREATE TABLE acsis_adt_next_of_kins (
+    next_of_kin_id int PRIMARY KEY,
+    person_id      int NOT NULL REFERENCES acsis_people
+);
+
+CREATE TABLE acsis_ethnicities (
+    ethnicity_id int PRIMARY KEY,
+    name         text NOT NULL
+);
+
+CREATE TABLE acsis_hc_schooling_levels (
+    schooling_level_id int PRIMARY KEY,
+    name               text NOT NULL
+);
+
+ALTER TABLE acsis_hc_patients
+    ADD COLUMN person_id int REFERENCES acsis_people,
+    ADD COLUMN ssi_number text NOT NULL DEFAULT '',
+    ADD COLUMN birth_place text NOT NULL DEFAULT '',
+    ADD COLUMN next_of_kin_id int REFERENCES acsis_adt_next_of_kins,
+    ADD COLUMN ethnicity_id int REFERENCES acsis_ethnicities,
+    ADD COLUMN schooling_level_id int REFERENCES acsis_hc_schooling_levels;
+
+ALTER TABLE acsis_hc_pregnancies
+    ADD COLUMN end_time timestamp,
+    ADD COLUMN active boolean;
+
+CREATE TABLE acsis_hc_births (
+    birth_id           int PRIMARY KEY,
+    patient_id         int NOT NULL REFERENCES acsis_hc_patients,
+    mother_id          int NOT NULL REFERENCES acsis_hc_patients,
+    birth_status_id    int REFERENCES acsis_hc_birth_statuses,
+    last_modified_time timestamp NOT NULL
+);
+
+CREATE TABLE acsis_hr_staff (
+    staff_id  int PRIMARY KEY,
+    person_id int NOT NULL REFERENCES acsis_people
+);
+
+ALTER TABLE acsis_adt_encounter_diagnoses
+    ADD COLUMN encounter_diagnosis_id serial PRIMARY KEY,
+    ADD COLUMN notes text NOT NULL DEFAULT '',
+    ADD COLUMN doctor_id int REFERENCES acsis_hr_staff;
+
+CREATE TABLE acsis_coe_frequency_units (
+    frequency_unit_id int PRIMARY KEY,
+    name              text NOT NULL
+);
+
+CREATE TABLE acsis_adt_pharmaceutical_units (
+    pharmaceutical_unit_id int PRIMARY KEY,
+    name                   text NOT NULL
+);
+
+CREATE TABLE acsis_adt_pharmaceuticals (
+    pharmaceutical_id int PRIMARY KEY,
+    name              text NOT NULL,
+    strength          text NOT NULL,
+    strength_unit_id  int NOT NULL REFERENCES acsis_adt_pharmaceutical_units
+);
+
+CREATE TABLE acsis_adt_encounter_pharmaceuticals (
+    encounter_pharmaceutical_id                 int PRIMARY KEY,
+    encounter_id                                int NOT NULL REFERENCES acsis_adt_encounters,
+    pharmaceutical_id                           int NOT NULL REFERENCES acsis_adt_pharmaceuticals,
+    frequency_unit_id                           int NOT NULL REFERENCES acsis_coe_frequency_units,
+    total_doses                                 int NOT NULL,
+    prescribing_physician_special_instructions  text NOT NULL DEFAULT '',
+    notes                                       text NOT NULL DEFAULT '',
+    prescribed_time                             timestamp NOT NULL
+);
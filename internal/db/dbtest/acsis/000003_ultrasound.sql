@@ -0,0 +1,4 @@
+-- The gestational age in weeks that an ultrasound found at an anc encounter.
+
+ALTER TABLE acsis_adt_mch_encounter_details
+    ADD COLUMN gestational_age_by_ultrasound numeric;
@@ -0,0 +1,91 @@
+package dbtest
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Fixture is a list of rows to insert, table by table, in order. Fixtures are written in YAML
+// or JSON, see fixtures/mother_infant.yaml. Each row maps column names to values, which are
+// passed to postgres as they are, so dates can be written as "2020-01-10".
+type Fixture []struct {
+	Table string                   `yaml:"table"`
+	Rows  []map[string]interface{} `yaml:"rows"`
+}
+
+//go:embed fixtures/*.yaml
+var fixtures embed.FS
+
+var identifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Load inserts the fixtures named name from fixtures/, e.g. "mother_infant".
+func Load(tb testing.TB, conn *sql.DB, names ...string) {
+	tb.Helper()
+	for _, name := range names {
+		b, err := fixtures.ReadFile("fixtures/" + name + ".yaml")
+		if err != nil {
+			tb.Fatalf("fixture %s does not exist: %v", name, err)
+		}
+		load(tb, conn, name, b)
+	}
+}
+
+// LoadFile inserts the fixture in the YAML or JSON file at path.
+func LoadFile(tb testing.TB, conn *sql.DB, path string) {
+	tb.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	load(tb, conn, path, b)
+}
+
+func load(tb testing.TB, conn *sql.DB, name string, b []byte) {
+	tb.Helper()
+	var f Fixture
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		tb.Fatalf("error parsing fixture %s: %v", name, err)
+	}
+	for _, t := range f {
+		for i, row := range t.Rows {
+			stmt, args, err := insert(t.Table, row)
+			if err != nil {
+				tb.Fatalf("fixture %s: %v", name, err)
+			}
+			if _, err := conn.Exec(stmt, args...); err != nil {
+				tb.Fatalf("fixture %s: error inserting row %d of %s: %v", name, i, t.Table, err)
+			}
+		}
+	}
+}
+
+// insert builds the INSERT statement of a row, with the columns sorted by name.
+func insert(table string, row map[string]interface{}) (string, []interface{}, error) {
+	if !identifier.MatchString(table) {
+		return "", nil, fmt.Errorf("%q is not a valid table name", table)
+	}
+	var columns []string
+	for c := range row {
+		if !identifier.MatchString(c) {
+			return "", nil, fmt.Errorf("%q is not a valid column name of %s", c, table)
+		}
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	params := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, c := range columns {
+		params[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = row[c]
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(params, ", "))
+	return stmt, args, nil
+}
@@ -0,0 +1,46 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestInsert(t *testing.T) {
+	stmt, args, err := insert("acsis_people", map[string]interface{}{"person_id": 100, "first_name": "Maria"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO acsis_people (first_name, person_id) VALUES ($1, $2)"
+	if stmt != want {
+		t.Errorf("want: %s got: %s", want, stmt)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"Maria", 100}) {
+		t.Errorf("want: the values in column order got: %v", args)
+	}
+
+	if _, _, err := insert("acsis_people; DROP TABLE x", nil); err == nil {
+		t.Error("want: an error for an invalid table name")
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	for _, name := range []string{"mother_infant", "emtct_patients"} {
+		b, err := fixtures.ReadFile("fixtures/" + name + ".yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var f Fixture
+		if err := yaml.Unmarshal(b, &f); err != nil {
+			t.Fatalf("error parsing fixture %s: %v", name, err)
+		}
+		for _, table := range f {
+			for _, row := range table.Rows {
+				if _, _, err := insert(table.Table, row); err != nil {
+					t.Errorf("fixture %s: %v", name, err)
+				}
+			}
+		}
+	}
+}
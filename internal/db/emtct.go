@@ -2,22 +2,21 @@ package db
 
 import (
 	"database/sql"
-	"fmt"
+	"time"
 
 	"moh.gov.bz/mch/emtct/internal/config"
 )
 
 type EmtctDb struct {
 	*sql.DB
+	QueryTimeout time.Duration
 }
 
-// NewConnection creates a new database connection
+// NewConnection opens a pool of connections to the emtct database.
 func NewConnection(cnf *config.DbConf) (*EmtctDb, error) {
-	connstr := fmt.Sprintf("user=%s dbname=%s password=%s host=%s sslmode=disable", cnf.Username, cnf.Database, cnf.Password, cnf.Host)
-	db, err := sql.Open("postgres", connstr)
+	db, err := open(cnf)
 	if err != nil {
 		return nil, err
 	}
-
-	return &EmtctDb{db}, nil
+	return &EmtctDb{DB: db, QueryTimeout: cnf.QueryTimeout}, nil
 }
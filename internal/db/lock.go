@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithAdvisoryLock runs fn while holding the postgres session level advisory lock
+// identified by key. The lock is taken with pg_try_advisory_lock, so WithAdvisoryLock
+// does not wait for another session to release it: when the lock is already held
+// fn is not run and acquired is false.
+func (d *EmtctDb) WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (acquired bool, err error) {
+	// Advisory locks belong to a session, so the lock and unlock must use the same connection.
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("could not get a connection for the advisory lock: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("error acquiring advisory lock %d: %w", key, err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		// Release the lock even when ctx has been cancelled.
+		if _, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); unlockErr != nil && err == nil {
+			err = fmt.Errorf("error releasing advisory lock %d: %w", key, unlockErr)
+		}
+	}()
+
+	return true, fn(ctx)
+}
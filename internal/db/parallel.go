@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMaxParallelQueries is used for databases whose configuration does not bound the
+// number of queries that a single request may run at the same time.
+const DefaultMaxParallelQueries = 4
+
+// Parallel runs the independent queries in fns concurrently, at most MaxParallelQueries at a
+// time, and waits for all of them. The first error cancels the context of the queries that
+// are still running, and is returned.
+func (d *AcsisDb) Parallel(ctx context.Context, fns ...func(ctx context.Context) error) error {
+	return parallel(ctx, d.MaxParallelQueries, fns)
+}
+
+func parallel(ctx context.Context, limit int, fns []func(ctx context.Context) error) error {
+	if limit <= 0 {
+		limit = DefaultMaxParallelQueries
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	sem := make(chan struct{}, limit)
+	for _, fn := range fns {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(fn func(ctx context.Context) error) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(ctx); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(fn)
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	var running, most int32
+	query := func(ctx context.Context) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&most)
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+	fns := []func(ctx context.Context) error{query, query, query, query, query}
+	if err := parallel(context.Background(), 2, fns); err != nil {
+		t.Fatal(err)
+	}
+	if most != 2 {
+		t.Errorf("want: at most 2 queries at a time got: %d", most)
+	}
+
+	failed := errors.New("connection reset")
+	var cancelled int32
+	fns = []func(ctx context.Context) error{
+		func(ctx context.Context) error { return failed },
+		func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				atomic.AddInt32(&cancelled, 1)
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		},
+	}
+	if err := parallel(context.Background(), 2, fns); err != failed {
+		t.Errorf("want: %v got: %v", failed, err)
+	}
+	if cancelled != 1 {
+		t.Error("want: the other queries to be cancelled after an error")
+	}
+}
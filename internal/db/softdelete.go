@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SoftDelete marks the row of table with the given id as deleted by user, for reason. The
+// row is kept, but the stores no longer return it. It returns false when there is no such
+// row or it was already deleted.
+func (d *EmtctDb) SoftDelete(ctx context.Context, table, id, user, reason string) (bool, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := fmt.Sprintf(`UPDATE %s SET deleted_at=$1, deleted_by=$2, deletion_reason=$3 WHERE id=$4 AND deleted_at IS NULL`, table)
+	var n int64
+	err := d.Audited(ctx, table, id, AuditDelete, user, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, stmt, time.Now(), user, reason, id)
+		if err != nil {
+			return err
+		}
+		n, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("error deleting %s %s: %w", table, id, err)
+	}
+	return n > 0, nil
+}
+
+// Restore undoes the SoftDelete of the row of table with the given id. It returns false when
+// there is no such row or it is not deleted.
+func (d *EmtctDb) Restore(ctx context.Context, table, id, user string) (bool, error) {
+	ctx, cancel := d.WithTimeout(ctx)
+	defer cancel()
+	stmt := fmt.Sprintf(`UPDATE %s SET deleted_at=NULL, deleted_by=NULL, deletion_reason=NULL WHERE id=$1 AND deleted_at IS NOT NULL`, table)
+	var n int64
+	err := d.Audited(ctx, table, id, AuditRestore, user, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, stmt, id)
+		if err != nil {
+			return err
+		}
+		n, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("error restoring %s %s: %w", table, id, err)
+	}
+	return n > 0, nil
+}
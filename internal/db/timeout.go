@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultQueryTimeout is used for databases whose configuration does not set a query timeout.
+const DefaultQueryTimeout = 30 * time.Second
+
+func queryTimeout(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultQueryTimeout
+	}
+	return d
+}
+
+// WithTimeout returns a copy of ctx that is cancelled when the ACSIS query timeout expires.
+// Every query to ACSIS runs with such a context, so that a slow query is cancelled
+// instead of running on after the client has given up.
+func (d *AcsisDb) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, queryTimeout(d.QueryTimeout))
+}
+
+// WithTimeout returns a copy of ctx that is cancelled when the emtct query timeout expires.
+func (d *EmtctDb) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, queryTimeout(d.QueryTimeout))
+}
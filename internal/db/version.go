@@ -0,0 +1,23 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrVersionConflict is returned by the Edit methods of the stores when the record is not at
+// the version that was edited, because someone else saved it first, or it no longer exists.
+var ErrVersionConflict = errors.New("the record was changed or deleted by someone else")
+
+// CheckVersion returns ErrVersionConflict when the versioned UPDATE of res did not change
+// any row.
+func CheckVersion(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package migrate applies and rolls back the sql migrations of the emtct database. It keeps
+// the version in the schema_migrations table in the same format as the migrate tool, so
+// databases that were migrated with it can be migrated with this package and back.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"moh.gov.bz/mch/emtct/internal/db"
+)
+
+// lockKey identifies the advisory lock that is held while migrations run.
+const lockKey int64 = 20200100
+
+// ErrLocked is returned when another process is migrating the database.
+var ErrLocked = errors.New("another process is migrating the database")
+
+// ErrDirty is returned when a migration run by the migrate tool failed half way. The
+// schema has to be fixed by hand before migrating again.
+var ErrDirty = errors.New("the database is dirty: a migration failed half way and must be fixed by hand")
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a numbered change to the emtct schema and the sql that reverts it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes the version of the schema.
+type Status struct {
+	// Version is the last migration that was applied, or 0 for an empty database.
+	Version int64 `json:"version"`
+	Dirty   bool  `json:"dirty"`
+	// Latest is the last migration that is embedded in the binary.
+	Latest  int64       `json:"latest"`
+	Pending []Migration `json:"-"`
+}
+
+// Behind reports whether there are migrations that have not been applied yet.
+func (s Status) Behind() bool {
+	return s.Version < s.Latest
+}
+
+type Migrator struct {
+	db         *db.EmtctDb
+	migrations []Migration
+}
+
+// New reads the migrations in fsys.
+func New(emtctDb *db.EmtctDb, fsys fs.FS) (*Migrator, error) {
+	ms, err := read(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: emtctDb, migrations: ms}, nil
+}
+
+// read parses the migration files in fsys, sorted by version.
+func read(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error reading the migrations: %w", err)
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		m := fileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", e.Name(), err)
+		}
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", e.Name(), err)
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if m[3] == "up" {
+			mig.Up = string(b)
+		} else {
+			mig.Down = string(b)
+		}
+	}
+	var ms []Migration
+	for _, m := range byVersion {
+		if len(m.Up) == 0 {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	return ms, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	stmt := `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`
+	if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("error creating the schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// version returns the version in the schema_migrations table. An empty table is version 0.
+func (m *Migrator) version(ctx context.Context) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := m.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	switch err {
+	case sql.ErrNoRows:
+		return 0, false, nil
+	case nil:
+		return version, dirty, nil
+	default:
+		return 0, false, fmt.Errorf("error reading the schema version: %w", err)
+	}
+}
+
+// Status returns the version of the schema and the migrations that have not been applied.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	version, dirty, err := m.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := Status{Version: version, Dirty: dirty}
+	for _, mig := range m.migrations {
+		s.Latest = mig.Version
+		if mig.Version > version {
+			s.Pending = append(s.Pending, mig)
+		}
+	}
+	return &s, nil
+}
+
+// Up applies every pending migration, in order, and returns the migrations it applied.
+// Each migration runs in its own transaction together with the update of the version, so
+// a failed migration leaves the schema at the previous version.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(ctx context.Context, s *Status) error {
+		for _, mig := range s.Pending {
+			if err := m.apply(ctx, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("error applying migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps migrations and returns the migrations it rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(ctx context.Context, s *Status) error {
+		var applied []Migration
+		for _, mig := range m.migrations {
+			if mig.Version <= s.Version {
+				applied = append(applied, mig)
+			}
+		}
+		for i := len(applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := applied[i]
+			var previous int64
+			if i > 0 {
+				previous = applied[i-1].Version
+			}
+			if err := m.apply(ctx, mig.Down, previous); err != nil {
+				return fmt.Errorf("error rolling back migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// locked runs fn while holding the migration lock, with the status of a clean schema.
+func (m *Migrator) locked(ctx context.Context, fn func(ctx context.Context, s *Status) error) error {
+	acquired, err := m.db.WithAdvisoryLock(ctx, lockKey, func(ctx context.Context) error {
+		s, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if s.Dirty {
+			return ErrDirty
+		}
+		return fn(ctx, s)
+	})
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrLocked
+	}
+	return nil
+}
+
+// apply runs the sql of a migration and sets the schema version in one transaction.
+// Version 0 empties the schema_migrations table, like the migrate tool does. An empty
+// migration only changes the version.
+func (m *Migrator) apply(ctx context.Context, stmt string, version int64) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if len(strings.TrimSpace(stmt)) > 0 {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
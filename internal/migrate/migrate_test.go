@@ -0,0 +1,52 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"moh.gov.bz/mch/emtct/db/migrations"
+)
+
+func TestRead(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000010_add_infants.up.sql":    {Data: []byte("CREATE TABLE infant ()")},
+		"000010_add_infants.down.sql":  {Data: []byte("DROP TABLE infant")},
+		"000002_add_patients.up.sql":   {Data: []byte("CREATE TABLE patient ()")},
+		"000002_add_patients.down.sql": {Data: []byte("DROP TABLE patient")},
+		"migrations.go":                {Data: []byte("package migrations")},
+	}
+	ms, err := read(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 {
+		t.Fatalf("want: 2 migrations got: %+v", ms)
+	}
+	if ms[0].Version != 2 || ms[0].Name != "add_patients" || ms[1].Version != 10 {
+		t.Errorf("want: migrations sorted by version got: %+v", ms)
+	}
+	if ms[1].Up != "CREATE TABLE infant ()" || ms[1].Down != "DROP TABLE infant" {
+		t.Errorf("want: the sql of both files got: %+v", ms[1])
+	}
+
+	delete(fsys, "000010_add_infants.up.sql")
+	if _, err := read(fsys); err == nil {
+		t.Error("want: an error for a migration without an up file")
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	ms, err := read(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) == 0 {
+		t.Fatal("want: the embedded migrations got: none")
+	}
+	for i, m := range ms {
+		if m.Version != int64(i+1) {
+			t.Errorf("want: migration %d got: %d_%s", i+1, m.Version, m.Name)
+			break
+		}
+	}
+}
@@ -11,28 +11,50 @@ import (
 	_ "github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 
+	"moh.gov.bz/mch/emtct/db/migrations"
 	"moh.gov.bz/mch/emtct/internal/app"
 	"moh.gov.bz/mch/emtct/internal/app/api"
+	"moh.gov.bz/mch/emtct/internal/business/data/etlRuns"
+	"moh.gov.bz/mch/emtct/internal/business/data/infant"
+	"moh.gov.bz/mch/emtct/internal/business/data/labs"
+	"moh.gov.bz/mch/emtct/internal/business/data/pregnancy"
+	"moh.gov.bz/mch/emtct/internal/business/etl"
 	"moh.gov.bz/mch/emtct/internal/config"
 	"moh.gov.bz/mch/emtct/internal/db"
+	"moh.gov.bz/mch/emtct/internal/migrate"
 )
 
-func RegisterHandlers(cnf config.AppConf) *mux.Router {
+// NewApp opens the database connections and returns the App that is shared by the
+// http handlers and the background jobs.
+func NewApp(cnf config.AppConf) app.App {
 	acsisStore, err := db.NewAcsisConnection(&cnf.AcsisDb)
 	if err != nil {
-		log.Errorf("could not establish connection to the database: %+v", err)
+		log.WithError(err).Error("could not establish connection to the acsis database")
 		os.Exit(1)
 	}
 	emtctStore, err := db.NewConnection(&cnf.EmtctDb)
+	if err != nil {
+		log.WithError(err).Error("could not establish connection to the emtct database")
+		os.Exit(1)
+	}
 
-	app := app.App{
+	return app.App{
 		AcsisDb: acsisStore,
 		EmtctDb: emtctStore,
 		Auth: app.Auth{
 			JwkUrl: cnf.Auth.JwkUrl,
 			Iss:    cnf.Auth.Issuer,
 			Aud:    cnf.Auth.Audience,
-		}}
+			Admins: cnf.Auth.Admins,
+		},
+		Cache: app.CacheTtl{
+			Patients: cnf.Cache.Patients,
+			Infants:  cnf.Cache.Infants,
+		},
+	}
+}
+
+func RegisterHandlers(app app.App) *mux.Router {
 	router := api.API(app)
 	log.Infof("Initiated App: %+v", app)
 	//apiRouter := r.PathPrefix("/api").Subrouter()
@@ -44,8 +66,56 @@ func RegisterHandlers(cnf config.AppConf) *mux.Router {
 	return router
 }
 
-func NewServer(cnf config.AppConf) {
-	r := RegisterHandlers(cnf)
+// newScheduler returns the background pregnancy sync, or nil when it is disabled.
+func newScheduler(cnf config.EtlConf, app app.App) *etl.Scheduler {
+	if !cnf.Enabled {
+		log.Info("scheduled pregnancy etl is disabled")
+		return nil
+	}
+	pregnancies := pregnancy.New(app.EmtctDb, app.AcsisDb)
+	runs := etlRuns.New(app.EmtctDb)
+	sync := etl.NewPregnancySync(pregnancies, runs)
+	births := etl.NewBirthSync(pregnancies, infant.New(app.AcsisDb, app.EmtctDb), runs)
+	labResults := etl.NewLabResultSync(app.EmtctDb, labs.New(app.AcsisDb, app.EmtctDb), runs)
+	scheduler, err := etl.NewScheduler(cnf, sync, births, labResults)
+	if err != nil {
+		log.WithError(err).Error("could not create the etl scheduler")
+		os.Exit(1)
+	}
+	return scheduler
+}
+
+// checkSchema stops the server when the emtct schema is dirty or behind the migrations
+// that are embedded in the binary.
+func checkSchema(emtctDb *db.EmtctDb) {
+	m, err := migrate.New(emtctDb, migrations.FS)
+	if err != nil {
+		log.WithError(err).Error("could not read the migrations")
+		os.Exit(1)
+	}
+	s, err := m.Status(context.Background())
+	if err != nil {
+		log.WithError(err).Error("could not read the version of the emtct schema")
+		os.Exit(1)
+	}
+	if s.Dirty || s.Behind() {
+		log.WithFields(log.Fields{
+			"version": s.Version,
+			"latest":  s.Latest,
+			"dirty":   s.Dirty,
+		}).Error("the emtct schema is not up to date, run emtct migrate up")
+		os.Exit(1)
+	}
+}
+
+// NewServer starts the api and the background jobs. When requireSchema is set the server
+// refuses to start until every migration has been applied.
+func NewServer(cnf config.AppConf, requireSchema bool) {
+	a := NewApp(cnf)
+	if requireSchema {
+		checkSchema(a.EmtctDb)
+	}
+	r := RegisterHandlers(a)
 	srv := &http.Server{
 		Addr: "0.0.0.0:8080",
 		// Good practice to set timeouts to avoid Slowloris attacks.
@@ -55,6 +125,12 @@ func NewServer(cnf config.AppConf) {
 		Handler:      r, // Pass our instance of gorilla/mux in.
 	}
 
+	scheduler := newScheduler(cnf.Etl, a)
+	if scheduler != nil {
+		log.WithFields(log.Fields{"schedule": cnf.Etl.Schedule}).Info("starting scheduled etl")
+		scheduler.Start()
+	}
+
 	// Run our server in a goroutine so that it doesn't block.
 	go func() {
 		log.Println("Starting server on port 8080")
@@ -71,16 +147,17 @@ func NewServer(cnf config.AppConf) {
 	// Block until we receive our signal.
 	<-c
 
-	wait := time.Duration(30)
+	wait := time.Second * 30
 	// Create a deadline to wait for.
 	ctx, cancel := context.WithTimeout(context.Background(), wait)
 	defer cancel()
 	// Doesn't block if no connections, but will otherwise wait
 	// until the timeout deadline.
 	srv.Shutdown(ctx)
-	// Optionally, you could run srv.Shutdown in a goroutine and block on
-	// <-ctx.Done() if your application should wait for other services
-	// to finalize based on context cancellation.
+	// Let a running sync finish within the same deadline, otherwise it is cancelled.
+	if scheduler != nil {
+		scheduler.Stop(ctx)
+	}
 	log.Println("shutting down")
 	os.Exit(0)
 }